@@ -0,0 +1,9 @@
+// Package assets contiene los archivos que se incluyen en el binario
+package assets
+
+import _ "embed"
+
+// Logo - Logo de la marca (JPEG) para los documentos imprimibles
+//
+//go:embed logo.jpg
+var Logo []byte
@@ -0,0 +1,309 @@
+package controllers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+	"vartan-backend/config"
+	"vartan-backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Unidades e ingresos de un renglón de venta sin lo devuelto. El ingreso descuenta la promoción
+// del renglón y se prorratea entre las unidades que no se devolvieron, como en el estado de resultados.
+const (
+	unidadesNetasSQL = "(vd.cantidad - vd.cantidad_devuelta)"
+	ingresosNetosSQL = "(vd.subtotal - vd.descuento) * (vd.cantidad - vd.cantidad_devuelta) / NULLIF(vd.cantidad, 0)"
+)
+
+// GetProductosMasVendidos godoc
+// @Summary Productos más vendidos
+// @Description Ranking de productos, equipos o tipos de producto por unidades o ingresos en el período, sin las unidades devueltas y con los ingresos netos del descuento de promociones (solo dueño)
+// @Tags Reportes
+// @Produce json
+// @Security BearerAuth
+// @Param fecha_desde query string false "Desde (YYYY-MM-DD), por defecto el primer día del mes"
+// @Param fecha_hasta query string false "Hasta inclusive (YYYY-MM-DD), por defecto hoy"
+// @Param agrupar query string false "producto (por defecto), equipo o tipo"
+// @Param ordenar_por query string false "unidades (por defecto) o ingresos"
+// @Param limit query int false "Cantidad de resultados (por defecto 20)"
+// @Success 200 {array} models.RankingProducto
+// @Failure 400 {object} map[string]string "Parámetro inválido"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/reportes/productos/mas-vendidos [get]
+func GetProductosMasVendidos(c *gin.Context) {
+	desde, hasta, err := rangoFechas(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	agrupamientos := map[string]struct{ join, columnas string }{
+		"producto": {"", "p.id, p.nombre"},
+		"equipo":   {"LEFT JOIN equipos e ON e.id = p.equipo_id", "COALESCE(e.id, 0) AS id, COALESCE(e.nombre, 'Sin equipo') AS nombre"},
+		"tipo":     {"LEFT JOIN tipo_productos tp ON tp.id = p.tipo_producto_id", "COALESCE(tp.id, 0) AS id, COALESCE(tp.nombre, 'Sin tipo') AS nombre"},
+	}
+	agrupamiento, ok := agrupamientos[c.DefaultQuery("agrupar", "producto")]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parámetro inválido: agrupar"})
+		return
+	}
+
+	orden := "unidades DESC, ingresos DESC"
+	switch c.DefaultQuery("ordenar_por", "unidades") {
+	case "unidades":
+	case "ingresos":
+		orden = "ingresos DESC, unidades DESC"
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parámetro inválido: ordenar_por"})
+		return
+	}
+
+	query := config.DB.Table("venta_detalles vd").
+		Select(agrupamiento.columnas + ", SUM(" + unidadesNetasSQL + ") AS unidades, ROUND(COALESCE(SUM(" + ingresosNetosSQL + "), 0), 2) AS ingresos").
+		Joins("JOIN venta v ON v.id = vd.venta_id").
+		Joins("JOIN productos p ON p.id = vd.producto_id")
+	if agrupamiento.join != "" {
+		query = query.Joins(agrupamiento.join)
+	}
+
+	ranking := []models.RankingProducto{}
+	if err := query.
+		Where("v.fecha_venta >= ? AND v.fecha_venta < ?", desde, hasta.AddDate(0, 0, 1)).
+		Group("1, 2").
+		Order(orden).
+		Limit(parametroEntero(c, "limit", 20)).
+		Scan(&ranking).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al calcular ranking"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ranking)
+}
+
+// GetSellThrough godoc
+// @Summary Sell-through por producto
+// @Description Porcentaje de la mercadería disponible que se vendió en el período: vendidas / (vendidas + stock actual), sin contar las unidades devueltas (solo dueño)
+// @Tags Reportes
+// @Produce json
+// @Security BearerAuth
+// @Param fecha_desde query string false "Desde (YYYY-MM-DD), por defecto el primer día del mes"
+// @Param fecha_hasta query string false "Hasta inclusive (YYYY-MM-DD), por defecto hoy"
+// @Success 200 {array} models.SellThroughProducto
+// @Failure 400 {object} map[string]string "Fechas inválidas"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/reportes/productos/sell-through [get]
+func GetSellThrough(c *gin.Context) {
+	desde, hasta, err := rangoFechas(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	productos := []models.SellThroughProducto{}
+	if err := config.DB.Table("productos p").
+		Select(`p.id AS producto_id, p.nombre,
+			COALESCE(ven.vendidas, 0) AS vendidas, COALESCE(st.stock, 0) AS stock_actual,
+			CASE WHEN COALESCE(ven.vendidas, 0) + COALESCE(st.stock, 0) > 0
+				THEN ROUND(COALESCE(ven.vendidas, 0) * 100.0 / (COALESCE(ven.vendidas, 0) + COALESCE(st.stock, 0)), 2)
+				ELSE 0 END AS sell_through`).
+		Joins(`LEFT JOIN (
+			SELECT vd.producto_id, SUM(`+unidadesNetasSQL+`) AS vendidas FROM venta_detalles vd
+			JOIN venta v ON v.id = vd.venta_id
+			WHERE v.fecha_venta >= ? AND v.fecha_venta < ? GROUP BY vd.producto_id
+		) ven ON ven.producto_id = p.id`, desde, hasta.AddDate(0, 0, 1)).
+		Joins("LEFT JOIN (SELECT producto_id, SUM(cantidad) AS stock FROM producto_stocks GROUP BY producto_id) st ON st.producto_id = p.id").
+		Where("p.activo = ?", true).
+		Where("COALESCE(ven.vendidas, 0) + COALESCE(st.stock, 0) > 0").
+		Order("sell_through DESC, vendidas DESC").
+		Scan(&productos).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al calcular sell-through"})
+		return
+	}
+
+	c.JSON(http.StatusOK, productos)
+}
+
+// GetStockInmovilizado godoc
+// @Summary Stock inmovilizado
+// @Description Productos con stock que no registran ventas en los últimos N días, con el capital inmovilizado al costo (solo dueño)
+// @Tags Reportes
+// @Produce json
+// @Security BearerAuth
+// @Param dias query int false "Días sin ventas (por defecto 90)"
+// @Success 200 {array} models.StockInmovilizado
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/reportes/productos/stock-inmovilizado [get]
+func GetStockInmovilizado(c *gin.Context) {
+	dias := parametroEntero(c, "dias", 90)
+	limite := time.Now().AddDate(0, 0, -dias)
+
+	productos := []models.StockInmovilizado{}
+	if err := config.DB.Table("productos p").
+		Select(`p.id AS producto_id, p.nombre, p.fecha_creacion, st.stock,
+			st.stock * p.costo_unitario AS valor_al_costo, ult.ultima_venta,
+			(CURRENT_DATE - ult.ultima_venta::date) AS dias_sin_ventas`).
+		Joins("JOIN (SELECT producto_id, SUM(cantidad) AS stock FROM producto_stocks GROUP BY producto_id) st ON st.producto_id = p.id").
+		Joins(`LEFT JOIN (
+			SELECT vd.producto_id, MAX(v.fecha_venta) AS ultima_venta FROM venta_detalles vd
+			JOIN venta v ON v.id = vd.venta_id GROUP BY vd.producto_id
+		) ult ON ult.producto_id = p.id`).
+		Where("p.activo = ? AND st.stock > 0", true).
+		// Los productos nuevos que todavía no tuvieron tiempo de venderse no cuentan
+		Where("COALESCE(ult.ultima_venta, p.fecha_creacion) < ?", limite).
+		Order("valor_al_costo DESC").
+		Scan(&productos).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al calcular stock inmovilizado"})
+		return
+	}
+
+	c.JSON(http.StatusOK, productos)
+}
+
+// GetCurvaTalles godoc
+// @Summary Curva de talles
+// @Description Unidades vendidas por talle para cada tipo de producto en el período (solo dueño)
+// @Tags Reportes
+// @Produce json
+// @Security BearerAuth
+// @Param fecha_desde query string false "Desde (YYYY-MM-DD), por defecto el primer día del mes"
+// @Param fecha_hasta query string false "Hasta inclusive (YYYY-MM-DD), por defecto hoy"
+// @Param tipo_producto_id query int false "Solo un tipo de producto"
+// @Success 200 {array} models.CurvaTallesTipo
+// @Failure 400 {object} map[string]string "Fechas inválidas"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/reportes/productos/curva-talles [get]
+func GetCurvaTalles(c *gin.Context) {
+	desde, hasta, err := rangoFechas(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := config.DB.Table("venta_detalles vd").
+		Select("COALESCE(tp.id, 0) AS tipo_producto_id, COALESCE(tp.nombre, 'Sin tipo') AS nombre, vd.talle, SUM("+unidadesNetasSQL+") AS unidades").
+		Joins("JOIN venta v ON v.id = vd.venta_id").
+		Joins("JOIN productos p ON p.id = vd.producto_id").
+		Joins("LEFT JOIN tipo_productos tp ON tp.id = p.tipo_producto_id").
+		Where("v.fecha_venta >= ? AND v.fecha_venta < ?", desde, hasta.AddDate(0, 0, 1))
+	if tipoID := c.Query("tipo_producto_id"); tipoID != "" {
+		query = query.Where("p.tipo_producto_id = ?", tipoID)
+	}
+
+	var filas []struct {
+		TipoProductoID int
+		Nombre         string
+		Talle          string
+		Unidades       int64
+	}
+	if err := query.Group("1, 2, vd.talle").Order("2").Scan(&filas).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al calcular curva de talles"})
+		return
+	}
+
+	// Agrupar por tipo respetando el orden de los talles
+	curvas := []models.CurvaTallesTipo{}
+	porTipo := map[int]int{}
+	unidades := map[int]map[string]int64{}
+	for _, f := range filas {
+		if _, ok := porTipo[f.TipoProductoID]; !ok {
+			porTipo[f.TipoProductoID] = len(curvas)
+			curvas = append(curvas, models.CurvaTallesTipo{TipoProductoID: f.TipoProductoID, Nombre: f.Nombre})
+			unidades[f.TipoProductoID] = map[string]int64{}
+		}
+		unidades[f.TipoProductoID][f.Talle] += f.Unidades
+		curvas[porTipo[f.TipoProductoID]].Unidades += f.Unidades
+	}
+
+	// Los talles se muestran en el orden del catálogo
+	var ordenTalles []string
+	if err := config.DB.Model(&models.Talle{}).Where("activo = ?", true).Order("orden, nombre").Pluck("nombre", &ordenTalles).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener talles"})
+		return
+	}
+	enCatalogo := make(map[string]bool, len(ordenTalles))
+	for _, t := range ordenTalles {
+		enCatalogo[t] = true
+	}
+
+	for i := range curvas {
+		curva := &curvas[i]
+		porTalle := unidades[curva.TipoProductoID]
+		talles := append([]string{}, ordenTalles...)
+		// Talles dados de baja o fuera del catálogo al final
+		var otros []string
+		for t := range porTalle {
+			if !enCatalogo[t] {
+				otros = append(otros, t)
+			}
+		}
+		sort.Strings(otros)
+		talles = append(talles, otros...)
+		for _, t := range talles {
+			curva.Talles = append(curva.Talles, models.CurvaTalle{
+				Talle:      t,
+				Unidades:   porTalle[t],
+				Porcentaje: redondear(float64(porTalle[t]) / float64(curva.Unidades) * 100),
+			})
+		}
+	}
+
+	c.JSON(http.StatusOK, curvas)
+}
+
+// GetCoberturaStock godoc
+// @Summary Días de cobertura por variante
+// @Description Para cada producto, talle y color con stock o ventas, cuántos días alcanza el stock al ritmo de venta de los últimos N días (solo dueño)
+// @Tags Reportes
+// @Produce json
+// @Security BearerAuth
+// @Param dias query int false "Días de historia para el ritmo de venta (por defecto 30)"
+// @Success 200 {array} models.CoberturaVariante
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/reportes/productos/cobertura [get]
+func GetCoberturaStock(c *gin.Context) {
+	dias := parametroEntero(c, "dias", 30)
+	desde := time.Now().AddDate(0, 0, -dias)
+
+	variantes := []models.CoberturaVariante{}
+	// Stock y ventas de cada producto, talle y color
+	if err := config.DB.Table(`(
+			SELECT producto_id, talle, color, SUM(cantidad) AS stock, 0 AS vendidas FROM producto_stocks GROUP BY producto_id, talle, color
+			UNION ALL
+			SELECT vd.producto_id, vd.talle, COALESCE(vd.color, ''), 0, SUM(`+unidadesNetasSQL+`) FROM venta_detalles vd
+			JOIN venta v ON v.id = vd.venta_id
+			WHERE v.fecha_venta >= ? GROUP BY vd.producto_id, vd.talle, vd.color
+		) x`, desde).
+		Select("p.id AS producto_id, p.nombre, x.talle, x.color, SUM(x.stock) AS stock, SUM(x.vendidas) AS vendidas").
+		Joins("JOIN productos p ON p.id = x.producto_id").
+		Where("p.activo = ?", true).
+		Group("p.id, p.nombre, x.talle, x.color").
+		Having("SUM(x.stock) > 0 OR SUM(x.vendidas) > 0").
+		Order("p.nombre, x.talle, x.color").
+		Scan(&variantes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al calcular cobertura"})
+		return
+	}
+
+	for i := range variantes {
+		v := &variantes[i]
+		v.VentaDiaria = redondear(float64(v.Vendidas) / float64(dias))
+		if v.Vendidas > 0 {
+			cobertura := redondear(float64(v.Stock) / (float64(v.Vendidas) / float64(dias)))
+			v.DiasCobertura = &cobertura
+		}
+	}
+
+	c.JSON(http.StatusOK, variantes)
+}
+
+// parametroEntero lee un parámetro entero positivo de la query, con un valor por defecto
+func parametroEntero(c *gin.Context, nombre string, defecto int) int {
+	valor, err := strconv.Atoi(c.Query(nombre))
+	if err != nil || valor < 1 {
+		return defecto
+	}
+	return valor
+}
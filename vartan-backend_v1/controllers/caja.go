@@ -0,0 +1,491 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"vartan-backend/config"
+	"vartan-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetCajaActual godoc
+// @Summary Caja abierta
+// @Description Devuelve la sesión de caja abierta con sus movimientos y el efectivo que debería haber
+// @Tags Caja
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.CajaResumen
+// @Failure 404 {object} map[string]string "No hay caja abierta"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/caja [get]
+func GetCajaActual(c *gin.Context) {
+	caja, err := cajaAbierta(config.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener caja"})
+		return
+	}
+	if caja == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No hay caja abierta"})
+		return
+	}
+
+	responderCaja(c, http.StatusOK, caja.ID)
+}
+
+// AbrirCaja godoc
+// @Summary Abrir caja
+// @Description Abre una sesión de caja con el fondo inicial. Solo puede haber una caja abierta.
+// @Tags Caja
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.CajaAbrirRequest true "Fondo inicial"
+// @Success 201 {object} models.CajaResumen
+// @Failure 400 {object} map[string]string "Datos inválidos o ya hay una caja abierta"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/caja/abrir [post]
+func AbrirCaja(c *gin.Context) {
+	var req models.CajaAbrirRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+
+	abierta, err := cajaAbierta(config.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener caja"})
+		return
+	}
+	if abierta != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ya hay una caja abierta"})
+		return
+	}
+
+	caja := models.Caja{
+		Estado:            models.CajaAbierta,
+		MontoInicial:      req.MontoInicial,
+		UsuarioAperturaID: c.GetInt("user_id"),
+	}
+	if req.Observaciones != "" {
+		caja.Observaciones = &req.Observaciones
+	}
+
+	// El índice único parcial impide dos cajas abiertas aunque se abran a la vez
+	if err := config.DB.Create(&caja).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ya hay una caja abierta"})
+		return
+	}
+
+	responderCaja(c, http.StatusCreated, caja.ID)
+}
+
+// RegistrarMovimientoCaja godoc
+// @Summary Retiro o ingreso manual de efectivo
+// @Description Registra un movimiento de efectivo que no proviene de ventas, pagos ni gastos (por ejemplo un retiro del dueño)
+// @Tags Caja
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.MovimientoCajaRequest true "Movimiento"
+// @Success 201 {object} models.MovimientoCaja
+// @Failure 400 {object} map[string]string "Datos inválidos o no hay caja abierta"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/caja/movimientos [post]
+func RegistrarMovimientoCaja(c *gin.Context) {
+	var req models.MovimientoCajaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+
+	movimiento := models.MovimientoCaja{
+		Tipo:        req.Tipo,
+		Concepto:    models.ConceptoCajaManual,
+		Monto:       req.Monto,
+		Descripcion: &req.Descripcion,
+		UsuarioID:   c.GetInt("user_id"),
+	}
+
+	var registrado bool
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		registrado, err = registrarMovimientoCaja(tx, &movimiento)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al registrar movimiento"})
+		return
+	}
+	if !registrado {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No hay caja abierta"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, movimiento)
+}
+
+// CerrarCaja godoc
+// @Summary Cerrar caja
+// @Description Cierra la caja abierta con el efectivo contado y registra la diferencia contra lo esperado
+// @Tags Caja
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.CajaCerrarRequest true "Efectivo contado"
+// @Success 200 {object} models.CajaResumen
+// @Failure 400 {object} map[string]string "Datos inválidos o no hay caja abierta"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/caja/cerrar [post]
+func CerrarCaja(c *gin.Context) {
+	var req models.CajaCerrarRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+
+	var cajaID int
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// Bloquear la caja para que no entren movimientos mientras se calcula lo esperado
+		var caja models.Caja
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("estado = ?", models.CajaAbierta).
+			First(&caja).Error; err != nil {
+			return err
+		}
+		cajaID = caja.ID
+
+		resumen, err := resumirCaja(tx, caja)
+		if err != nil {
+			return err
+		}
+
+		usuarioID := c.GetInt("user_id")
+		ahora := time.Now()
+		esperado := redondear(resumen.SaldoActual)
+		diferencia := redondear(*req.MontoContado - esperado)
+
+		caja.Estado = models.CajaCerrada
+		caja.UsuarioCierreID = &usuarioID
+		caja.FechaCierre = &ahora
+		caja.MontoEsperado = &esperado
+		caja.MontoContado = req.MontoContado
+		caja.Diferencia = &diferencia
+		if req.Observaciones != "" {
+			caja.Observaciones = &req.Observaciones
+		}
+
+		return tx.Save(&caja).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No hay caja abierta"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al cerrar caja"})
+		return
+	}
+
+	responderCaja(c, http.StatusOK, cajaID)
+}
+
+// GetCajas godoc
+// @Summary Historial de cajas
+// @Description Lista las sesiones de caja abiertas en el período, con sus totales y diferencias (solo dueño)
+// @Tags Caja
+// @Produce json
+// @Security BearerAuth
+// @Param fecha_desde query string false "Desde (YYYY-MM-DD), por defecto el primer día del mes"
+// @Param fecha_hasta query string false "Hasta inclusive (YYYY-MM-DD), por defecto hoy"
+// @Param page query int false "Página (por defecto 1)"
+// @Param limit query int false "Resultados por página (por defecto 50)"
+// @Success 200 {object} map[string]interface{} "cajas, total, page, limit"
+// @Failure 400 {object} map[string]string "Fechas inválidas"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/cajas [get]
+func GetCajas(c *gin.Context) {
+	desde, hasta, err := rangoFechas(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit < 1 || limit > 500 {
+		limit = 50
+	}
+
+	query := config.DB.Model(&models.Caja{}).
+		Where("fecha_apertura >= ? AND fecha_apertura < ?", desde, hasta.AddDate(0, 0, 1))
+
+	var total int64
+	query.Count(&total)
+
+	var cajas []models.Caja
+	if err := query.
+		Preload("UsuarioApertura").
+		Preload("UsuarioCierre").
+		Order("fecha_apertura DESC").
+		Limit(limit).
+		Offset((page - 1) * limit).
+		Find(&cajas).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener cajas"})
+		return
+	}
+
+	resumenes := make([]models.CajaResumen, 0, len(cajas))
+	for _, caja := range cajas {
+		resumen, err := resumirCaja(config.DB, caja)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al calcular totales de caja"})
+			return
+		}
+		resumenes = append(resumenes, resumen)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"cajas": resumenes,
+		"total": total,
+		"page":  page,
+		"limit": limit,
+	})
+}
+
+// GetCaja godoc
+// @Summary Detalle de caja
+// @Description Devuelve una sesión de caja con todos sus movimientos (solo dueño)
+// @Tags Caja
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID de la caja"
+// @Success 200 {object} models.CajaResumen
+// @Failure 404 {object} map[string]string "Caja no encontrada"
+// @Router /api/owner/cajas/{id} [get]
+func GetCaja(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Caja no encontrada"})
+		return
+	}
+
+	responderCaja(c, http.StatusOK, id)
+}
+
+// GetFlujoCaja godoc
+// @Summary Flujo de fondos
+// @Description Compara, por forma de pago, lo facturado en ventas contra el dinero efectivamente cobrado (señas y pagos de cuenta corriente) en el período (solo dueño)
+// @Tags Reportes
+// @Produce json
+// @Security BearerAuth
+// @Param fecha_desde query string false "Desde (YYYY-MM-DD), por defecto el primer día del mes"
+// @Param fecha_hasta query string false "Hasta inclusive (YYYY-MM-DD), por defecto hoy"
+// @Success 200 {object} models.FlujoCajaResponse
+// @Failure 400 {object} map[string]string "Fechas inválidas"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/reportes/flujo-caja [get]
+func GetFlujoCaja(c *gin.Context) {
+	desde, hasta, err := rangoFechas(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	finHasta := hasta.AddDate(0, 0, 1)
+
+	response := models.FlujoCajaResponse{
+		FechaDesde:   desde.Format("2006-01-02"),
+		FechaHasta:   hasta.Format("2006-01-02"),
+		PorFormaPago: []models.FlujoFormaPago{},
+	}
+
+	// Lo facturado sale de las ventas; lo cobrado, de los créditos de cuenta corriente con forma de pago
+	if err := config.DB.Table("forma_pagos fp").
+		Select(`fp.id AS forma_pago_id, fp.nombre,
+			COALESCE(f.facturado, 0) AS facturado, COALESCE(m.senas, 0) AS senas, COALESCE(m.pagos, 0) AS pagos,
+			COALESCE(m.senas, 0) + COALESCE(m.pagos, 0) AS cobrado`).
+		Joins(`LEFT JOIN (
+			SELECT forma_pago_id, SUM(total_final) AS facturado FROM venta
+			WHERE fecha_venta >= ? AND fecha_venta < ? GROUP BY forma_pago_id
+		) f ON f.forma_pago_id = fp.id`, desde, finHasta).
+		Joins(`LEFT JOIN (
+			SELECT forma_pago_id,
+				SUM(CASE WHEN concepto = ? THEN monto ELSE 0 END) AS senas,
+				SUM(CASE WHEN concepto = ? THEN monto ELSE 0 END) AS pagos
+			FROM cuenta_corriente_movimientos
+			WHERE tipo = ? AND fecha >= ? AND fecha < ? GROUP BY forma_pago_id
+		) m ON m.forma_pago_id = fp.id`, models.ConceptoSena, models.ConceptoPago, models.MovimientoCredito, desde, finHasta).
+		Order("fp.id").
+		Scan(&response.PorFormaPago).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al calcular flujo de fondos"})
+		return
+	}
+
+	for _, f := range response.PorFormaPago {
+		response.TotalFacturado += f.Facturado
+		response.TotalCobrado += f.Cobrado
+	}
+
+	if err := config.DB.Model(&models.Gasto{}).
+		Select("COALESCE(SUM(monto), 0)").
+		Where("metodo_pago = ? AND fecha >= ? AND fecha < ?", models.FormaPagoEfectivo, desde, finHasta).
+		Scan(&response.GastosEfectivo).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al calcular gastos en efectivo"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// responderCaja carga la caja con sus movimientos y responde con sus totales
+func responderCaja(c *gin.Context, status int, cajaID int) {
+	var caja models.Caja
+	if err := config.DB.
+		Preload("UsuarioApertura").
+		Preload("UsuarioCierre").
+		Preload("Movimientos", func(db *gorm.DB) *gorm.DB { return db.Order("fecha ASC, id ASC") }).
+		First(&caja, cajaID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Caja no encontrada"})
+		return
+	}
+
+	resumen, err := resumirCaja(config.DB, caja)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al calcular totales de caja"})
+		return
+	}
+
+	c.JSON(status, resumen)
+}
+
+// resumirCaja calcula los ingresos, egresos y el efectivo que debería haber en la caja
+func resumirCaja(db *gorm.DB, caja models.Caja) (models.CajaResumen, error) {
+	resumen := models.CajaResumen{Caja: caja}
+
+	err := db.Model(&models.MovimientoCaja{}).
+		Select(`COALESCE(SUM(CASE WHEN tipo = ? THEN monto ELSE 0 END), 0) AS total_ingresos,
+			COALESCE(SUM(CASE WHEN tipo = ? THEN monto ELSE 0 END), 0) AS total_egresos`,
+			models.MovimientoCajaIngreso, models.MovimientoCajaEgreso).
+		Where("caja_id = ?", caja.ID).
+		Scan(&resumen).Error
+	if err != nil {
+		return resumen, err
+	}
+
+	resumen.SaldoActual = redondear(caja.MontoInicial + resumen.TotalIngresos - resumen.TotalEgresos)
+	return resumen, nil
+}
+
+// cajaAbierta devuelve la sesión de caja abierta, o nil si no hay ninguna
+func cajaAbierta(db *gorm.DB) (*models.Caja, error) {
+	var caja models.Caja
+	err := db.Where("estado = ?", models.CajaAbierta).First(&caja).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &caja, nil
+}
+
+// registrarMovimientoCaja asienta el movimiento en la caja abierta. Si no hay caja abierta
+// no registra nada y devuelve false: las ventas y pagos no se bloquean por eso.
+func registrarMovimientoCaja(tx *gorm.DB, movimiento *models.MovimientoCaja) (bool, error) {
+	// Bloqueo compartido: el cierre espera a que terminen los movimientos en curso
+	var caja models.Caja
+	err := tx.Clauses(clause.Locking{Strength: "SHARE"}).
+		Where("estado = ?", models.CajaAbierta).
+		First(&caja).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	movimiento.CajaID = caja.ID
+	if err := tx.Create(movimiento).Error; err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// esEfectivo indica si la forma de pago mueve la caja
+func esEfectivo(tx *gorm.DB, formaPagoID int) (bool, error) {
+	var formaPago models.FormaPago
+	if err := tx.First(&formaPago, formaPagoID).Error; err != nil {
+		return false, err
+	}
+	return strings.EqualFold(formaPago.Nombre, models.FormaPagoEfectivo), nil
+}
+
+// sincronizarCajaVenta deja en la caja abierta la seña en efectivo de la venta. Los movimientos
+// de cajas ya cerradas no se tocan porque ese efectivo ya fue contado.
+func sincronizarCajaVenta(tx *gorm.DB, venta models.Venta, usuarioID int) error {
+	if err := anularCajaVenta(tx, venta.ID); err != nil {
+		return err
+	}
+
+	efectivo, err := esEfectivo(tx, venta.FormaPagoID)
+	if err != nil || !efectivo || venta.Sena <= 0 {
+		return err
+	}
+
+	_, err = registrarMovimientoCaja(tx, &models.MovimientoCaja{
+		Tipo:      models.MovimientoCajaIngreso,
+		Concepto:  models.ConceptoCajaVenta,
+		Monto:     venta.Sena,
+		VentaID:   &venta.ID,
+		ClienteID: &venta.ClienteID,
+		UsuarioID: usuarioID,
+	})
+	return err
+}
+
+// anularCajaVenta quita de la caja abierta la seña de una venta
+func anularCajaVenta(tx *gorm.DB, ventaID int) error {
+	return tx.
+		Where("venta_id = ? AND concepto = ?", ventaID, models.ConceptoCajaVenta).
+		Where("caja_id IN (?)", tx.Model(&models.Caja{}).Select("id").Where("estado = ?", models.CajaAbierta)).
+		Delete(&models.MovimientoCaja{}).Error
+}
+
+// sincronizarCajaGasto deja en la caja abierta la salida de efectivo de un gasto del día.
+// Los gastos con otra fecha no pasaron por la caja de hoy y no se registran.
+func sincronizarCajaGasto(tx *gorm.DB, gasto models.Gasto, usuarioID int) error {
+	if err := anularCajaGasto(tx, gasto.ID); err != nil {
+		return err
+	}
+
+	hoy := time.Now()
+	delDia := gasto.Fecha.Year() == hoy.Year() && gasto.Fecha.YearDay() == hoy.YearDay()
+	if !strings.EqualFold(gasto.MetodoPago, models.FormaPagoEfectivo) || !delDia {
+		return nil
+	}
+
+	_, err := registrarMovimientoCaja(tx, &models.MovimientoCaja{
+		Tipo:        models.MovimientoCajaEgreso,
+		Concepto:    models.ConceptoCajaGasto,
+		Monto:       gasto.Monto,
+		GastoID:     &gasto.ID,
+		Descripcion: &gasto.Descripcion,
+		UsuarioID:   usuarioID,
+	})
+	return err
+}
+
+// anularCajaGasto quita de la caja abierta la salida de efectivo de un gasto
+func anularCajaGasto(tx *gorm.DB, gastoID int) error {
+	return tx.
+		Where("gasto_id = ? AND concepto = ?", gastoID, models.ConceptoCajaGasto).
+		Where("caja_id IN (?)", tx.Model(&models.Caja{}).Select("id").Where("estado = ?", models.CajaAbierta)).
+		Delete(&models.MovimientoCaja{}).Error
+}
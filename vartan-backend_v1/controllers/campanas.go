@@ -0,0 +1,514 @@
+package controllers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"vartan-backend/config"
+	"vartan-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Clave del reporte para las ventas sin canal cargado
+const sinCanal = "sin_canal"
+
+// GetCampanas godoc
+// @Summary Listar campañas
+// @Description Obtiene las campañas publicitarias activas con lo invertido, las ventas que se registraron con cada una y el ROI. Con todos=true incluye las inactivas.
+// @Tags Campañas
+// @Produce json
+// @Security BearerAuth
+// @Param canal query string false "Filtrar por canal"
+// @Param todos query bool false "Incluir inactivas"
+// @Success 200 {array} models.CampanaResponse
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/campanas [get]
+func GetCampanas(c *gin.Context) {
+	query := config.DB.Preload("Usuario").Order("fecha_creacion DESC, id DESC")
+	if c.Query("todos") != "true" {
+		query = query.Where("activo = ?", true)
+	}
+	if canal := c.Query("canal"); canal != "" {
+		query = query.Where("canal = ?", canal)
+	}
+
+	var campanas []models.Campana
+	if err := query.Find(&campanas).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener campañas"})
+		return
+	}
+
+	respuesta, err := conRendimiento(config.DB, campanas)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al calcular rendimiento de campañas"})
+		return
+	}
+
+	c.JSON(http.StatusOK, respuesta)
+}
+
+// GetCampana godoc
+// @Summary Obtener campaña
+// @Description Obtiene una campaña con sus gastos cargados y su rendimiento (solo dueño)
+// @Tags Campañas
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID de la campaña"
+// @Success 200 {object} models.CampanaResponse
+// @Failure 404 {object} map[string]string "Campaña no encontrada"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/campanas/{id} [get]
+func GetCampana(c *gin.Context) {
+	var campana models.Campana
+	if err := config.DB.Preload("Usuario").
+		Preload("Gastos", func(db *gorm.DB) *gorm.DB { return db.Order("fecha DESC, id DESC") }).
+		First(&campana, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Campaña no encontrada"})
+		return
+	}
+
+	respuesta, err := conRendimiento(config.DB, []models.Campana{campana})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al calcular rendimiento de la campaña"})
+		return
+	}
+
+	c.JSON(http.StatusOK, respuesta[0])
+}
+
+// CreateCampana godoc
+// @Summary Crear campaña
+// @Description Crea una campaña publicitaria en un canal, opcionalmente a cargo de un vendedor. El gasto de las campañas de un vendedor se descuenta de su comisión (solo dueño).
+// @Tags Campañas
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.CampanaCreateRequest true "Datos de la campaña"
+// @Success 201 {object} models.Campana
+// @Failure 400 {object} map[string]string "Datos inválidos"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/campanas [post]
+func CreateCampana(c *gin.Context) {
+	var req models.CampanaCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+
+	campana := models.Campana{
+		Nombre:     strings.TrimSpace(req.Nombre),
+		Canal:      req.Canal,
+		UsuarioID:  req.UsuarioID,
+		FechaDesde: req.FechaDesde,
+		FechaHasta: req.FechaHasta,
+		Activo:     true,
+	}
+	if err := validarCampana(config.DB, campana); err != nil {
+		responderError(c, err, "Error al validar campaña")
+		return
+	}
+
+	if err := config.DB.Create(&campana).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al crear campaña"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, campana)
+}
+
+// UpdateCampana godoc
+// @Summary Actualizar campaña
+// @Description Modifica el nombre, el vendedor a cargo, las fechas o el estado de la campaña. El canal no cambia para no alterar los reportes (solo dueño).
+// @Tags Campañas
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID de la campaña"
+// @Param request body models.CampanaUpdateRequest true "Datos a actualizar"
+// @Success 200 {object} models.Campana
+// @Failure 400 {object} map[string]string "Datos inválidos"
+// @Failure 404 {object} map[string]string "Campaña no encontrada"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/campanas/{id} [put]
+func UpdateCampana(c *gin.Context) {
+	var campana models.Campana
+	if err := config.DB.First(&campana, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Campaña no encontrada"})
+		return
+	}
+
+	var req models.CampanaUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+
+	if nombre := strings.TrimSpace(req.Nombre); nombre != "" {
+		campana.Nombre = nombre
+	}
+	if req.UsuarioID != nil {
+		// Cero deja la campaña sin vendedor a cargo
+		campana.UsuarioID = req.UsuarioID
+		if *req.UsuarioID == 0 {
+			campana.UsuarioID = nil
+		}
+	}
+	if req.FechaDesde != nil {
+		campana.FechaDesde = req.FechaDesde
+	}
+	if req.FechaHasta != nil {
+		campana.FechaHasta = req.FechaHasta
+	}
+	if req.Activo != nil {
+		campana.Activo = *req.Activo
+	}
+	if err := validarCampana(config.DB, campana); err != nil {
+		responderError(c, err, "Error al validar campaña")
+		return
+	}
+
+	if err := config.DB.Omit("Usuario", "Gastos").Save(&campana).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar campaña"})
+		return
+	}
+
+	c.JSON(http.StatusOK, campana)
+}
+
+// CrearGastoCampana godoc
+// @Summary Cargar gasto de campaña
+// @Description Registra lo invertido en publicidad en la campaña en una fecha (solo dueño)
+// @Tags Campañas
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID de la campaña"
+// @Param request body models.GastoCampanaRequest true "Gasto"
+// @Success 201 {object} models.GastoCampana
+// @Failure 400 {object} map[string]string "Datos inválidos"
+// @Failure 404 {object} map[string]string "Campaña no encontrada"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/campanas/{id}/gastos [post]
+func CrearGastoCampana(c *gin.Context) {
+	var campana models.Campana
+	if err := config.DB.First(&campana, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Campaña no encontrada"})
+		return
+	}
+
+	var req models.GastoCampanaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+
+	fecha, err := time.Parse("2006-01-02", req.Fecha)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Formato de fecha inválido. Use YYYY-MM-DD"})
+		return
+	}
+
+	gasto := models.GastoCampana{
+		CampanaID:   campana.ID,
+		Fecha:       fecha,
+		Monto:       redondear(req.Monto),
+		Descripcion: strings.TrimSpace(req.Descripcion),
+		UsuarioID:   c.GetInt("user_id"),
+	}
+	if err := config.DB.Create(&gasto).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al registrar gasto de campaña"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gasto)
+}
+
+// EliminarGastoCampana godoc
+// @Summary Eliminar gasto de campaña
+// @Description Elimina un gasto cargado por error. Las comisiones ya calculadas se corrigen al volver a calcularlas (solo dueño).
+// @Tags Campañas
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID de la campaña"
+// @Param gasto_id path int true "ID del gasto"
+// @Success 200 {object} map[string]string "Gasto eliminado"
+// @Failure 404 {object} map[string]string "Gasto no encontrado"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/campanas/{id}/gastos/{gasto_id} [delete]
+func EliminarGastoCampana(c *gin.Context) {
+	var gasto models.GastoCampana
+	if err := config.DB.Where("campana_id = ?", c.Param("id")).First(&gasto, c.Param("gasto_id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Gasto no encontrado"})
+		return
+	}
+
+	if err := config.DB.Delete(&gasto).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al eliminar gasto de campaña"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Gasto eliminado exitosamente"})
+}
+
+// GetReporteCanales godoc
+// @Summary Rendimiento por canal y vendedor
+// @Description Cantidad de ventas, ingresos (total final), inversión publicitaria y ROI de cada canal de venta y de cada vendedor en el período. El gasto de un vendedor es el de las campañas a su cargo (solo dueño).
+// @Tags Reportes
+// @Produce json
+// @Security BearerAuth
+// @Param fecha_desde query string false "Desde (YYYY-MM-DD), por defecto el primer día del mes"
+// @Param fecha_hasta query string false "Hasta inclusive (YYYY-MM-DD), por defecto hoy"
+// @Success 200 {object} models.ReporteCanalesResponse
+// @Failure 400 {object} map[string]string "Fechas inválidas"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/reportes/canales [get]
+func GetReporteCanales(c *gin.Context) {
+	desde, hasta, err := rangoFechas(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	fin := hasta.AddDate(0, 0, 1)
+
+	type fila struct {
+		Clave    string
+		Nombre   string
+		Ventas   int64
+		Ingresos float64
+		Gasto    float64
+	}
+
+	// Por canal
+	var ventasCanal, gastosCanal []fila
+	if err := config.DB.Model(&models.Venta{}).
+		Select("COALESCE(canal_venta, ?) AS clave, COUNT(*) AS ventas, COALESCE(SUM(total_final), 0) AS ingresos", sinCanal).
+		Where("fecha_venta >= ? AND fecha_venta < ?", desde, fin).
+		Group("1").
+		Scan(&ventasCanal).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al calcular ventas por canal"})
+		return
+	}
+	if err := config.DB.Table("campana_gastos g").
+		Select("ca.canal AS clave, SUM(g.monto) AS gasto").
+		Joins("JOIN campanas ca ON ca.id = g.campana_id").
+		Where("g.fecha >= ? AND g.fecha < ?", desde, fin).
+		Group("1").
+		Scan(&gastosCanal).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al calcular gastos por canal"})
+		return
+	}
+
+	canales := make(map[string]*models.RendimientoCanal)
+	for _, canal := range models.CanalesVenta {
+		canales[canal] = &models.RendimientoCanal{Clave: canal, Nombre: models.NombresCanal[canal]}
+	}
+	canal := func(clave string) *models.RendimientoCanal {
+		if canales[clave] == nil {
+			nombre := clave
+			if clave == sinCanal {
+				nombre = "Sin canal"
+			}
+			canales[clave] = &models.RendimientoCanal{Clave: clave, Nombre: nombre}
+		}
+		return canales[clave]
+	}
+	for _, v := range ventasCanal {
+		r := canal(v.Clave)
+		r.Ventas, r.Ingresos = v.Ventas, v.Ingresos
+	}
+	for _, g := range gastosCanal {
+		canal(g.Clave).GastoPublicitario = g.Gasto
+	}
+
+	// Por vendedor
+	var ventasVendedor, gastosVendedor []fila
+	if err := config.DB.Table("venta v").
+		Select("v.usuario_id::text AS clave, u.nombre, COUNT(*) AS ventas, COALESCE(SUM(v.total_final), 0) AS ingresos").
+		Joins("JOIN usuarios u ON u.id = v.usuario_id").
+		Where("v.fecha_venta >= ? AND v.fecha_venta < ?", desde, fin).
+		Group("1, 2").
+		Scan(&ventasVendedor).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al calcular ventas por vendedor"})
+		return
+	}
+	if err := config.DB.Table("campana_gastos g").
+		Select("ca.usuario_id::text AS clave, u.nombre, SUM(g.monto) AS gasto").
+		Joins("JOIN campanas ca ON ca.id = g.campana_id").
+		Joins("JOIN usuarios u ON u.id = ca.usuario_id").
+		Where("g.fecha >= ? AND g.fecha < ?", desde, fin).
+		Group("1, 2").
+		Scan(&gastosVendedor).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al calcular gastos por vendedor"})
+		return
+	}
+
+	vendedores := make(map[string]*models.RendimientoCanal)
+	vendedor := func(f fila) *models.RendimientoCanal {
+		if vendedores[f.Clave] == nil {
+			vendedores[f.Clave] = &models.RendimientoCanal{Clave: f.Clave, Nombre: f.Nombre}
+		}
+		return vendedores[f.Clave]
+	}
+	for _, v := range ventasVendedor {
+		r := vendedor(v)
+		r.Ventas, r.Ingresos = v.Ventas, v.Ingresos
+	}
+	for _, g := range gastosVendedor {
+		vendedor(g).GastoPublicitario = g.Gasto
+	}
+
+	respuesta := models.ReporteCanalesResponse{
+		FechaDesde: desde.Format("2006-01-02"),
+		FechaHasta: hasta.Format("2006-01-02"),
+		Canales:    ordenarRendimientos(canales),
+		Vendedores: ordenarRendimientos(vendedores),
+	}
+
+	c.JSON(http.StatusOK, respuesta)
+}
+
+// ordenarRendimientos completa el ROI y ordena de mayor a menor ingreso
+func ordenarRendimientos(rendimientos map[string]*models.RendimientoCanal) []models.RendimientoCanal {
+	lista := make([]models.RendimientoCanal, 0, len(rendimientos))
+	for _, r := range rendimientos {
+		r.Ingresos = redondear(r.Ingresos)
+		r.GastoPublicitario = redondear(r.GastoPublicitario)
+		r.ROI = calcularROI(r.Ingresos, r.GastoPublicitario)
+		lista = append(lista, *r)
+	}
+	sort.Slice(lista, func(i, j int) bool {
+		if lista[i].Ingresos != lista[j].Ingresos {
+			return lista[i].Ingresos > lista[j].Ingresos
+		}
+		return lista[i].Clave < lista[j].Clave
+	})
+	return lista
+}
+
+// calcularROI devuelve (ingresos - gasto) / gasto en porcentaje, o nil si no hubo gasto
+func calcularROI(ingresos, gasto float64) *float64 {
+	if gasto <= 0 {
+		return nil
+	}
+	roi := redondear((ingresos - gasto) / gasto * 100)
+	return &roi
+}
+
+// conRendimiento agrega a cada campaña lo invertido y lo vendido con ella
+func conRendimiento(db *gorm.DB, campanas []models.Campana) ([]models.CampanaResponse, error) {
+	respuesta := make([]models.CampanaResponse, len(campanas))
+	if len(campanas) == 0 {
+		return respuesta, nil
+	}
+	ids := make([]int, len(campanas))
+	for i, campana := range campanas {
+		ids[i] = campana.ID
+	}
+
+	var gastos []struct {
+		CampanaID int
+		Total     float64
+	}
+	if err := db.Model(&models.GastoCampana{}).
+		Select("campana_id, SUM(monto) AS total").
+		Where("campana_id IN ?", ids).
+		Group("campana_id").
+		Scan(&gastos).Error; err != nil {
+		return nil, err
+	}
+	var ventas []struct {
+		CampanaID int
+		Ventas    int64
+		Ingresos  float64
+	}
+	if err := db.Model(&models.Venta{}).
+		Select("campana_id, COUNT(*) AS ventas, SUM(total_final) AS ingresos").
+		Where("campana_id IN ?", ids).
+		Group("campana_id").
+		Scan(&ventas).Error; err != nil {
+		return nil, err
+	}
+
+	indice := make(map[int]int, len(campanas))
+	for i, campana := range campanas {
+		respuesta[i] = models.CampanaResponse{Campana: campana}
+		indice[campana.ID] = i
+	}
+	for _, g := range gastos {
+		respuesta[indice[g.CampanaID]].TotalGastado = redondear(g.Total)
+	}
+	for _, v := range ventas {
+		r := &respuesta[indice[v.CampanaID]]
+		r.Ventas, r.Ingresos = v.Ventas, redondear(v.Ingresos)
+	}
+	for i := range respuesta {
+		respuesta[i].ROI = calcularROI(respuesta[i].Ingresos, respuesta[i].TotalGastado)
+	}
+	return respuesta, nil
+}
+
+// validarCampana verifica el canal, las fechas y que el vendedor a cargo exista
+func validarCampana(db *gorm.DB, campana models.Campana) error {
+	if campana.Nombre == "" {
+		return &ventaError{http.StatusBadRequest, "Indique el nombre de la campaña"}
+	}
+	if !models.EsCanalVenta(campana.Canal) {
+		return &ventaError{http.StatusBadRequest, "Canal inválido. Use " + strings.Join(models.CanalesVenta, ", ")}
+	}
+	if campana.FechaDesde != nil && campana.FechaHasta != nil && campana.FechaHasta.Before(*campana.FechaDesde) {
+		return &ventaError{http.StatusBadRequest, "fecha_hasta no puede ser anterior a fecha_desde"}
+	}
+	if campana.UsuarioID != nil {
+		var usuario models.Usuario
+		if err := db.First(&usuario, *campana.UsuarioID).Error; err != nil {
+			return &ventaError{http.StatusBadRequest, "Vendedor no encontrado"}
+		}
+		if usuario.Rol != "empleado" && usuario.Rol != "dueño" {
+			return &ventaError{http.StatusBadRequest, "El usuario seleccionado no es un vendedor"}
+		}
+	}
+	return nil
+}
+
+// origenVenta valida el canal y la campaña de una venta. Sin canal se usa el de la campaña; si se
+// indican los dos deben coincidir.
+func origenVenta(db *gorm.DB, canal string, campanaID *int) (*string, *int, error) {
+	canal = strings.ToLower(strings.TrimSpace(canal))
+	if canal != "" && !models.EsCanalVenta(canal) {
+		return nil, nil, &ventaError{http.StatusBadRequest, "Canal de venta inválido. Use " + strings.Join(models.CanalesVenta, ", ")}
+	}
+
+	if campanaID != nil && *campanaID > 0 {
+		var campana models.Campana
+		if err := db.First(&campana, *campanaID).Error; err != nil {
+			return nil, nil, &ventaError{http.StatusBadRequest, "Campaña no encontrada"}
+		}
+		if canal == "" {
+			canal = campana.Canal
+		} else if canal != campana.Canal {
+			return nil, nil, &ventaError{http.StatusBadRequest, "La campaña " + strconv.Quote(campana.Nombre) + " es del canal " + campana.Canal}
+		}
+		return &canal, &campana.ID, nil
+	}
+
+	if canal == "" {
+		return nil, nil, nil
+	}
+	return &canal, nil, nil
+}
+
+// gastoPublicitarioMes suma lo invertido en el mes en las campañas a cargo del vendedor
+func gastoPublicitarioMes(db *gorm.DB, usuarioID, mes, anio int) (float64, error) {
+	desde := time.Date(anio, time.Month(mes), 1, 0, 0, 0, 0, time.Local)
+	var total float64
+	err := db.Table("campana_gastos g").
+		Select("COALESCE(SUM(g.monto), 0)").
+		Joins("JOIN campanas ca ON ca.id = g.campana_id").
+		Where("ca.usuario_id = ? AND g.fecha >= ? AND g.fecha < ?", usuarioID, desde, desde.AddDate(0, 1, 0)).
+		Scan(&total).Error
+	return total, err
+}
@@ -2,31 +2,114 @@ package controllers
 
 import (
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 	"vartan-backend/config"
 	"vartan-backend/models"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // GetClientes godoc
 // @Summary Listar clientes
-// @Description Obtiene todos los clientes ordenados por nombre
+// @Description Busca clientes activos por nombre, teléfono, email o ciudad, ordenados por nombre y paginados
 // @Tags Clientes
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {array} models.Cliente
+// @Param q query string false "Texto a buscar en nombre, teléfono, email o ciudad"
+// @Param nombre query string false "Filtrar por nombre"
+// @Param telefono query string false "Filtrar por teléfono (se ignoran espacios, guiones y prefijos)"
+// @Param email query string false "Filtrar por email"
+// @Param ciudad query string false "Filtrar por ciudad"
+// @Param archivados query bool false "Listar solo los clientes archivados"
+// @Param page query int false "Página (por defecto 1)"
+// @Param limit query int false "Resultados por página (por defecto 50, máximo 500)"
+// @Success 200 {object} map[string]interface{} "clientes, total, page, limit"
 // @Failure 500 {object} map[string]string "Error interno"
 // @Router /api/clientes [get]
 func GetClientes(c *gin.Context) {
-	var clientes []models.Cliente
+	query := config.DB.Model(&models.Cliente{})
+	if c.Query("archivados") == "true" {
+		query = query.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		like := "%" + q + "%"
+		condicion := "nombre ILIKE ? OR email ILIKE ? OR ciudad ILIKE ?"
+		args := []interface{}{like, like, like}
+		if telefono := models.NormalizarTelefono(q); len(telefono) >= 6 {
+			condicion += " OR " + models.TelefonoNormalizadoSQL("telefono") + " LIKE ?"
+			args = append(args, "%"+telefono+"%")
+		}
+		query = query.Where(condicion, args...)
+	}
+
+	if nombre := c.Query("nombre"); nombre != "" {
+		query = query.Where("nombre ILIKE ?", "%"+nombre+"%")
+	}
+	if telefono := models.NormalizarTelefono(c.Query("telefono")); telefono != "" {
+		query = query.Where(models.TelefonoNormalizadoSQL("telefono")+" LIKE ?", "%"+telefono+"%")
+	}
+	if email := c.Query("email"); email != "" {
+		query = query.Where("email ILIKE ?", "%"+email+"%")
+	}
+	if ciudad := c.Query("ciudad"); ciudad != "" {
+		query = query.Where("ciudad ILIKE ?", "%"+ciudad+"%")
+	}
 
-	if err := config.DB.Order("nombre ASC").Find(&clientes).Error; err != nil {
+	// Paginación
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit < 1 || limit > 500 {
+		limit = 50
+	}
+
+	// Contar total
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener clientes"})
+		return
+	}
+
+	var clientes []models.Cliente
+	if err := query.Order("nombre ASC").Limit(limit).Offset((page - 1) * limit).Find(&clientes).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener clientes"})
 		return
 	}
 
-	c.JSON(http.StatusOK, clientes)
+	c.JSON(http.StatusOK, gin.H{
+		"clientes": clientes,
+		"total":    total,
+		"page":     page,
+		"limit":    limit,
+	})
+}
+
+// GetClientesDuplicados godoc
+// @Summary Buscar posibles duplicados
+// @Description Devuelve los clientes con el mismo teléfono o email normalizados, para avisar antes de crear uno nuevo
+// @Tags Clientes
+// @Produce json
+// @Security BearerAuth
+// @Param telefono query string false "Teléfono"
+// @Param email query string false "Email"
+// @Success 200 {array} models.Cliente
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/clientes/duplicados [get]
+func GetClientesDuplicados(c *gin.Context) {
+	duplicados, err := buscarDuplicados(c.Query("telefono"), c.Query("email"), 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al buscar duplicados"})
+		return
+	}
+
+	c.JSON(http.StatusOK, duplicados)
 }
 
 // GetCliente godoc
@@ -43,8 +126,9 @@ func GetClientes(c *gin.Context) {
 func GetCliente(c *gin.Context) {
 	id := c.Param("id")
 
+	// Incluye archivados para poder consultar los clientes de ventas históricas
 	var cliente models.Cliente
-	if err := config.DB.First(&cliente, id).Error; err != nil {
+	if err := config.DB.Unscoped().First(&cliente, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Cliente no encontrado"})
 		return
 	}
@@ -54,13 +138,13 @@ func GetCliente(c *gin.Context) {
 
 // CreateCliente godoc
 // @Summary Crear cliente
-// @Description Crea un nuevo cliente
+// @Description Crea un nuevo cliente y avisa si ya hay clientes con el mismo teléfono o email
 // @Tags Clientes
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param request body models.ClienteCreateRequest true "Datos del cliente"
-// @Success 201 {object} models.Cliente
+// @Success 201 {object} models.ClienteCreateResponse "Cliente creado; incluye posibles_duplicados si el teléfono o email ya existían"
 // @Failure 400 {object} map[string]string "Datos inválidos"
 // @Failure 500 {object} map[string]string "Error interno"
 // @Router /api/clientes [post]
@@ -82,12 +166,22 @@ func CreateCliente(c *gin.Context) {
 		Pais:      req.Pais,
 	}
 
+	// Buscar duplicados antes de crear para no incluir al cliente nuevo
+	duplicados, err := buscarDuplicados(req.Telefono, req.Email, 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al buscar duplicados"})
+		return
+	}
+
 	if err := config.DB.Create(&cliente).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al crear cliente"})
 		return
 	}
 
-	c.JSON(http.StatusCreated, cliente)
+	c.JSON(http.StatusCreated, models.ClienteCreateResponse{
+		Cliente:            cliente,
+		PosiblesDuplicados: duplicados,
+	})
 }
 
 // UpdateCliente godoc
@@ -136,23 +230,319 @@ func UpdateCliente(c *gin.Context) {
 }
 
 // DeleteCliente godoc
-// @Summary Eliminar cliente
-// @Description Elimina un cliente (solo dueño)
+// @Summary Eliminar o archivar cliente
+// @Description Archiva un cliente (solo dueño): deja de aparecer en los listados pero sigue visible en sus ventas. Con permanente=true lo borra definitivamente, solo si no tiene ventas, presupuestos ni movimientos de cuenta corriente.
 // @Tags Clientes
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "ID del cliente"
-// @Success 200 {object} map[string]string "Cliente eliminado exitosamente"
+// @Param permanente query bool false "Borrar definitivamente en lugar de archivar"
+// @Success 200 {object} map[string]string "Cliente archivado o eliminado exitosamente"
+// @Failure 404 {object} map[string]string "Cliente no encontrado"
+// @Failure 409 {object} map[string]string "El cliente tiene actividad y no puede borrarse"
 // @Failure 500 {object} map[string]string "Error interno"
 // @Router /api/owner/clientes/{id} [delete]
 func DeleteCliente(c *gin.Context) {
 	id := c.Param("id")
 
-	if err := config.DB.Delete(&models.Cliente{}, id).Error; err != nil {
+	var cliente models.Cliente
+	if err := config.DB.Unscoped().First(&cliente, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cliente no encontrado"})
+		return
+	}
+
+	if c.Query("permanente") != "true" {
+		if cliente.DeletedAt.Valid {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "El cliente ya está archivado"})
+			return
+		}
+		if err := config.DB.Delete(&cliente).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al archivar cliente"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Cliente archivado exitosamente"})
+		return
+	}
+
+	actividad, err := clienteTieneActividad(cliente.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al verificar actividad del cliente"})
+		return
+	}
+	if actividad {
+		c.JSON(http.StatusConflict, gin.H{"error": "El cliente tiene ventas, presupuestos, movimientos de cuenta corriente u otros registros asociados; solo puede archivarse"})
+		return
+	}
+
+	if err := config.DB.Unscoped().Delete(&cliente).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al eliminar cliente"})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Cliente eliminado exitosamente"})
 }
+
+// RestaurarCliente godoc
+// @Summary Restaurar cliente archivado
+// @Description Vuelve a activar un cliente archivado (solo dueño)
+// @Tags Clientes
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID del cliente"
+// @Success 200 {object} models.Cliente
+// @Failure 400 {object} map[string]string "El cliente no está archivado"
+// @Failure 404 {object} map[string]string "Cliente no encontrado"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/clientes/{id}/restaurar [post]
+func RestaurarCliente(c *gin.Context) {
+	var cliente models.Cliente
+	if err := config.DB.Unscoped().First(&cliente, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cliente no encontrado"})
+		return
+	}
+
+	if !cliente.DeletedAt.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "El cliente no está archivado"})
+		return
+	}
+
+	if err := config.DB.Unscoped().Model(&cliente).Update("deleted_at", nil).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al restaurar cliente"})
+		return
+	}
+	cliente.DeletedAt = gorm.DeletedAt{}
+
+	c.JSON(http.StatusOK, cliente)
+}
+
+// FusionarClientes godoc
+// @Summary Fusionar clientes duplicados
+// @Description Pasa las ventas, presupuestos y cuenta corriente de los duplicados al cliente indicado y elimina los duplicados (solo dueño). Los datos vacíos del cliente se completan con los de los duplicados.
+// @Tags Clientes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID del cliente que se conserva"
+// @Param request body models.ClienteFusionRequest true "Clientes duplicados"
+// @Success 200 {object} models.Cliente
+// @Failure 400 {object} map[string]string "Datos inválidos"
+// @Failure 404 {object} map[string]string "Cliente no encontrado"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/clientes/{id}/fusionar [post]
+func FusionarClientes(c *gin.Context) {
+	var cliente models.Cliente
+	if err := config.DB.First(&cliente, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cliente no encontrado"})
+		return
+	}
+
+	var req models.ClienteFusionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+
+	for _, id := range req.ClienteIDs {
+		if id == cliente.ID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "No se puede fusionar un cliente consigo mismo"})
+			return
+		}
+	}
+
+	var duplicados []models.Cliente
+	if err := config.DB.Unscoped().Where("id IN ?", req.ClienteIDs).Order("fecha_creacion ASC").Find(&duplicados).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener clientes"})
+		return
+	}
+	if len(duplicados) != len(req.ClienteIDs) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Alguno de los clientes a fusionar no existe"})
+		return
+	}
+
+	// Completar los datos vacíos con los de los duplicados
+	for _, d := range duplicados {
+		completarCampo(&cliente.Telefono, d.Telefono)
+		completarCampo(&cliente.Email, d.Email)
+		completarCampo(&cliente.Direccion, d.Direccion)
+		completarCampo(&cliente.Ciudad, d.Ciudad)
+		completarCampo(&cliente.Provincia, d.Provincia)
+		completarCampo(&cliente.Pais, d.Pais)
+	}
+
+	tx := config.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Re-apuntar todo lo que referencia a los duplicados
+	for _, modelo := range modelosConCliente() {
+		if err := tx.Model(modelo).Where("cliente_id IN ?", req.ClienteIDs).Update("cliente_id", cliente.ID).Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al reasignar registros del cliente"})
+			return
+		}
+	}
+
+	if err := tx.Save(&cliente).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar cliente"})
+		return
+	}
+
+	// Ya no tienen registros asociados: se borran definitivamente en lugar de archivarse
+	if err := tx.Unscoped().Delete(&models.Cliente{}, req.ClienteIDs).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al eliminar duplicados"})
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al confirmar fusión"})
+		return
+	}
+
+	c.JSON(http.StatusOK, cliente)
+}
+
+// GetClienteHistorial godoc
+// @Summary Historial de compras del cliente
+// @Description Devuelve las compras del cliente, el total gastado, la última compra y sus equipos favoritos
+// @Tags Clientes
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID del cliente"
+// @Success 200 {object} models.ClienteHistorialResponse
+// @Failure 404 {object} map[string]string "Cliente no encontrado"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/clientes/{id}/historial [get]
+func GetClienteHistorial(c *gin.Context) {
+	var cliente models.Cliente
+	if err := config.DB.Unscoped().First(&cliente, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cliente no encontrado"})
+		return
+	}
+
+	response := models.ClienteHistorialResponse{Cliente: cliente}
+
+	var resumen struct {
+		Cantidad     int64
+		Total        float64
+		UltimaCompra *time.Time
+	}
+	if err := config.DB.Model(&models.Venta{}).
+		Select("COUNT(*) AS cantidad, COALESCE(SUM(total_final), 0) AS total, MAX(fecha_venta) AS ultima_compra").
+		Where("cliente_id = ?", cliente.ID).
+		Scan(&resumen).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener resumen de compras"})
+		return
+	}
+	response.CantidadCompras = resumen.Cantidad
+	response.TotalGastado = resumen.Total
+	response.UltimaCompra = resumen.UltimaCompra
+	if resumen.Cantidad > 0 {
+		response.TicketPromedio = redondear(resumen.Total / float64(resumen.Cantidad))
+	}
+
+	response.EquiposFavoritos = []models.EquipoFavorito{}
+	if err := config.DB.Table("venta_detalles AS vd").
+		Select("e.id AS equipo_id, e.nombre, SUM(vd.cantidad) AS unidades, SUM(vd.subtotal) AS total").
+		Joins("JOIN venta v ON v.id = vd.venta_id").
+		Joins("JOIN productos p ON p.id = vd.producto_id").
+		Joins("JOIN equipos e ON e.id = p.equipo_id").
+		Where("v.cliente_id = ?", cliente.ID).
+		Group("e.id, e.nombre").
+		Order("unidades DESC, total DESC").
+		Limit(3).
+		Scan(&response.EquiposFavoritos).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener equipos favoritos"})
+		return
+	}
+
+	if err := config.DB.
+		Where("cliente_id = ?", cliente.ID).
+		Preload("Usuario").
+		Preload("FormaPago").
+		Preload("Detalles").
+		Preload("Detalles.Producto").
+		Order("fecha_venta DESC").
+		Find(&response.Ventas).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener ventas"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// modelosConCliente - Registros que referencian al cliente: se re-apuntan al fusionar duplicados
+// e impiden borrarlo definitivamente
+func modelosConCliente() []interface{} {
+	return []interface{}{
+		&models.Venta{},
+		&models.Presupuesto{},
+		&models.MovimientoCuentaCorriente{},
+		&models.MovimientoPuntos{},
+		&models.SaldoAFavor{},
+		&models.MovimientoCaja{},
+	}
+}
+
+// clienteTieneActividad indica si el cliente tiene registros asociados (ventas, presupuestos,
+// movimientos de cuenta corriente o de caja, puntos, saldos a favor)
+func clienteTieneActividad(clienteID int) (bool, error) {
+	for _, modelo := range modelosConCliente() {
+		var cantidad int64
+		if err := config.DB.Model(modelo).Where("cliente_id = ?", clienteID).Count(&cantidad).Error; err != nil {
+			return false, err
+		}
+		if cantidad > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// conArchivados se usa al precargar el cliente de registros históricos (ventas, pedidos,
+// presupuestos) para que sigan mostrándolo aunque esté archivado
+func conArchivados(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
+
+// buscarDuplicados busca clientes con el mismo teléfono o email normalizados, excluyendo excluirID
+func buscarDuplicados(telefono, email string, excluirID int) ([]models.Cliente, error) {
+	telefono = models.NormalizarTelefono(telefono)
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	var condiciones []string
+	var args []interface{}
+	// Con menos de 6 dígitos hay demasiadas coincidencias casuales
+	if len(telefono) >= 6 {
+		condiciones = append(condiciones, models.TelefonoNormalizadoSQL("telefono")+" = ?")
+		args = append(args, telefono)
+	}
+	if email != "" {
+		condiciones = append(condiciones, "LOWER(TRIM(email)) = ?")
+		args = append(args, email)
+	}
+
+	duplicados := []models.Cliente{}
+	if len(condiciones) == 0 {
+		return duplicados, nil
+	}
+
+	err := config.DB.
+		Where(strings.Join(condiciones, " OR "), args...).
+		Where("id <> ?", excluirID).
+		Order("nombre ASC").
+		Find(&duplicados).Error
+	return duplicados, err
+}
+
+// completarCampo copia valor en destino solo si destino está vacío
+func completarCampo(destino *string, valor string) {
+	if strings.TrimSpace(*destino) == "" && valor != "" {
+		*destino = valor
+	}
+}
@@ -0,0 +1,334 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"vartan-backend/config"
+	"vartan-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetCombos godoc
+// @Summary Listar combos
+// @Description Obtiene los combos activos con sus componentes y las unidades disponibles según el stock de cada componente. Para los componentes sin variante fija se cuenta el stock de todos los talles y colores. Con todos=true incluye los inactivos.
+// @Tags Combos
+// @Produce json
+// @Security BearerAuth
+// @Param todos query bool false "Incluir inactivos"
+// @Success 200 {array} models.ComboResponse
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/combos [get]
+func GetCombos(c *gin.Context) {
+	query := precargarComponentes(config.DB).Order("nombre, id")
+	if c.Query("todos") != "true" {
+		query = query.Where("activo = ?", true)
+	}
+
+	var combos []models.Combo
+	if err := query.Find(&combos).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener combos"})
+		return
+	}
+
+	respuesta, err := conDisponibilidad(config.DB, combos)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al calcular disponibilidad de combos"})
+		return
+	}
+
+	c.JSON(http.StatusOK, respuesta)
+}
+
+// GetCombo godoc
+// @Summary Obtener combo
+// @Description Obtiene un combo con sus componentes y las unidades disponibles
+// @Tags Combos
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID del combo"
+// @Success 200 {object} models.ComboResponse
+// @Failure 404 {object} map[string]string "Combo no encontrado"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/combos/{id} [get]
+func GetCombo(c *gin.Context) {
+	var combo models.Combo
+	if err := precargarComponentes(config.DB).First(&combo, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Combo no encontrado"})
+		return
+	}
+
+	respuesta, err := conDisponibilidad(config.DB, []models.Combo{combo})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al calcular disponibilidad del combo"})
+		return
+	}
+
+	c.JSON(http.StatusOK, respuesta[0])
+}
+
+// CreateCombo godoc
+// @Summary Crear combo
+// @Description Crea un combo con sus componentes (producto, variante opcional y cantidad) y el precio del paquete (solo dueño)
+// @Tags Combos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.ComboCreateRequest true "Datos del combo"
+// @Success 201 {object} models.Combo
+// @Failure 400 {object} map[string]string "Datos inválidos"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/combos [post]
+func CreateCombo(c *gin.Context) {
+	var req models.ComboCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+
+	componentes, err := armarComponentes(config.DB, req.Componentes)
+	if err != nil {
+		responderError(c, err, "Error al validar componentes")
+		return
+	}
+
+	combo := models.Combo{
+		Nombre:      strings.TrimSpace(req.Nombre),
+		Precio:      req.Precio,
+		Activo:      true,
+		Componentes: componentes,
+	}
+	if err := config.DB.Create(&combo).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al crear combo"})
+		return
+	}
+
+	precargarComponentes(config.DB).First(&combo, combo.ID)
+	c.JSON(http.StatusCreated, combo)
+}
+
+// UpdateCombo godoc
+// @Summary Actualizar combo
+// @Description Modifica el nombre, precio o estado del combo. Si se envían componentes reemplazan a los actuales; las ventas ya registradas no cambian (solo dueño).
+// @Tags Combos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID del combo"
+// @Param request body models.ComboUpdateRequest true "Datos a actualizar"
+// @Success 200 {object} models.Combo
+// @Failure 400 {object} map[string]string "Datos inválidos"
+// @Failure 404 {object} map[string]string "Combo no encontrado"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/combos/{id} [put]
+func UpdateCombo(c *gin.Context) {
+	var combo models.Combo
+	if err := config.DB.First(&combo, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Combo no encontrado"})
+		return
+	}
+
+	var req models.ComboUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+
+	if nombre := strings.TrimSpace(req.Nombre); nombre != "" {
+		combo.Nombre = nombre
+	}
+	if req.Precio != nil {
+		combo.Precio = *req.Precio
+	}
+	if req.Activo != nil {
+		combo.Activo = *req.Activo
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&combo).Error; err != nil {
+			return err
+		}
+		if req.Componentes == nil {
+			return nil
+		}
+		componentes, err := armarComponentes(tx, req.Componentes)
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("combo_id = ?", combo.ID).Delete(&models.ComboComponente{}).Error; err != nil {
+			return err
+		}
+		for i := range componentes {
+			componentes[i].ComboID = combo.ID
+		}
+		return tx.Create(&componentes).Error
+	})
+	if err != nil {
+		responderError(c, err, "Error al actualizar combo")
+		return
+	}
+
+	precargarComponentes(config.DB).First(&combo, combo.ID)
+	c.JSON(http.StatusOK, combo)
+}
+
+func precargarComponentes(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Componentes", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Componentes.Producto").
+		Preload("Componentes.Variante")
+}
+
+// armarComponentes valida que cada producto exista y esté activo y que la variante, si se indica,
+// sea del producto
+func armarComponentes(db *gorm.DB, req []models.ComboComponenteRequest) ([]models.ComboComponente, error) {
+	componentes := make([]models.ComboComponente, 0, len(req))
+	unidades := 0
+	for _, r := range req {
+		var producto models.Producto
+		if err := db.Select("id", "nombre", "activo").First(&producto, r.ProductoID).Error; err != nil {
+			return nil, &ventaError{http.StatusBadRequest, fmt.Sprintf("Producto %d no encontrado", r.ProductoID)}
+		}
+		if !producto.Activo {
+			return nil, &ventaError{http.StatusBadRequest, "El producto " + producto.Nombre + " no está activo"}
+		}
+		if r.VarianteID != nil {
+			var variante models.VarianteProducto
+			if err := db.First(&variante, *r.VarianteID).Error; err != nil || variante.ProductoID != producto.ID {
+				return nil, &ventaError{http.StatusBadRequest, "La variante no corresponde al producto " + producto.Nombre}
+			}
+		}
+		unidades += r.Cantidad
+		componentes = append(componentes, models.ComboComponente{
+			ProductoID: r.ProductoID,
+			VarianteID: r.VarianteID,
+			Cantidad:   r.Cantidad,
+		})
+	}
+	if unidades < 2 {
+		return nil, &ventaError{http.StatusBadRequest, "Un combo debe incluir al menos dos unidades"}
+	}
+	return componentes, nil
+}
+
+// conDisponibilidad calcula cuántos combos se pueden armar: el mínimo, entre los componentes,
+// del stock dividido la cantidad que lleva cada combo
+func conDisponibilidad(db *gorm.DB, combos []models.Combo) ([]models.ComboResponse, error) {
+	var productoIDs, varianteIDs []int
+	for _, combo := range combos {
+		for _, comp := range combo.Componentes {
+			if comp.VarianteID != nil {
+				varianteIDs = append(varianteIDs, *comp.VarianteID)
+			} else {
+				productoIDs = append(productoIDs, comp.ProductoID)
+			}
+		}
+	}
+
+	type stockID struct {
+		ID       int
+		Cantidad int
+	}
+	porProducto := map[int]int{}
+	porVariante := map[int]int{}
+	if len(productoIDs) > 0 {
+		var filas []stockID
+		if err := db.Model(&models.ProductoStock{}).
+			Select("producto_id AS id, COALESCE(SUM(cantidad), 0) AS cantidad").
+			Where("producto_id IN ?", productoIDs).
+			Group("producto_id").
+			Scan(&filas).Error; err != nil {
+			return nil, err
+		}
+		for _, f := range filas {
+			porProducto[f.ID] = f.Cantidad
+		}
+	}
+	if len(varianteIDs) > 0 {
+		var filas []stockID
+		if err := db.Table("variantes_producto v").
+			Select("v.id, s.cantidad").
+			Joins("JOIN producto_stocks s ON s.id = v.stock_id").
+			Where("v.id IN ?", varianteIDs).
+			Scan(&filas).Error; err != nil {
+			return nil, err
+		}
+		for _, f := range filas {
+			porVariante[f.ID] = f.Cantidad
+		}
+	}
+
+	respuesta := make([]models.ComboResponse, len(combos))
+	for i, combo := range combos {
+		disponibles := -1
+		for _, comp := range combo.Componentes {
+			stock := porProducto[comp.ProductoID]
+			if comp.VarianteID != nil {
+				stock = porVariante[*comp.VarianteID]
+			}
+			if n := max(stock, 0) / comp.Cantidad; disponibles < 0 || n < disponibles {
+				disponibles = n
+			}
+		}
+		respuesta[i] = models.ComboResponse{Combo: combo, Disponibles: max(disponibles, 0)}
+	}
+	return respuesta, nil
+}
+
+// expandirCombos convierte cada combo vendido en un renglón por componente, con el precio del
+// combo repartido según el precio de lista de los componentes (el de venta o, si no está cargado,
+// el costo). Así los reportes de margen, productos y comisiones ven cada producto vendido.
+// Devuelve también el combo de cada renglón generado.
+func expandirCombos(tx *gorm.DB, combos []models.VentaComboCreateRequest) ([]models.VentaDetalleCreateRequest, []int, error) {
+	var detalles []models.VentaDetalleCreateRequest
+	var comboIDs []int
+
+	for _, vc := range combos {
+		var combo models.Combo
+		if err := precargarComponentes(tx).First(&combo, vc.ComboID).Error; err != nil {
+			return nil, nil, &ventaError{http.StatusBadRequest, fmt.Sprintf("Combo %d no encontrado", vc.ComboID)}
+		}
+		if !combo.Activo {
+			return nil, nil, &ventaError{http.StatusBadRequest, "El combo " + combo.Nombre + " no está activo"}
+		}
+
+		selecciones := map[int]models.ComboSeleccionRequest{}
+		for _, s := range vc.Selecciones {
+			selecciones[s.ComponenteID] = s
+		}
+
+		pesos := make([]float64, len(combo.Componentes))
+		cantidades := make([]int, len(combo.Componentes))
+		for i, comp := range combo.Componentes {
+			precio := comp.Producto.PrecioVenta
+			if precio <= 0 {
+				precio = comp.Producto.CostoUnitario
+			}
+			pesos[i] = precio * float64(comp.Cantidad)
+			cantidades[i] = comp.Cantidad
+		}
+		unitarios := models.RepartirPrecioCombo(combo.Precio, pesos, cantidades)
+
+		for i, comp := range combo.Componentes {
+			detalle := models.VentaDetalleCreateRequest{
+				VarianteID:     comp.VarianteID,
+				ProductoID:     comp.ProductoID,
+				Cantidad:       comp.Cantidad * vc.Cantidad,
+				PrecioUnitario: unitarios[i],
+			}
+			if comp.VarianteID == nil {
+				s, ok := selecciones[comp.ID]
+				if !ok || (s.VarianteID == nil && s.Talle == "") {
+					return nil, nil, &ventaError{http.StatusBadRequest, "Falta elegir el talle de " + comp.Producto.Nombre + " en el combo " + combo.Nombre}
+				}
+				detalle.VarianteID, detalle.Talle, detalle.Color = s.VarianteID, s.Talle, s.Color
+			}
+			detalles = append(detalles, detalle)
+			comboIDs = append(comboIDs, combo.ID)
+		}
+	}
+
+	return detalles, comboIDs, nil
+}
@@ -87,7 +87,7 @@ func GetAllComisiones(c *gin.Context) {
 
 // CalcularComisionesMesActual godoc
 // @Summary Calcular comisiones del mes
-// @Description Calcula las comisiones del mes actual para todos los empleados (solo dueño)
+// @Description Calcula las comisiones del mes actual para todos los empleados sobre lo vendido sin las devoluciones, descontando lo invertido en el mes en las campañas a cargo de cada uno y sumando el bono de quienes cumplieron su objetivo (solo dueño)
 // @Tags Comisiones
 // @Accept json
 // @Produce json
@@ -108,23 +108,36 @@ func CalcularComisionesMesActual(c *gin.Context) {
 	}
 
 	for _, usuario := range usuarios {
-		// Calcular ventas del mes (sintaxis PostgreSQL)
-		var totalVentas float64
-		config.DB.Model(&models.Venta{}).
-			Where("usuario_id = ? AND EXTRACT(MONTH FROM fecha_venta) = ? AND EXTRACT(YEAR FROM fecha_venta) = ?", usuario.ID, mes, anio).
-			Select("COALESCE(SUM(total_final), 0)").
-			Scan(&totalVentas)
+		// Ventas del mes sin lo reintegrado por devoluciones, igual que en el progreso del objetivo
+		progreso, err := progresoVendedores(config.DB, mes, anio, []models.Usuario{usuario})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al calcular ventas del mes"})
+			return
+		}
+		totalVentas := progreso[0].Vendido
 
 		// Calcular comisión usando el porcentaje configurado del usuario
 		porcentaje := usuario.PorcentajeComision / 100.0 // Convertir % a decimal
 		comisionBruta := totalVentas * porcentaje
 
-		// Restar gasto publicitario
-		comisionNeta := comisionBruta - usuario.GastoPublicitario
+		// Restar lo invertido en el mes en las campañas a cargo del vendedor
+		gastoPublicitario, err := gastoPublicitarioMes(config.DB, usuario.ID, mes, anio)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al calcular gasto publicitario"})
+			return
+		}
+		comisionNeta := comisionBruta - gastoPublicitario
 		if comisionNeta < 0 {
 			comisionNeta = 0 // No puede ser negativa
 		}
 
+		// Sumar el bono si cumplió el objetivo del mes
+		var bono float64
+		if progreso[0].Cumplido {
+			bono = progreso[0].Bono
+		}
+		comisionNeta += bono
+
 		// Buscar si ya existe comisión para este mes
 		var comisionExistente models.Comision
 		result := config.DB.Where("usuario_id = ? AND mes = ? AND anio = ?", usuario.ID, mes, anio).First(&comisionExistente)
@@ -138,6 +151,9 @@ func CalcularComisionesMesActual(c *gin.Context) {
 				TotalVentas:   totalVentas,
 				TotalComision: comisionNeta,
 				Sueldo:        usuario.Sueldo,
+
+				GastoPublicitario: gastoPublicitario,
+				Bono:              bono,
 			}
 			config.DB.Create(&nuevaComision)
 		} else {
@@ -145,6 +161,8 @@ func CalcularComisionesMesActual(c *gin.Context) {
 			comisionExistente.TotalVentas = totalVentas
 			comisionExistente.TotalComision = comisionNeta
 			comisionExistente.Sueldo = usuario.Sueldo
+			comisionExistente.GastoPublicitario = gastoPublicitario
+			comisionExistente.Bono = bono
 			config.DB.Save(&comisionExistente)
 		}
 	}
@@ -0,0 +1,432 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+	"vartan-backend/config"
+	"vartan-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CrearConteo godoc
+// @Summary Abrir conteo de inventario
+// @Description Abre un conteo para todos los productos activos o para un subconjunto (productos, tipo o equipo). Solo puede haber un conteo abierto (solo dueño).
+// @Tags Inventario
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.ConteoCreateRequest true "Alcance del conteo"
+// @Success 201 {object} models.ConteoRevisionResponse
+// @Failure 400 {object} map[string]string "Datos inválidos o ya hay un conteo abierto"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/conteos [post]
+func CrearConteo(c *gin.Context) {
+	var req models.ConteoCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+
+	var abiertos int64
+	config.DB.Model(&models.ConteoInventario{}).Where("estado = ?", models.ConteoAbierto).Count(&abiertos)
+	if abiertos > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ya hay un conteo abierto"})
+		return
+	}
+
+	// Variantes a contar
+	query := config.DB.Model(&models.ProductoStock{}).
+		Joins("JOIN productos p ON p.id = producto_stocks.producto_id").
+		Where("p.activo = ?", true)
+	if len(req.ProductoIDs) > 0 {
+		query = query.Where("p.id IN ?", req.ProductoIDs)
+	}
+	if req.TipoProductoID != nil {
+		query = query.Where("p.tipo_producto_id = ?", *req.TipoProductoID)
+	}
+	if req.EquipoID != nil {
+		query = query.Where("p.equipo_id = ?", *req.EquipoID)
+	}
+
+	var stocks []models.ProductoStock
+	if err := query.Find(&stocks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener stock"})
+		return
+	}
+	if len(stocks) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No hay variantes para contar con esos filtros"})
+		return
+	}
+
+	conteo := models.ConteoInventario{
+		Estado:      models.ConteoAbierto,
+		Descripcion: req.Descripcion,
+		UsuarioID:   c.GetInt("user_id"),
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&conteo).Error; err != nil {
+			return err
+		}
+
+		items := make([]models.ConteoInventarioItem, 0, len(stocks))
+		for _, s := range stocks {
+			items = append(items, models.ConteoInventarioItem{
+				ConteoID:     conteo.ID,
+				StockID:      s.ID,
+				ProductoID:   s.ProductoID,
+				Talle:        string(s.Talle),
+				Color:        string(s.Color),
+				StockSistema: s.Cantidad,
+			})
+		}
+		return tx.CreateInBatches(&items, 200).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al crear conteo"})
+		return
+	}
+
+	responderRevisionConteo(c, http.StatusCreated, conteo.ID)
+}
+
+// GetConteos godoc
+// @Summary Listar conteos de inventario
+// @Description Lista los conteos de inventario, del más reciente al más antiguo (solo dueño)
+// @Tags Inventario
+// @Produce json
+// @Security BearerAuth
+// @Param estado query string false "abierto, confirmado o cancelado"
+// @Success 200 {array} models.ConteoInventario
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/conteos [get]
+func GetConteos(c *gin.Context) {
+	query := config.DB.Order("fecha_apertura DESC")
+	if estado := c.Query("estado"); estado != "" {
+		query = query.Where("estado = ?", estado)
+	}
+
+	conteos := []models.ConteoInventario{}
+	if err := query.Find(&conteos).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener conteos"})
+		return
+	}
+
+	c.JSON(http.StatusOK, conteos)
+}
+
+// GetConteoRevision godoc
+// @Summary Revisar diferencias del conteo
+// @Description Compara lo contado con el stock del sistema para cada variante (solo dueño)
+// @Tags Inventario
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID del conteo"
+// @Success 200 {object} models.ConteoRevisionResponse
+// @Failure 404 {object} map[string]string "Conteo no encontrado"
+// @Router /api/owner/conteos/{id} [get]
+func GetConteoRevision(c *gin.Context) {
+	var conteo models.ConteoInventario
+	if err := config.DB.First(&conteo, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Conteo no encontrado"})
+		return
+	}
+
+	responderRevisionConteo(c, http.StatusOK, conteo.ID)
+}
+
+// GetConteoItems godoc
+// @Summary Variantes a contar
+// @Description Devuelve las variantes del conteo con lo registrado por cada dispositivo, sin mostrar el stock del sistema para no condicionar el recuento
+// @Tags Inventario
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID del conteo"
+// @Success 200 {array} models.ConteoInventarioItem
+// @Failure 404 {object} map[string]string "Conteo no encontrado"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/conteos/{id}/items [get]
+func GetConteoItems(c *gin.Context) {
+	var conteo models.ConteoInventario
+	if err := config.DB.First(&conteo, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Conteo no encontrado"})
+		return
+	}
+
+	items := []models.ConteoInventarioItem{}
+	if err := config.DB.
+		Omit("stock_sistema", "ajuste").
+		Where("conteo_id = ?", conteo.ID).
+		Preload("Producto").
+		Preload("Registros").
+		Order("producto_id, talle, color").
+		Find(&items).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener variantes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+// RegistrarConteo godoc
+// @Summary Registrar cantidades contadas
+// @Description Registra lo contado desde un dispositivo. Si el dispositivo ya había contado una variante, se reemplaza su cantidad; lo de distintos dispositivos se suma.
+// @Tags Inventario
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID del conteo"
+// @Param request body models.ConteoRegistroRequest true "Cantidades contadas"
+// @Success 200 {object} map[string]interface{} "Cantidad de variantes registradas"
+// @Failure 400 {object} map[string]string "Datos inválidos o conteo cerrado"
+// @Failure 404 {object} map[string]string "Conteo no encontrado"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/conteos/{id}/registros [post]
+func RegistrarConteo(c *gin.Context) {
+	var req models.ConteoRegistroRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+
+	usuarioID := c.GetInt("user_id")
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// El bloqueo compartido evita registrar mientras se confirma
+		var conteo models.ConteoInventario
+		if err := tx.Clauses(clause.Locking{Strength: "SHARE"}).First(&conteo, c.Param("id")).Error; err != nil {
+			return &ventaError{http.StatusNotFound, "Conteo no encontrado"}
+		}
+		if conteo.Estado != models.ConteoAbierto {
+			return &ventaError{http.StatusBadRequest, "El conteo no está abierto"}
+		}
+
+		for _, r := range req.Items {
+			var item models.ConteoInventarioItem
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("conteo_id = ? AND stock_id = ?", conteo.ID, r.StockID).
+				First(&item).Error; err != nil {
+				return &ventaError{http.StatusBadRequest, "La variante no forma parte del conteo"}
+			}
+
+			registro := models.ConteoInventarioRegistro{
+				ItemID:      item.ID,
+				Dispositivo: req.Dispositivo,
+				Cantidad:    r.Cantidad,
+				UsuarioID:   usuarioID,
+				Fecha:       time.Now(),
+			}
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "item_id"}, {Name: "dispositivo"}},
+				DoUpdates: clause.AssignmentColumns([]string{"cantidad", "usuario_id", "fecha"}),
+			}).Create(&registro).Error; err != nil {
+				return err
+			}
+
+			// El stock del sistema queda fijo desde la apertura: lo de cada dispositivo se
+			// compara siempre contra la misma foto
+			var contado int
+			if err := tx.Model(&models.ConteoInventarioRegistro{}).
+				Select("COALESCE(SUM(cantidad), 0)").
+				Where("item_id = ?", item.ID).
+				Scan(&contado).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&item).Update("contado", contado).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		responderError(c, err, "Error al registrar conteo")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Conteo registrado exitosamente",
+		"registrados": len(req.Items),
+	})
+}
+
+// ConfirmarConteo godoc
+// @Summary Confirmar conteo y ajustar stock
+// @Description Aplica las diferencias entre lo contado y el stock del sistema al abrir el conteo sobre el stock actual, y registra un movimiento de ajuste por variante con el motivo indicado. Los movimientos hechos después de abrir el conteo se conservan (solo dueño).
+// @Tags Inventario
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID del conteo"
+// @Param request body models.ConteoConfirmarRequest true "Motivo de los ajustes"
+// @Success 200 {object} models.ConteoRevisionResponse
+// @Failure 400 {object} map[string]string "Datos inválidos o conteo cerrado"
+// @Failure 404 {object} map[string]string "Conteo no encontrado"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/conteos/{id}/confirmar [post]
+func ConfirmarConteo(c *gin.Context) {
+	var req models.ConteoConfirmarRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+
+	usuarioID := c.GetInt("user_id")
+	var conteoID int
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var conteo models.ConteoInventario
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&conteo, c.Param("id")).Error; err != nil {
+			return &ventaError{http.StatusNotFound, "Conteo no encontrado"}
+		}
+		if conteo.Estado != models.ConteoAbierto {
+			return &ventaError{http.StatusBadRequest, "El conteo no está abierto"}
+		}
+		conteoID = conteo.ID
+
+		var items []models.ConteoInventarioItem
+		if err := tx.Where("conteo_id = ?", conteo.ID).Find(&items).Error; err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			contado := item.Contado
+			if contado == nil {
+				if !req.AjustarNoContados {
+					continue
+				}
+				cero := 0
+				contado = &cero
+			}
+
+			ajuste := *contado - item.StockSistema
+			if err := tx.Model(&item).Update("ajuste", ajuste).Error; err != nil {
+				return err
+			}
+			if ajuste == 0 {
+				continue
+			}
+
+			if err := ajustarStock(tx, item.StockID, ajuste, models.MovimientoStockAjusteConteo, req.Motivo, &conteo.ID, usuarioID); err != nil {
+				return err
+			}
+		}
+
+		ahora := time.Now()
+		return tx.Model(&conteo).Updates(map[string]interface{}{
+			"estado":            models.ConteoConfirmado,
+			"fecha_cierre":      ahora,
+			"usuario_cierre_id": usuarioID,
+			"motivo":            req.Motivo,
+		}).Error
+	})
+	if err != nil {
+		responderError(c, err, "Error al confirmar conteo")
+		return
+	}
+
+	responderRevisionConteo(c, http.StatusOK, conteoID)
+}
+
+// CancelarConteo godoc
+// @Summary Cancelar conteo
+// @Description Cancela un conteo abierto sin tocar el stock (solo dueño)
+// @Tags Inventario
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID del conteo"
+// @Success 200 {object} map[string]string "Conteo cancelado exitosamente"
+// @Failure 400 {object} map[string]string "El conteo no está abierto"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/conteos/{id}/cancelar [post]
+func CancelarConteo(c *gin.Context) {
+	ahora := time.Now()
+	result := config.DB.Model(&models.ConteoInventario{}).
+		Where("id = ? AND estado = ?", c.Param("id"), models.ConteoAbierto).
+		Updates(map[string]interface{}{
+			"estado":            models.ConteoCancelado,
+			"fecha_cierre":      ahora,
+			"usuario_cierre_id": c.GetInt("user_id"),
+		})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al cancelar conteo"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "El conteo no está abierto"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Conteo cancelado exitosamente"})
+}
+
+// responderRevisionConteo responde con el conteo y la diferencia de cada variante
+func responderRevisionConteo(c *gin.Context, status int, conteoID int) {
+	var conteo models.ConteoInventario
+	if err := config.DB.First(&conteo, conteoID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Conteo no encontrado"})
+		return
+	}
+
+	items := []models.DiferenciaConteo{}
+	if err := config.DB.Table("conteos_inventario_items ci").
+		Select(`ci.id AS item_id, ci.stock_id, ci.producto_id, p.nombre, ci.talle, ci.color,
+			ci.stock_sistema, ci.contado, ci.contado - ci.stock_sistema AS diferencia,
+			COALESCE(ps.cantidad, 0) AS stock_actual, ci.ajuste`).
+		Joins("JOIN productos p ON p.id = ci.producto_id").
+		Joins("LEFT JOIN producto_stocks ps ON ps.id = ci.stock_id").
+		Where("ci.conteo_id = ?", conteoID).
+		Order("p.nombre, ci.talle, ci.color").
+		Scan(&items).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener diferencias"})
+		return
+	}
+
+	response := models.ConteoRevisionResponse{Conteo: conteo, Items: items, TotalItems: len(items)}
+	for _, item := range items {
+		if item.Contado != nil {
+			response.Contados++
+		}
+		if item.Diferencia != nil && *item.Diferencia != 0 {
+			response.ConDiferencias++
+		}
+	}
+
+	c.JSON(status, response)
+}
+
+// ajustarStock suma (o resta) cantidad al stock de la variante y deja registrado el movimiento
+func ajustarStock(tx *gorm.DB, stockID int, cantidad int, tipo string, motivo string, conteoID *int, usuarioID int) error {
+	var stock models.ProductoStock
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&stock, stockID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &ventaError{http.StatusBadRequest, "Stock no encontrado"}
+		}
+		return err
+	}
+
+	variante, err := asegurarVariante(tx, stock)
+	if err != nil {
+		return err
+	}
+
+	movimiento := models.MovimientoStock{
+		StockID:    stock.ID,
+		VarianteID: &variante.ID,
+		ProductoID: stock.ProductoID,
+		Talle:      string(stock.Talle),
+		Color:      string(stock.Color),
+		Tipo:       tipo,
+		Cantidad:   cantidad,
+		Anterior:   stock.Cantidad,
+		Motivo:     motivo,
+		ConteoID:   conteoID,
+		UsuarioID:  usuarioID,
+	}
+
+	if err := tx.Model(&stock).Update("cantidad", gorm.Expr("cantidad + ?", cantidad)).Error; err != nil {
+		return err
+	}
+	return tx.Create(&movimiento).Error
+}
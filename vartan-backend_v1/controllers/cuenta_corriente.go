@@ -0,0 +1,553 @@
+package controllers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+	"vartan-backend/config"
+	"vartan-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetEstadoCuentaCliente godoc
+// @Summary Estado de cuenta corriente
+// @Description Devuelve los movimientos de la cuenta corriente del cliente entre dos fechas con el saldo acumulado
+// @Tags Cuenta corriente
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID del cliente"
+// @Param fecha_desde query string false "Fecha desde (YYYY-MM-DD). Por defecto, el primer día del mes"
+// @Param fecha_hasta query string false "Fecha hasta (YYYY-MM-DD). Por defecto, hoy"
+// @Success 200 {object} models.EstadoCuentaResponse
+// @Failure 400 {object} map[string]string "Fechas inválidas"
+// @Failure 404 {object} map[string]string "Cliente no encontrado"
+// @Router /api/clientes/{id}/cuenta-corriente [get]
+func GetEstadoCuentaCliente(c *gin.Context) {
+	var cliente models.Cliente
+	if err := config.DB.Unscoped().First(&cliente, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cliente no encontrado"})
+		return
+	}
+
+	desde, hasta, err := rangoFechas(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// Incluir el día completo de fecha_hasta
+	finHasta := hasta.AddDate(0, 0, 1)
+
+	// Saldo anterior al período
+	var saldoInicial float64
+	if err := config.DB.Model(&models.MovimientoCuentaCorriente{}).
+		Where("cliente_id = ? AND fecha < ?", cliente.ID, desde).
+		Select("COALESCE(SUM(CASE WHEN tipo = ? THEN monto ELSE -monto END), 0)", models.MovimientoDebito).
+		Scan(&saldoInicial).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al calcular saldo inicial"})
+		return
+	}
+
+	var movimientos []models.MovimientoCuentaCorriente
+	if err := config.DB.
+		Preload("FormaPago").
+		Where("cliente_id = ? AND fecha >= ? AND fecha < ?", cliente.ID, desde, finHasta).
+		Order("fecha ASC, id ASC").
+		Find(&movimientos).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener movimientos"})
+		return
+	}
+
+	response := models.EstadoCuentaResponse{
+		Cliente:      cliente,
+		FechaDesde:   desde.Format("2006-01-02"),
+		FechaHasta:   hasta.Format("2006-01-02"),
+		SaldoInicial: saldoInicial,
+		Movimientos:  make([]models.MovimientoEstadoCuenta, 0, len(movimientos)),
+	}
+
+	saldo := saldoInicial
+	for _, m := range movimientos {
+		if m.Tipo == models.MovimientoDebito {
+			saldo += m.Monto
+			response.TotalDebitos += m.Monto
+		} else {
+			saldo -= m.Monto
+			response.TotalCreditos += m.Monto
+		}
+		response.Movimientos = append(response.Movimientos, models.MovimientoEstadoCuenta{
+			MovimientoCuentaCorriente: m,
+			Saldo:                     redondear(saldo),
+		})
+	}
+	response.SaldoFinal = redondear(saldo)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RegistrarPagoCliente godoc
+// @Summary Registrar pago de cliente
+// @Description Registra un pago en la cuenta corriente. Se imputa primero a la venta indicada y luego a las ventas pendientes más antiguas; el excedente queda como saldo a favor.
+// @Tags Cuenta corriente
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID del cliente"
+// @Param request body models.PagoClienteRequest true "Datos del pago"
+// @Success 201 {array} models.MovimientoCuentaCorriente
+// @Failure 400 {object} map[string]string "Datos inválidos"
+// @Failure 404 {object} map[string]string "Cliente no encontrado"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/clientes/{id}/pagos [post]
+func RegistrarPagoCliente(c *gin.Context) {
+	// Un cliente archivado puede seguir cancelando su deuda
+	var cliente models.Cliente
+	if err := config.DB.Unscoped().First(&cliente, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cliente no encontrado"})
+		return
+	}
+
+	var req models.PagoClienteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+
+	var formaPago models.FormaPago
+	if err := config.DB.First(&formaPago, req.FormaPagoID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Forma de pago no encontrada"})
+		return
+	}
+
+	tx := config.DB.Begin()
+	movimientos, err := imputarCredito(tx, cliente.ID, req.Monto, req.VentaID, models.ConceptoPago, &req.FormaPagoID, req.Descripcion, c.GetInt("user_id"))
+	if err != nil {
+		tx.Rollback()
+		responderError(c, err, "Error al registrar pago")
+		return
+	}
+
+	// Los pagos en efectivo entran en la caja abierta
+	if strings.EqualFold(formaPago.Nombre, models.FormaPagoEfectivo) {
+		movimiento := models.MovimientoCaja{
+			Tipo:      models.MovimientoCajaIngreso,
+			Concepto:  models.ConceptoCajaPago,
+			Monto:     req.Monto,
+			VentaID:   req.VentaID,
+			ClienteID: &cliente.ID,
+			UsuarioID: c.GetInt("user_id"),
+		}
+		if req.Descripcion != "" {
+			movimiento.Descripcion = &req.Descripcion
+		}
+		if _, err := registrarMovimientoCaja(tx, &movimiento); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al registrar movimiento de caja"})
+			return
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al registrar pago"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, movimientos)
+}
+
+// CrearNotaCredito godoc
+// @Summary Emitir nota de crédito
+// @Description Acredita un monto en la cuenta corriente del cliente (solo dueño). Se imputa igual que un pago.
+// @Tags Cuenta corriente
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID del cliente"
+// @Param request body models.NotaCreditoRequest true "Datos de la nota de crédito"
+// @Success 201 {array} models.MovimientoCuentaCorriente
+// @Failure 400 {object} map[string]string "Datos inválidos"
+// @Failure 404 {object} map[string]string "Cliente no encontrado"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/clientes/{id}/notas-credito [post]
+func CrearNotaCredito(c *gin.Context) {
+	var cliente models.Cliente
+	if err := config.DB.Unscoped().First(&cliente, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cliente no encontrado"})
+		return
+	}
+
+	var req models.NotaCreditoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+
+	tx := config.DB.Begin()
+	movimientos, err := imputarCredito(tx, cliente.ID, req.Monto, req.VentaID, models.ConceptoNotaCredito, nil, req.Descripcion, c.GetInt("user_id"))
+	if err != nil {
+		tx.Rollback()
+		responderError(c, err, "Error al registrar nota de crédito")
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al registrar nota de crédito"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, movimientos)
+}
+
+// UpdateLimiteCredito godoc
+// @Summary Actualizar límite de crédito
+// @Description Define la deuda máxima permitida en cuenta corriente para el cliente (solo dueño). Enviar null para quitar el límite.
+// @Tags Cuenta corriente
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID del cliente"
+// @Param request body models.LimiteCreditoRequest true "Límite de crédito"
+// @Success 200 {object} models.Cliente
+// @Failure 400 {object} map[string]string "Datos inválidos"
+// @Failure 404 {object} map[string]string "Cliente no encontrado"
+// @Router /api/owner/clientes/{id}/limite-credito [put]
+func UpdateLimiteCredito(c *gin.Context) {
+	var cliente models.Cliente
+	if err := config.DB.First(&cliente, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cliente no encontrado"})
+		return
+	}
+
+	var req models.LimiteCreditoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+
+	cliente.LimiteCredito = req.LimiteCredito
+	if err := config.DB.Model(&cliente).Update("limite_credito", req.LimiteCredito).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar límite de crédito"})
+		return
+	}
+
+	c.JSON(http.StatusOK, cliente)
+}
+
+// GetAntiguedadSaldos godoc
+// @Summary Antigüedad de saldos
+// @Description Deuda de cada cliente agrupada por antigüedad de las ventas: hasta 30, 31-60, 61-90 y más de 90 días (solo dueño)
+// @Tags Cuenta corriente
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/cuentas-corrientes/antiguedad [get]
+func GetAntiguedadSaldos(c *gin.Context) {
+	var pendientes []ventaPendiente
+	if err := consultaVentasPendientes(config.DB).Scan(&pendientes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al calcular saldos"})
+		return
+	}
+
+	ahora := time.Now()
+	porCliente := map[int]*models.AntiguedadSaldoCliente{}
+	var totales models.AntiguedadSaldoCliente
+	for _, p := range pendientes {
+		fila, ok := porCliente[p.ClienteID]
+		if !ok {
+			fila = &models.AntiguedadSaldoCliente{ClienteID: p.ClienteID}
+			porCliente[p.ClienteID] = fila
+		}
+
+		dias := int(ahora.Sub(p.FechaVenta).Hours() / 24)
+		switch {
+		case dias <= 30:
+			fila.Hasta30 += p.Pendiente
+			totales.Hasta30 += p.Pendiente
+		case dias <= 60:
+			fila.De31a60 += p.Pendiente
+			totales.De31a60 += p.Pendiente
+		case dias <= 90:
+			fila.De61a90 += p.Pendiente
+			totales.De61a90 += p.Pendiente
+		default:
+			fila.MasDe90 += p.Pendiente
+			totales.MasDe90 += p.Pendiente
+		}
+		fila.Total += p.Pendiente
+		totales.Total += p.Pendiente
+	}
+
+	ids := make([]int, 0, len(porCliente))
+	for id := range porCliente {
+		ids = append(ids, id)
+	}
+	var clientes []models.Cliente
+	if len(ids) > 0 {
+		// Los archivados con deuda también se listan
+		if err := config.DB.Unscoped().Where("id IN ?", ids).Find(&clientes).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener clientes"})
+			return
+		}
+	}
+
+	resultado := make([]models.AntiguedadSaldoCliente, 0, len(clientes))
+	for _, cliente := range clientes {
+		fila := porCliente[cliente.ID]
+		fila.Nombre = cliente.Nombre
+		fila.Telefono = cliente.Telefono
+		fila.LimiteCredito = cliente.LimiteCredito
+		resultado = append(resultado, *fila)
+	}
+
+	// Primero los que más deben
+	sort.Slice(resultado, func(i, j int) bool {
+		return resultado[i].Total > resultado[j].Total
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"fecha_corte": ahora.Format("2006-01-02"),
+		"clientes":    resultado,
+		"totales": gin.H{
+			"hasta_30":   redondear(totales.Hasta30),
+			"de_31_a_60": redondear(totales.De31a60),
+			"de_61_a_90": redondear(totales.De61a90),
+			"mas_de_90":  redondear(totales.MasDe90),
+			"total":      redondear(totales.Total),
+		},
+	})
+}
+
+// ventaPendiente - Venta con deuda pendiente según la cuenta corriente
+type ventaPendiente struct {
+	VentaID    int
+	ClienteID  int
+	FechaVenta time.Time
+	Pendiente  float64
+}
+
+// consultaVentasPendientes arma la consulta de ventas con saldo deudor, de la más antigua a la más nueva
+func consultaVentasPendientes(db *gorm.DB) *gorm.DB {
+	return db.Table("cuenta_corriente_movimientos AS m").
+		Select("v.id AS venta_id, m.cliente_id, v.fecha_venta, SUM(CASE WHEN m.tipo = ? THEN m.monto ELSE -m.monto END) AS pendiente", models.MovimientoDebito).
+		Joins("JOIN venta v ON v.id = m.venta_id").
+		Group("v.id, m.cliente_id, v.fecha_venta").
+		Having("SUM(CASE WHEN m.tipo = ? THEN m.monto ELSE -m.monto END) > 0.005", models.MovimientoDebito).
+		Order("v.fecha_venta ASC, v.id ASC")
+}
+
+// saldoCuentaCorriente devuelve la deuda actual del cliente (negativo = saldo a favor)
+func saldoCuentaCorriente(db *gorm.DB, clienteID int) (float64, error) {
+	var saldo float64
+	err := db.Model(&models.MovimientoCuentaCorriente{}).
+		Where("cliente_id = ?", clienteID).
+		Select("COALESCE(SUM(CASE WHEN tipo = ? THEN monto ELSE -monto END), 0)", models.MovimientoDebito).
+		Scan(&saldo).Error
+	return saldo, err
+}
+
+// registrarVentaEnCuentaCorriente asienta la venta como débito y la seña y lo pagado con saldo a favor como créditos
+func registrarVentaEnCuentaCorriente(tx *gorm.DB, venta models.Venta, usuarioID int) error {
+	movimientos := []models.MovimientoCuentaCorriente{{
+		ClienteID: venta.ClienteID,
+		Tipo:      models.MovimientoDebito,
+		Concepto:  models.ConceptoVenta,
+		Monto:     venta.TotalFinal,
+		VentaID:   &venta.ID,
+		UsuarioID: usuarioID,
+		Fecha:     time.Now(),
+	}}
+
+	if venta.Sena > 0 {
+		movimientos = append(movimientos, models.MovimientoCuentaCorriente{
+			ClienteID:   venta.ClienteID,
+			Tipo:        models.MovimientoCredito,
+			Concepto:    models.ConceptoSena,
+			Monto:       venta.Sena,
+			VentaID:     &venta.ID,
+			FormaPagoID: &venta.FormaPagoID,
+			UsuarioID:   usuarioID,
+			Fecha:       time.Now(),
+		})
+	}
+
+	if venta.PagoSaldoAFavor > 0 {
+		movimientos = append(movimientos, models.MovimientoCuentaCorriente{
+			ClienteID: venta.ClienteID,
+			Tipo:      models.MovimientoCredito,
+			Concepto:  models.ConceptoSaldoAFavor,
+			Monto:     venta.PagoSaldoAFavor,
+			VentaID:   &venta.ID,
+			UsuarioID: usuarioID,
+			Fecha:     time.Now(),
+		})
+	}
+
+	return tx.Create(&movimientos).Error
+}
+
+// sincronizarCuentaCorrienteVenta actualiza el débito y la seña de una venta modificada
+func sincronizarCuentaCorrienteVenta(tx *gorm.DB, venta models.Venta, usuarioID int) error {
+	// Si cambió el cliente, la deuda pasa al nuevo cliente
+	if err := tx.Model(&models.MovimientoCuentaCorriente{}).
+		Where("venta_id = ?", venta.ID).
+		Update("cliente_id", venta.ClienteID).Error; err != nil {
+		return err
+	}
+
+	var movimientos []models.MovimientoCuentaCorriente
+	if err := tx.Where("venta_id = ? AND concepto IN ?", venta.ID, []string{models.ConceptoVenta, models.ConceptoSena}).
+		Find(&movimientos).Error; err != nil {
+		return err
+	}
+
+	// Ventas anteriores a la cuenta corriente: se asientan por primera vez
+	if len(movimientos) == 0 {
+		return registrarVentaEnCuentaCorriente(tx, venta, usuarioID)
+	}
+
+	tieneSena := false
+	for _, m := range movimientos {
+		switch m.Concepto {
+		case models.ConceptoVenta:
+			if err := tx.Model(&m).Update("monto", venta.TotalFinal).Error; err != nil {
+				return err
+			}
+		case models.ConceptoSena:
+			tieneSena = true
+			if venta.Sena <= 0 {
+				if err := tx.Delete(&m).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			if err := tx.Model(&m).Updates(map[string]interface{}{"monto": venta.Sena, "forma_pago_id": venta.FormaPagoID}).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	if !tieneSena && venta.Sena > 0 {
+		return tx.Create(&models.MovimientoCuentaCorriente{
+			ClienteID:   venta.ClienteID,
+			Tipo:        models.MovimientoCredito,
+			Concepto:    models.ConceptoSena,
+			Monto:       venta.Sena,
+			VentaID:     &venta.ID,
+			FormaPagoID: &venta.FormaPagoID,
+			UsuarioID:   usuarioID,
+			Fecha:       time.Now(),
+		}).Error
+	}
+
+	return nil
+}
+
+// anularVentaEnCuentaCorriente quita el débito, la seña y lo pagado con saldo a favor (que se reintegra
+// a la gift card) de una venta eliminada. Los pagos imputados a esa venta quedan como saldo a favor del cliente.
+func anularVentaEnCuentaCorriente(tx *gorm.DB, ventaID int) error {
+	if err := tx.Where("venta_id = ? AND concepto IN ?", ventaID, []string{models.ConceptoVenta, models.ConceptoSena, models.ConceptoSaldoAFavor}).
+		Delete(&models.MovimientoCuentaCorriente{}).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.MovimientoCuentaCorriente{}).
+		Where("venta_id = ?", ventaID).
+		Update("venta_id", nil).Error
+}
+
+// creditosImputados suma los pagos y notas de crédito imputados a una venta, que bajaron su saldo
+func creditosImputados(db *gorm.DB, ventaID int) (float64, error) {
+	var total float64
+	err := db.Model(&models.MovimientoCuentaCorriente{}).
+		Select("COALESCE(SUM(monto), 0)").
+		Where("venta_id = ? AND concepto IN ?", ventaID, []string{models.ConceptoPago, models.ConceptoNotaCredito}).
+		Scan(&total).Error
+	return total, err
+}
+
+// imputarCredito registra un crédito (pago o nota de crédito) repartiéndolo entre las ventas pendientes.
+// Si se indica ventaID se cancela esa venta primero. Cada imputación baja también Venta.Saldo.
+func imputarCredito(tx *gorm.DB, clienteID int, monto float64, ventaID *int, concepto string, formaPagoID *int, descripcion string, usuarioID int) ([]models.MovimientoCuentaCorriente, error) {
+	var pendientes []ventaPendiente
+	if err := consultaVentasPendientes(tx).Where("m.cliente_id = ?", clienteID).Scan(&pendientes).Error; err != nil {
+		return nil, err
+	}
+
+	if ventaID != nil {
+		indice := -1
+		for i, p := range pendientes {
+			if p.VentaID == *ventaID {
+				indice = i
+				break
+			}
+		}
+		if indice < 0 {
+			return nil, &ventaError{http.StatusBadRequest, fmt.Sprintf("La venta %d no tiene saldo pendiente para este cliente", *ventaID)}
+		}
+		// Mover la venta indicada al principio
+		elegida := pendientes[indice]
+		pendientes = append([]ventaPendiente{elegida}, append(pendientes[:indice], pendientes[indice+1:]...)...)
+	}
+
+	var desc *string
+	if descripcion != "" {
+		desc = &descripcion
+	}
+
+	restante := redondear(monto)
+	var movimientos []models.MovimientoCuentaCorriente
+	for _, p := range pendientes {
+		if restante <= 0 {
+			break
+		}
+		aplicado := math.Min(restante, redondear(p.Pendiente))
+		venta := p.VentaID
+		movimientos = append(movimientos, models.MovimientoCuentaCorriente{
+			ClienteID:   clienteID,
+			Tipo:        models.MovimientoCredito,
+			Concepto:    concepto,
+			Monto:       aplicado,
+			VentaID:     &venta,
+			FormaPagoID: formaPagoID,
+			Descripcion: desc,
+			UsuarioID:   usuarioID,
+			Fecha:       time.Now(),
+		})
+
+		if err := tx.Model(&models.Venta{}).
+			Where("id = ?", venta).
+			Update("saldo", gorm.Expr("GREATEST(saldo - ?, 0)", aplicado)).Error; err != nil {
+			return nil, err
+		}
+		restante = redondear(restante - aplicado)
+	}
+
+	// Excedente: saldo a favor del cliente
+	if restante > 0 {
+		movimientos = append(movimientos, models.MovimientoCuentaCorriente{
+			ClienteID:   clienteID,
+			Tipo:        models.MovimientoCredito,
+			Concepto:    concepto,
+			Monto:       restante,
+			FormaPagoID: formaPagoID,
+			Descripcion: desc,
+			UsuarioID:   usuarioID,
+			Fecha:       time.Now(),
+		})
+	}
+
+	if err := tx.Create(&movimientos).Error; err != nil {
+		return nil, err
+	}
+
+	return movimientos, nil
+}
+
+// redondear redondea un importe a centavos
+func redondear(valor float64) float64 {
+	return math.Round(valor*100) / 100
+}
@@ -0,0 +1,176 @@
+package controllers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+	"vartan-backend/config"
+	"vartan-backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetDashboard godoc
+// @Summary Tablero de ventas
+// @Description Indicadores de ventas del período (por defecto el mes en curso) comparados con el período anterior de igual duración (solo dueño)
+// @Tags Dashboard
+// @Produce json
+// @Security BearerAuth
+// @Param fecha_desde query string false "Desde (YYYY-MM-DD), por defecto el primer día del mes"
+// @Param fecha_hasta query string false "Hasta inclusive (YYYY-MM-DD), por defecto hoy"
+// @Success 200 {object} models.DashboardResponse
+// @Failure 400 {object} map[string]string "Fechas inválidas"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/dashboard [get]
+func GetDashboard(c *gin.Context) {
+	desde, hasta, err := rangoFechas(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	finHasta := hasta.AddDate(0, 0, 1)
+
+	// El período anterior tiene la misma cantidad de días y termina donde empieza el actual
+	dias := int(finHasta.Sub(desde).Hours()/24 + 0.5)
+	desdeAnterior := desde.AddDate(0, 0, -dias)
+
+	var response models.DashboardResponse
+	if response.Periodo, err = totalesPeriodo(desde, finHasta); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al calcular totales"})
+		return
+	}
+	if response.PeriodoAnterior, err = totalesPeriodo(desdeAnterior, desde); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al calcular totales del período anterior"})
+		return
+	}
+	response.Variacion = models.DashboardVariacion{
+		Ingresos:       variacionPorcentual(response.Periodo.Ingresos, response.PeriodoAnterior.Ingresos),
+		CantidadVentas: variacionPorcentual(float64(response.Periodo.CantidadVentas), float64(response.PeriodoAnterior.CantidadVentas)),
+		TicketPromedio: variacionPorcentual(response.Periodo.TicketPromedio, response.PeriodoAnterior.TicketPromedio),
+	}
+
+	// Agrupamientos sobre la cabecera de la venta
+	porVenta := func(join, columnas string) ([]models.DashboardGrupo, error) {
+		grupos := []models.DashboardGrupo{}
+		err := config.DB.Table("venta v").
+			Select(columnas+", COUNT(*) AS cantidad_ventas, COALESCE(SUM(v.total_final), 0) AS total").
+			Joins(join).
+			Where("v.fecha_venta >= ? AND v.fecha_venta < ?", desde, finHasta).
+			Group("1, 2").
+			Order("total DESC").
+			Scan(&grupos).Error
+		return grupos, err
+	}
+	// Agrupamientos sobre los renglones vendidos
+	porProducto := func(join, columnas string) ([]models.DashboardGrupo, error) {
+		grupos := []models.DashboardGrupo{}
+		err := config.DB.Table("venta_detalles vd").
+			Select(columnas+", COUNT(DISTINCT v.id) AS cantidad_ventas, COALESCE(SUM(vd.cantidad), 0) AS unidades, COALESCE(SUM(vd.subtotal), 0) AS total").
+			Joins("JOIN venta v ON v.id = vd.venta_id").
+			Joins("JOIN productos p ON p.id = vd.producto_id").
+			Joins(join).
+			Where("v.fecha_venta >= ? AND v.fecha_venta < ?", desde, finHasta).
+			Group("1, 2").
+			Order("total DESC").
+			Scan(&grupos).Error
+		return grupos, err
+	}
+
+	if response.PorFormaPago, err = porVenta("JOIN forma_pagos fp ON fp.id = v.forma_pago_id", "fp.id, fp.nombre"); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al agrupar por forma de pago"})
+		return
+	}
+	if response.PorVendedor, err = porVenta("JOIN usuarios u ON u.id = v.usuario_id", "u.id, u.nombre"); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al agrupar por vendedor"})
+		return
+	}
+	if response.PorEquipo, err = porProducto("LEFT JOIN equipos e ON e.id = p.equipo_id", "COALESCE(e.id, 0) AS id, COALESCE(e.nombre, 'Sin equipo') AS nombre"); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al agrupar por equipo"})
+		return
+	}
+	if response.PorTipoProducto, err = porProducto("LEFT JOIN tipo_productos tp ON tp.id = p.tipo_producto_id", "COALESCE(tp.id, 0) AS id, COALESCE(tp.nombre, 'Sin tipo') AS nombre"); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al agrupar por tipo de producto"})
+		return
+	}
+
+	for _, grupos := range [][]models.DashboardGrupo{response.PorFormaPago, response.PorVendedor, response.PorEquipo, response.PorTipoProducto} {
+		calcularParticipacion(grupos)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// totalesPeriodo calcula los totales de las ventas entre desde (inclusive) y hasta (exclusive)
+func totalesPeriodo(desde, hasta time.Time) (models.DashboardPeriodo, error) {
+	periodo := models.DashboardPeriodo{
+		FechaDesde: desde.Format("2006-01-02"),
+		FechaHasta: hasta.AddDate(0, 0, -1).Format("2006-01-02"),
+	}
+
+	err := config.DB.Model(&models.Venta{}).
+		Select(`COALESCE(SUM(total_final), 0) AS ingresos,
+			COUNT(*) AS cantidad_ventas,
+			COALESCE(SUM(descuento), 0) AS descuentos,
+			COALESCE(SUM(sena), 0) AS total_senas,
+			COALESCE(SUM(saldo), 0) AS saldo_pendiente`).
+		Where("fecha_venta >= ? AND fecha_venta < ?", desde, hasta).
+		Scan(&periodo).Error
+	if err != nil {
+		return periodo, err
+	}
+
+	if periodo.CantidadVentas > 0 {
+		periodo.TicketPromedio = redondear(periodo.Ingresos / float64(periodo.CantidadVentas))
+	}
+	return periodo, nil
+}
+
+// variacionPorcentual devuelve cuánto cambió actual respecto de anterior, en porcentaje.
+// Se divide por el valor absoluto para que pasar de -100 a -50 sea una mejora.
+func variacionPorcentual(actual, anterior float64) *float64 {
+	if anterior == 0 {
+		return nil
+	}
+	variacion := redondear((actual - anterior) / math.Abs(anterior) * 100)
+	return &variacion
+}
+
+// calcularParticipacion completa el porcentaje de cada grupo sobre el total
+func calcularParticipacion(grupos []models.DashboardGrupo) {
+	var total float64
+	for _, g := range grupos {
+		total += g.Total
+	}
+	if total == 0 {
+		return
+	}
+	for i := range grupos {
+		grupos[i].Porcentaje = redondear(grupos[i].Total / total * 100)
+	}
+}
+
+// rangoFechas lee fecha_desde y fecha_hasta (YYYY-MM-DD). Por defecto va del primer día del mes a hoy.
+// fecha_hasta se devuelve al inicio del día: para incluirlo completo hay que filtrar hasta el día siguiente.
+func rangoFechas(c *gin.Context) (time.Time, time.Time, error) {
+	hoy := time.Now()
+	desde := time.Date(hoy.Year(), hoy.Month(), 1, 0, 0, 0, 0, time.Local)
+	hasta := time.Date(hoy.Year(), hoy.Month(), hoy.Day(), 0, 0, 0, 0, time.Local)
+
+	var err error
+	if fechaDesde := c.Query("fecha_desde"); fechaDesde != "" {
+		if desde, err = time.ParseInLocation("2006-01-02", fechaDesde, time.Local); err != nil {
+			return desde, hasta, fmt.Errorf("Formato de fecha_desde inválido. Use YYYY-MM-DD")
+		}
+	}
+	if fechaHasta := c.Query("fecha_hasta"); fechaHasta != "" {
+		if hasta, err = time.ParseInLocation("2006-01-02", fechaHasta, time.Local); err != nil {
+			return desde, hasta, fmt.Errorf("Formato de fecha_hasta inválido. Use YYYY-MM-DD")
+		}
+	}
+	if hasta.Before(desde) {
+		return desde, hasta, fmt.Errorf("fecha_hasta no puede ser anterior a fecha_desde")
+	}
+
+	return desde, hasta, nil
+}
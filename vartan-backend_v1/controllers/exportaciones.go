@@ -0,0 +1,322 @@
+package controllers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+	"vartan-backend/config"
+	"vartan-backend/models"
+	"vartan-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// iniciarExportacion prepara la respuesta según el formato pedido (csv por defecto o xlsx)
+// y devuelve la planilla sobre la que se escriben las filas
+func iniciarExportacion(c *gin.Context, nombre string, encabezados []string) (utils.Planilla, bool) {
+	formato := c.DefaultQuery("formato", "csv")
+	if formato != "csv" && formato != "xlsx" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parámetro inválido: formato (csv o xlsx)"})
+		return nil, false
+	}
+
+	archivo := fmt.Sprintf("%s_%s.%s", nombre, time.Now().Format("20060102"), formato)
+	c.Header("Content-Disposition", `attachment; filename="`+archivo+`"`)
+
+	var planilla utils.Planilla
+	var err error
+	if formato == "xlsx" {
+		c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		c.Status(http.StatusOK)
+		planilla, err = utils.NuevaPlanillaXLSX(c.Writer, nombre, encabezados)
+	} else {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Status(http.StatusOK)
+		planilla, err = utils.NuevaPlanillaCSV(c.Writer, encabezados)
+	}
+	if err != nil {
+		log.Printf("Error al iniciar exportación de %s: %v", nombre, err)
+		return nil, false
+	}
+	return planilla, true
+}
+
+// volcarFilas recorre el resultado de la consulta fila por fila y lo escribe en la planilla.
+// Como la respuesta ya empezó a enviarse, los errores solo se registran en el log.
+func volcarFilas(c *gin.Context, nombre string, filas *sql.Rows, planilla utils.Planilla, escribir func(*sql.Rows) error) {
+	defer filas.Close()
+	for filas.Next() {
+		if err := escribir(filas); err != nil {
+			log.Printf("Error al exportar %s: %v", nombre, err)
+			return
+		}
+	}
+	if err := filas.Err(); err != nil {
+		log.Printf("Error al exportar %s: %v", nombre, err)
+		return
+	}
+	if err := planilla.Cerrar(); err != nil {
+		log.Printf("Error al exportar %s: %v", nombre, err)
+	}
+	c.Writer.Flush()
+}
+
+// ExportarVentas godoc
+// @Summary Exportar ventas
+// @Description Descarga las ventas con sus renglones (una fila por producto vendido) en CSV o XLSX. Acepta los mismos filtros que el listado de ventas (solo dueño).
+// @Tags Exportaciones
+// @Produce text/csv
+// @Security BearerAuth
+// @Param formato query string false "csv (por defecto) o xlsx"
+// @Param usuario_id query int false "Filtrar por vendedor"
+// @Param fecha_desde query string false "Desde (YYYY-MM-DD)"
+// @Param fecha_hasta query string false "Hasta inclusive (YYYY-MM-DD)"
+// @Param cliente_id query int false "Filtrar por cliente"
+// @Param forma_pago_id query int false "Filtrar por forma de pago"
+// @Param con_saldo query bool false "Solo ventas con saldo pendiente"
+// @Param producto_id query int false "Ventas que incluyen el producto"
+// @Param equipo_id query int false "Ventas que incluyen productos del equipo"
+// @Param canal_venta query string false "Canal de venta"
+// @Param campana_id query int false "Campaña de origen"
+// @Param total_min query number false "Total final mínimo"
+// @Param total_max query number false "Total final máximo"
+// @Success 200 {file} file "Planilla de ventas"
+// @Failure 400 {object} map[string]string "Parámetro inválido"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/exportar/ventas [get]
+func ExportarVentas(c *gin.Context) {
+	base := config.DB
+	if usuarioID := c.Query("usuario_id"); usuarioID != "" {
+		id, err := strconv.Atoi(usuarioID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parámetro inválido: usuario_id"})
+			return
+		}
+		base = base.Where("usuario_id = ?", id)
+	}
+	ventas, err := filtrarVentas(c, base.Model(&models.Venta{}))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filas, err := config.DB.Table("venta_detalles vd").
+		Select(`v.id, v.fecha_venta, cl.nombre, u.nombre, fp.nombre, p.nombre, vd.talle, COALESCE(vd.color, ''),
+			vd.cantidad, vd.precio_unitario, vd.subtotal, v.total, v.descuento, v.total_final, v.sena, v.saldo`).
+		Joins("JOIN venta v ON v.id = vd.venta_id").
+		Joins("JOIN clientes cl ON cl.id = v.cliente_id").
+		Joins("JOIN usuarios u ON u.id = v.usuario_id").
+		Joins("JOIN forma_pagos fp ON fp.id = v.forma_pago_id").
+		Joins("JOIN productos p ON p.id = vd.producto_id").
+		Where("vd.venta_id IN (?)", ventas.Select("id")).
+		Order("v.fecha_venta, v.id, vd.id").
+		Rows()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al exportar ventas"})
+		return
+	}
+
+	planilla, ok := iniciarExportacion(c, "ventas", []string{
+		"N° venta", "Fecha", "Cliente", "Vendedor", "Forma de pago", "Producto", "Talle", "Color",
+		"Cantidad", "Precio unitario", "Subtotal", "Total venta", "Descuento", "Total final", "Seña", "Saldo",
+	})
+	if !ok {
+		filas.Close()
+		return
+	}
+
+	volcarFilas(c, "ventas", filas, planilla, func(f *sql.Rows) error {
+		var (
+			id, cantidad                                                int
+			fecha                                                       time.Time
+			cliente, vendedor, formaPago, producto, talle, color        string
+			precio, subtotal, total, descuento, totalFinal, sena, saldo float64
+		)
+		if err := f.Scan(&id, &fecha, &cliente, &vendedor, &formaPago, &producto, &talle, &color,
+			&cantidad, &precio, &subtotal, &total, &descuento, &totalFinal, &sena, &saldo); err != nil {
+			return err
+		}
+		return planilla.Fila(id, fecha, cliente, vendedor, formaPago, producto, talle, color,
+			cantidad, precio, subtotal, total, descuento, totalFinal, sena, saldo)
+	})
+}
+
+// ExportarStock godoc
+// @Summary Exportar stock
+// @Description Descarga el stock por variante con su valorización al costo en CSV o XLSX (solo dueño)
+// @Tags Exportaciones
+// @Produce text/csv
+// @Security BearerAuth
+// @Param formato query string false "csv (por defecto) o xlsx"
+// @Param producto_id query int false "Filtrar por producto"
+// @Param equipo_id query int false "Filtrar por equipo"
+// @Param tipo_producto_id query int false "Filtrar por tipo de producto"
+// @Param con_stock query bool false "Solo variantes con stock"
+// @Success 200 {file} file "Planilla de stock"
+// @Failure 400 {object} map[string]string "Parámetro inválido"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/exportar/stock [get]
+func ExportarStock(c *gin.Context) {
+	query := config.DB.Table("producto_stocks s").
+		Select(`p.nombre, COALESCE(tp.nombre, ''), COALESCE(e.nombre, ''), s.talle, s.color,
+			COALESCE(v.sku, ''), COALESCE(v.codigo_barras, ''), s.cantidad, p.costo_unitario, p.precio_venta`).
+		Joins("JOIN productos p ON p.id = s.producto_id").
+		Joins("LEFT JOIN tipo_productos tp ON tp.id = p.tipo_producto_id").
+		Joins("LEFT JOIN equipos e ON e.id = p.equipo_id").
+		Joins("LEFT JOIN variantes_producto v ON v.stock_id = s.id").
+		Joins("LEFT JOIN talles t ON t.nombre = s.talle").
+		Where("p.activo = ?", true)
+
+	filtros := []struct{ param, columna string }{
+		{"producto_id", "p.id"},
+		{"equipo_id", "p.equipo_id"},
+		{"tipo_producto_id", "p.tipo_producto_id"},
+	}
+	for _, f := range filtros {
+		if valor := c.Query(f.param); valor != "" {
+			id, err := strconv.Atoi(valor)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Parámetro inválido: " + f.param})
+				return
+			}
+			query = query.Where(f.columna+" = ?", id)
+		}
+	}
+	if c.Query("con_stock") == "true" {
+		query = query.Where("s.cantidad > 0")
+	}
+
+	filas, err := query.Order("p.nombre, p.id, t.orden, s.talle, s.color").Rows()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al exportar stock"})
+		return
+	}
+
+	planilla, ok := iniciarExportacion(c, "stock", []string{
+		"Producto", "Tipo", "Equipo", "Talle", "Color", "SKU", "Código de barras",
+		"Cantidad", "Costo unitario", "Precio de venta", "Valor al costo",
+	})
+	if !ok {
+		filas.Close()
+		return
+	}
+
+	volcarFilas(c, "stock", filas, planilla, func(f *sql.Rows) error {
+		var (
+			producto, tipo, equipo, talle, color, sku, codigo string
+			cantidad                                          int
+			costo, precio                                     float64
+		)
+		if err := f.Scan(&producto, &tipo, &equipo, &talle, &color, &sku, &codigo, &cantidad, &costo, &precio); err != nil {
+			return err
+		}
+		return planilla.Fila(producto, tipo, equipo, talle, color, sku, codigo,
+			cantidad, costo, precio, redondear(costo*float64(cantidad)))
+	})
+}
+
+// ExportarGastos godoc
+// @Summary Exportar gastos
+// @Description Descarga los gastos en CSV o XLSX con los mismos filtros que el listado de gastos (solo dueño)
+// @Tags Exportaciones
+// @Produce text/csv
+// @Security BearerAuth
+// @Param formato query string false "csv (por defecto) o xlsx"
+// @Param categoria query string false "Filtrar por categoría"
+// @Param fecha_desde query string false "Desde (YYYY-MM-DD)"
+// @Param fecha_hasta query string false "Hasta (YYYY-MM-DD)"
+// @Param proveedor query string false "Buscar por proveedor"
+// @Success 200 {file} file "Planilla de gastos"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/exportar/gastos [get]
+func ExportarGastos(c *gin.Context) {
+	filas, err := consultaGastos(c).
+		Select("fecha, descripcion, categoria, proveedor, metodo_pago, comprobante, monto, notas").
+		Order("fecha, id").
+		Rows()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al exportar gastos"})
+		return
+	}
+
+	planilla, ok := iniciarExportacion(c, "gastos", []string{
+		"Fecha", "Descripción", "Categoría", "Proveedor", "Método de pago", "Comprobante", "Monto", "Notas",
+	})
+	if !ok {
+		filas.Close()
+		return
+	}
+
+	volcarFilas(c, "gastos", filas, planilla, func(f *sql.Rows) error {
+		var (
+			fecha                                     time.Time
+			descripcion, categoria                    string
+			proveedor, metodoPago, comprobante, notas sql.NullString
+			monto                                     float64
+		)
+		if err := f.Scan(&fecha, &descripcion, &categoria, &proveedor, &metodoPago, &comprobante, &monto, &notas); err != nil {
+			return err
+		}
+		return planilla.Fila(fecha, descripcion, categoria, proveedor.String, metodoPago.String, comprobante.String, monto, notas.String)
+	})
+}
+
+// ExportarComisiones godoc
+// @Summary Exportar comisiones
+// @Description Descarga las comisiones liquidadas en CSV o XLSX (solo dueño)
+// @Tags Exportaciones
+// @Produce text/csv
+// @Security BearerAuth
+// @Param formato query string false "csv (por defecto) o xlsx"
+// @Param usuario_id query int false "Filtrar por vendedor"
+// @Param anio query int false "Filtrar por año"
+// @Param mes query int false "Filtrar por mes (1-12)"
+// @Success 200 {file} file "Planilla de comisiones"
+// @Failure 400 {object} map[string]string "Parámetro inválido"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/exportar/comisiones [get]
+func ExportarComisiones(c *gin.Context) {
+	query := config.DB.Table("comisions co").
+		Select("co.anio, co.mes, u.nombre, co.total_ventas, co.gasto_publicitario, co.bono, co.total_comision, co.sueldo, COALESCE(co.observaciones, '')").
+		Joins("JOIN usuarios u ON u.id = co.usuario_id")
+
+	for _, campo := range []string{"usuario_id", "anio", "mes"} {
+		if valor := c.Query(campo); valor != "" {
+			n, err := strconv.Atoi(valor)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Parámetro inválido: " + campo})
+				return
+			}
+			query = query.Where("co."+campo+" = ?", n)
+		}
+	}
+
+	filas, err := query.Order("co.anio, co.mes, u.nombre").Rows()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al exportar comisiones"})
+		return
+	}
+
+	planilla, ok := iniciarExportacion(c, "comisiones", []string{
+		"Año", "Mes", "Vendedor", "Total ventas", "Gasto publicitario", "Bono", "Comisión", "Sueldo", "Observaciones",
+	})
+	if !ok {
+		filas.Close()
+		return
+	}
+
+	volcarFilas(c, "comisiones", filas, planilla, func(f *sql.Rows) error {
+		var (
+			anio, mes                             int
+			vendedor, observaciones               string
+			ventas, gasto, bono, comision, sueldo float64
+		)
+		if err := f.Scan(&anio, &mes, &vendedor, &ventas, &gasto, &bono, &comision, &sueldo, &observaciones); err != nil {
+			return err
+		}
+		return planilla.Fila(anio, mes, vendedor, ventas, gasto, bono, comision, sueldo, observaciones)
+	})
+}
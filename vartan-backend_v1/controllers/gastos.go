@@ -52,7 +52,13 @@ func CrearGasto(c *gin.Context) {
 		UsuarioID:   usuarioID.(uint),
 	}
 
-	if err := config.DB.Create(&gasto).Error; err != nil {
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&gasto).Error; err != nil {
+			return err
+		}
+		return sincronizarCajaGasto(tx, gasto, c.GetInt("user_id"))
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al crear gasto"})
 		return
 	}
@@ -65,42 +71,17 @@ func CrearGasto(c *gin.Context) {
 
 // ListarGastos lista todos los gastos con filtros opcionales
 func ListarGastos(c *gin.Context) {
-	clienteID, _ := c.Get("cliente_id")
-
-	// Parámetros de consulta opcionales
-	categoria := c.Query("categoria")
-	fechaDesde := c.Query("fecha_desde") // YYYY-MM-DD
-	fechaHasta := c.Query("fecha_hasta") // YYYY-MM-DD
-	proveedor := c.Query("proveedor")
-
 	// Paginación
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
 	offset := (page - 1) * limit
 
 	// Construir query
-	query := config.DB.Where("cliente_id = ?", clienteID)
-
-	// Aplicar filtros
-	if categoria != "" {
-		query = query.Where("categoria = ?", categoria)
-	}
-
-	if fechaDesde != "" {
-		query = query.Where("fecha >= ?", fechaDesde)
-	}
-
-	if fechaHasta != "" {
-		query = query.Where("fecha <= ?", fechaHasta)
-	}
-
-	if proveedor != "" {
-		query = query.Where("proveedor ILIKE ?", "%"+proveedor+"%")
-	}
+	query := consultaGastos(c)
 
 	// Contar total
 	var total int64
-	query.Model(&models.Gasto{}).Count(&total)
+	query.Count(&total)
 
 	// Obtener gastos
 	var gastos []models.Gasto
@@ -117,6 +98,34 @@ func ListarGastos(c *gin.Context) {
 	})
 }
 
+// consultaGastos arma la consulta de los gastos del cliente autenticado con los filtros de la
+// query; la comparten el listado y la exportación
+func consultaGastos(c *gin.Context) *gorm.DB {
+	clienteID, _ := c.Get("cliente_id")
+	return filtrarGastos(c, config.DB.Model(&models.Gasto{}).Where("cliente_id = ?", clienteID))
+}
+
+// filtrarGastos aplica los filtros opcionales de categoría, fechas (YYYY-MM-DD) y proveedor
+func filtrarGastos(c *gin.Context, query *gorm.DB) *gorm.DB {
+	if categoria := c.Query("categoria"); categoria != "" {
+		query = query.Where("categoria = ?", categoria)
+	}
+
+	if fechaDesde := c.Query("fecha_desde"); fechaDesde != "" {
+		query = query.Where("fecha >= ?", fechaDesde)
+	}
+
+	if fechaHasta := c.Query("fecha_hasta"); fechaHasta != "" {
+		query = query.Where("fecha <= ?", fechaHasta)
+	}
+
+	if proveedor := c.Query("proveedor"); proveedor != "" {
+		query = query.Where("proveedor ILIKE ?", "%"+proveedor+"%")
+	}
+
+	return query
+}
+
 // ObtenerGasto obtiene un gasto por ID
 func ObtenerGasto(c *gin.Context) {
 	clienteID, _ := c.Get("cliente_id")
@@ -175,7 +184,13 @@ func ActualizarGasto(c *gin.Context) {
 	gasto.Comprobante = input.Comprobante
 	gasto.Notas = input.Notas
 
-	if err := config.DB.Save(&gasto).Error; err != nil {
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&gasto).Error; err != nil {
+			return err
+		}
+		return sincronizarCajaGasto(tx, gasto, c.GetInt("user_id"))
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar gasto"})
 		return
 	}
@@ -203,7 +218,13 @@ func EliminarGasto(c *gin.Context) {
 	}
 
 	// Eliminar
-	if err := config.DB.Delete(&gasto).Error; err != nil {
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := anularCajaGasto(tx, gasto.ID); err != nil {
+			return err
+		}
+		return tx.Delete(&gasto).Error
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al eliminar gasto"})
 		return
 	}
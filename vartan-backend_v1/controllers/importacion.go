@@ -0,0 +1,422 @@
+package controllers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"vartan-backend/config"
+	"vartan-backend/models"
+	"vartan-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	maxTamanioImportacion = 5 * 1024 * 1024
+	maxFilasImportacion   = 5000
+)
+
+// Nombres aceptados para cada columna del archivo (en minúscula y sin acentos)
+var columnasImportacion = map[string]string{
+	"nombre": "nombre", "producto": "nombre",
+	"tipo": "tipo", "tipo de producto": "tipo", "tipo_producto": "tipo",
+	"equipo": "equipo",
+	"costo":  "costo", "costo unitario": "costo", "costo_unitario": "costo",
+	"precio": "precio", "precio venta": "precio", "precio de venta": "precio", "precio_venta": "precio",
+	"talle": "talle", "talles": "talle",
+	"color": "color", "colores": "color",
+	"cantidad": "cantidad", "stock": "cantidad",
+}
+
+// ImportarProductos godoc
+// @Summary Importar productos y stock
+// @Description Carga productos y stock desde un CSV o XLSX con las columnas nombre, tipo, equipo, costo, precio, talle, color y cantidad (una fila por variante; talle y color aceptan varios valores separados por coma). Los productos se identifican por nombre, tipo y equipo: los existentes se actualizan y el stock se suma. Por defecto es una prueba que informa los errores por fila sin guardar; con aplicar=true se guarda todo en una sola transacción, solo si no hay errores (solo dueño).
+// @Tags Productos
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param archivo formData file true "Archivo CSV o XLSX"
+// @Param aplicar query bool false "Guardar los cambios (por defecto solo se valida)"
+// @Success 200 {object} models.ImportacionProductosResponse
+// @Failure 400 {object} models.ImportacionProductosResponse "Archivo inválido o filas con errores"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/productos/importar [post]
+func ImportarProductos(c *gin.Context) {
+	archivo, err := c.FormFile("archivo")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Debe enviar el archivo en el campo archivo"})
+		return
+	}
+	if archivo.Size > maxTamanioImportacion {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "El archivo no puede superar los 5MB"})
+		return
+	}
+
+	f, err := archivo.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No se pudo leer el archivo"})
+		return
+	}
+	data, err := io.ReadAll(f)
+	f.Close()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No se pudo leer el archivo"})
+		return
+	}
+
+	// El formato se reconoce por el contenido: un XLSX es un ZIP
+	var filas [][]string
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		filas, err = utils.LeerXLSX(data)
+	} else {
+		filas, err = utils.LeerCSV(data)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No se pudo leer el archivo: " + err.Error()})
+		return
+	}
+	if len(filas) < 2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "El archivo no tiene filas para importar"})
+		return
+	}
+	if len(filas) > maxFilasImportacion+1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("No se pueden importar más de %d filas por vez", maxFilasImportacion)})
+		return
+	}
+
+	columnas, err := columnasDeEncabezado(filas[0])
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	aplicar := c.Query("aplicar") == "true"
+
+	// La prueba recorre el mismo camino que la importación real y se descarta con rollback,
+	// así los totales informados son exactamente los que se aplicarían
+	tx := config.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	importador := nuevoImportador(tx, c.GetInt("user_id"))
+	for i, fila := range filas[1:] {
+		if err := importador.procesarFila(i+2, fila, columnas); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al importar productos"})
+			return
+		}
+	}
+
+	resultado := importador.resultado
+	if !aplicar || len(resultado.Errores) > 0 {
+		tx.Rollback()
+		if aplicar {
+			resultado.Error = "El archivo tiene errores; no se importó nada"
+			c.JSON(http.StatusBadRequest, resultado)
+			return
+		}
+		c.JSON(http.StatusOK, resultado)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al importar productos"})
+		return
+	}
+
+	resultado.Aplicado = true
+	c.JSON(http.StatusOK, resultado)
+}
+
+// columnasDeEncabezado ubica cada columna conocida en la fila de encabezado
+func columnasDeEncabezado(encabezado []string) (map[string]int, error) {
+	columnas := map[string]int{}
+	for i, titulo := range encabezado {
+		if nombre, ok := columnasImportacion[normalizarEncabezado(titulo)]; ok {
+			columnas[nombre] = i
+		}
+	}
+
+	for _, requerida := range []string{"nombre", "talle", "color", "cantidad"} {
+		if _, ok := columnas[requerida]; !ok {
+			return nil, fmt.Errorf("Falta la columna %s en el encabezado", requerida)
+		}
+	}
+	return columnas, nil
+}
+
+func normalizarEncabezado(titulo string) string {
+	titulo = strings.ToLower(strings.TrimSpace(titulo))
+	return strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u").Replace(titulo)
+}
+
+// importador mantiene los productos, tipos y equipos ya resueltos durante la importación
+type importador struct {
+	tx        *gorm.DB
+	usuarioID int
+	tipos     map[string]*int
+	equipos   map[string]*int
+	productos map[string]*models.Producto
+	resultado models.ImportacionProductosResponse
+}
+
+func nuevoImportador(tx *gorm.DB, usuarioID int) *importador {
+	return &importador{
+		tx:        tx,
+		usuarioID: usuarioID,
+		tipos:     map[string]*int{},
+		equipos:   map[string]*int{},
+		productos: map[string]*models.Producto{},
+		resultado: models.ImportacionProductosResponse{Errores: []models.ErrorImportacion{}},
+	}
+}
+
+// procesarFila valida la fila y, si está bien, crea o actualiza el producto y suma el stock.
+// Los problemas de la fila se acumulan en el resultado; solo se devuelven los errores de base de datos.
+func (imp *importador) procesarFila(numero int, fila []string, columnas map[string]int) error {
+	valor := func(columna string) string {
+		i, ok := columnas[columna]
+		if !ok || i >= len(fila) {
+			return ""
+		}
+		return strings.TrimSpace(fila[i])
+	}
+	agregarError := func(columna, mensaje string) {
+		imp.resultado.Errores = append(imp.resultado.Errores, models.ErrorImportacion{Fila: numero, Columna: columna, Mensaje: mensaje})
+	}
+
+	// Las filas vacías se ignoran
+	if strings.TrimSpace(strings.Join(fila, "")) == "" {
+		return nil
+	}
+	imp.resultado.Filas++
+	erroresPrevios := len(imp.resultado.Errores)
+
+	nombre := valor("nombre")
+	if nombre == "" {
+		agregarError("nombre", "El nombre es obligatorio")
+	} else if len([]rune(nombre)) > 100 {
+		agregarError("nombre", "El nombre no puede superar los 100 caracteres")
+	}
+
+	tipoID, err := imp.buscarPorNombre(imp.tipos, &models.TipoProducto{}, valor("tipo"))
+	if err != nil {
+		return err
+	}
+	if valor("tipo") != "" && tipoID == nil {
+		agregarError("tipo", "Tipo de producto inexistente: "+valor("tipo"))
+	}
+	equipoID, err := imp.buscarPorNombre(imp.equipos, &models.Equipo{}, valor("equipo"))
+	if err != nil {
+		return err
+	}
+	if valor("equipo") != "" && equipoID == nil {
+		agregarError("equipo", "Equipo inexistente: "+valor("equipo"))
+	}
+
+	costo, okCosto := imp.numero(valor("costo"), "costo", agregarError)
+	precio, okPrecio := imp.numero(valor("precio"), "precio", agregarError)
+
+	cantidad, okCantidad := imp.numero(valor("cantidad"), "cantidad", agregarError)
+	if valor("cantidad") == "" {
+		agregarError("cantidad", "La cantidad es obligatoria")
+	} else if okCantidad && (cantidad < 0 || cantidad != float64(int(cantidad))) {
+		agregarError("cantidad", "La cantidad debe ser un número entero no negativo")
+	}
+
+	var talles []models.TalleEnum
+	for _, t := range dividirLista(valor("talle")) {
+		talles = append(talles, models.TalleEnum(t))
+	}
+	var colores []models.ColorEnum
+	for _, col := range dividirLista(valor("color")) {
+		colores = append(colores, models.ColorEnum(col))
+	}
+	if len(talles) == 0 {
+		agregarError("talle", "El talle es obligatorio")
+	}
+	if len(colores) == 0 {
+		agregarError("color", "El color es obligatorio")
+	}
+
+	// Mismas validaciones que al crear productos y agregar stock
+	if err := validarTallesColores(imp.tx, tipoID, talles, colores); err != nil {
+		var vErr *ventaError
+		if !errors.As(err, &vErr) {
+			return err
+		}
+		columna := "talle"
+		if strings.HasPrefix(vErr.mensaje, "Color") {
+			columna = "color"
+		}
+		agregarError(columna, vErr.mensaje)
+	}
+
+	if len(imp.resultado.Errores) > erroresPrevios {
+		return nil
+	}
+
+	producto, nuevo, err := imp.buscarProducto(nombre, tipoID, equipoID)
+	if err != nil {
+		return err
+	}
+	if nuevo && (!okCosto || costo <= 0) {
+		agregarError("costo", "El costo es obligatorio para los productos nuevos")
+		return nil
+	}
+	if okCosto && costo < 0 || okPrecio && precio < 0 {
+		agregarError("", "El costo y el precio no pueden ser negativos")
+		return nil
+	}
+
+	anterior := *producto
+	if okCosto && costo > 0 {
+		producto.CostoUnitario = costo
+	}
+	if okPrecio && precio > 0 {
+		producto.PrecioVenta = precio
+	}
+	producto.TallesDisponibles = agregarSinRepetir(producto.TallesDisponibles, talles)
+	producto.ColoresDisponibles = agregarSinRepetir(producto.ColoresDisponibles, colores)
+
+	if nuevo {
+		if err := imp.tx.Create(producto).Error; err != nil {
+			return err
+		}
+		imp.resultado.ProductosNuevos++
+	} else {
+		if err := imp.tx.Omit("Imagenes", "TipoProducto", "Equipo").Save(producto).Error; err != nil {
+			return err
+		}
+		if err := registrarCambioPrecio(imp.tx, anterior, producto.CostoUnitario, producto.PrecioVenta, models.CambioPrecioImportacion, "", imp.usuarioID); err != nil {
+			return err
+		}
+	}
+
+	for _, talle := range talles {
+		for _, color := range colores {
+			_, creado, err := sumarStock(imp.tx, producto.ID, talle, color, int(cantidad))
+			if err != nil {
+				return err
+			}
+			if creado {
+				imp.resultado.VariantesNuevas++
+			}
+			imp.resultado.Unidades += int(cantidad)
+		}
+	}
+	return nil
+}
+
+// numero interpreta una celda numérica; vacía devuelve ok=false sin error
+func (imp *importador) numero(texto, columna string, agregarError func(string, string)) (float64, bool) {
+	if texto == "" {
+		return 0, false
+	}
+	n, err := utils.ParseNumero(texto)
+	if err != nil {
+		agregarError(columna, "Número inválido: "+texto)
+		return 0, false
+	}
+	return n, true
+}
+
+// buscarPorNombre resuelve un tipo de producto o equipo activo por nombre, sin distinguir mayúsculas
+func (imp *importador) buscarPorNombre(cache map[string]*int, modelo interface{}, nombre string) (*int, error) {
+	if nombre == "" {
+		return nil, nil
+	}
+	clave := strings.ToLower(nombre)
+	if id, ok := cache[clave]; ok {
+		return id, nil
+	}
+
+	var ids []int
+	if err := imp.tx.Model(modelo).Where("LOWER(nombre) = ? AND activo = ?", clave, true).Limit(1).Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	var id *int
+	if len(ids) > 0 {
+		id = &ids[0]
+	}
+	cache[clave] = id
+	return id, nil
+}
+
+// buscarProducto devuelve el producto activo con ese nombre, tipo y equipo, o uno nuevo sin guardar
+func (imp *importador) buscarProducto(nombre string, tipoID, equipoID *int) (*models.Producto, bool, error) {
+	clave := fmt.Sprintf("%s|%v|%v", strings.ToLower(nombre), valorOCero(tipoID), valorOCero(equipoID))
+	if producto, ok := imp.productos[clave]; ok {
+		// Si ya se contó como nuevo en esta importación, las filas siguientes no son actualizaciones
+		return producto, false, nil
+	}
+
+	query := imp.tx.Where("LOWER(nombre) = LOWER(?) AND activo = ?", nombre, true)
+	if tipoID != nil {
+		query = query.Where("tipo_producto_id = ?", *tipoID)
+	} else {
+		query = query.Where("tipo_producto_id IS NULL")
+	}
+	if equipoID != nil {
+		query = query.Where("equipo_id = ?", *equipoID)
+	} else {
+		query = query.Where("equipo_id IS NULL")
+	}
+
+	var producto models.Producto
+	err := query.First(&producto).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		producto = models.Producto{Nombre: nombre, Activo: true, TipoProductoID: tipoID, EquipoID: equipoID}
+		imp.productos[clave] = &producto
+		return &producto, true, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	imp.productos[clave] = &producto
+	imp.resultado.ProductosActualizados++
+	return &producto, false, nil
+}
+
+func valorOCero(id *int) int {
+	if id == nil {
+		return 0
+	}
+	return *id
+}
+
+// dividirLista separa los valores de una celda como "S, M, L"
+func dividirLista(texto string) []string {
+	var valores []string
+	for _, v := range strings.FieldsFunc(texto, func(r rune) bool { return r == ',' || r == '|' }) {
+		if v = strings.TrimSpace(v); v != "" {
+			valores = append(valores, v)
+		}
+	}
+	return valores
+}
+
+// agregarSinRepetir suma a la lista los valores que todavía no tiene
+func agregarSinRepetir[T comparable](lista []T, nuevos []T) []T {
+	for _, n := range nuevos {
+		existe := false
+		for _, v := range lista {
+			if v == n {
+				existe = true
+				break
+			}
+		}
+		if !existe {
+			lista = append(lista, n)
+		}
+	}
+	return lista
+}
@@ -0,0 +1,268 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+	"vartan-backend/config"
+	"vartan-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetObjetivos godoc
+// @Summary Listar objetivos del mes
+// @Description Obtiene los objetivos de venta cargados para el mes (solo dueño)
+// @Tags Objetivos
+// @Produce json
+// @Security BearerAuth
+// @Param mes query string false "Mes (YYYY-MM), por defecto el mes en curso"
+// @Success 200 {array} models.ObjetivoVenta
+// @Failure 400 {object} map[string]string "Mes inválido"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/objetivos [get]
+func GetObjetivos(c *gin.Context) {
+	mes, anio, err := mesConsultado(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	objetivos := []models.ObjetivoVenta{}
+	if err := config.DB.Preload("Usuario").
+		Where("mes = ? AND anio = ?", mes, anio).
+		Order("usuario_id").
+		Find(&objetivos).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener objetivos"})
+		return
+	}
+
+	c.JSON(http.StatusOK, objetivos)
+}
+
+// GuardarObjetivo godoc
+// @Summary Definir objetivo de un vendedor
+// @Description Crea el objetivo del vendedor para el mes o reemplaza el que tenía. Se indica un monto, una cantidad de unidades o ambos, y opcionalmente el bono que se suma a la comisión si se cumplen (solo dueño).
+// @Tags Objetivos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.ObjetivoVentaRequest true "Objetivo"
+// @Success 200 {object} models.ObjetivoVenta
+// @Failure 400 {object} map[string]string "Datos inválidos"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/objetivos [put]
+func GuardarObjetivo(c *gin.Context) {
+	var req models.ObjetivoVentaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+	if req.MontoObjetivo == nil && req.UnidadesObjetivo == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Indique el monto o las unidades del objetivo"})
+		return
+	}
+
+	var usuario models.Usuario
+	if err := config.DB.First(&usuario, req.UsuarioID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Usuario vendedor no encontrado"})
+		return
+	}
+	if usuario.Rol != "empleado" && usuario.Rol != "dueño" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "El usuario seleccionado no es un vendedor"})
+		return
+	}
+
+	var objetivo models.ObjetivoVenta
+	err := config.DB.Where("usuario_id = ? AND mes = ? AND anio = ?", req.UsuarioID, req.Mes, req.Anio).First(&objetivo).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener objetivo"})
+		return
+	}
+	objetivo.UsuarioID = req.UsuarioID
+	objetivo.Mes = req.Mes
+	objetivo.Anio = req.Anio
+	objetivo.MontoObjetivo = req.MontoObjetivo
+	objetivo.UnidadesObjetivo = req.UnidadesObjetivo
+	objetivo.Bono = req.Bono
+
+	if err := config.DB.Save(&objetivo).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al guardar objetivo"})
+		return
+	}
+
+	objetivo.Usuario = &usuario
+	c.JSON(http.StatusOK, objetivo)
+}
+
+// DeleteObjetivo godoc
+// @Summary Eliminar objetivo
+// @Description Elimina el objetivo de un vendedor. Las comisiones ya calculadas conservan el bono hasta que se vuelvan a calcular (solo dueño).
+// @Tags Objetivos
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID del objetivo"
+// @Success 200 {object} map[string]string "Objetivo eliminado"
+// @Failure 404 {object} map[string]string "Objetivo no encontrado"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/objetivos/{id} [delete]
+func DeleteObjetivo(c *gin.Context) {
+	var objetivo models.ObjetivoVenta
+	if err := config.DB.First(&objetivo, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Objetivo no encontrado"})
+		return
+	}
+
+	if err := config.DB.Delete(&objetivo).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al eliminar objetivo"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Objetivo eliminado exitosamente"})
+}
+
+// GetMisObjetivos godoc
+// @Summary Mi avance del mes
+// @Description Lo vendido por el usuario autenticado en el mes (total final y unidades sin lo devuelto, y cantidad de ventas) frente a su objetivo, con el porcentaje alcanzado de cada meta. Sin objetivo cargado las metas vienen en null.
+// @Tags Objetivos
+// @Produce json
+// @Security BearerAuth
+// @Param mes query string false "Mes (YYYY-MM), por defecto el mes en curso"
+// @Success 200 {object} models.ProgresoObjetivo
+// @Failure 400 {object} map[string]string "Mes inválido"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/mis-objetivos [get]
+func GetMisObjetivos(c *gin.Context) {
+	mes, anio, err := mesConsultado(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var usuario models.Usuario
+	if err := config.DB.First(&usuario, c.GetInt("user_id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Usuario no encontrado"})
+		return
+	}
+
+	progreso, err := progresoVendedores(config.DB, mes, anio, []models.Usuario{usuario})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al calcular avance del objetivo"})
+		return
+	}
+
+	c.JSON(http.StatusOK, progreso[0])
+}
+
+// GetRankingVendedores godoc
+// @Summary Ranking de vendedores
+// @Description Vendedores activos (y los que tienen objetivo en el mes) ordenados por lo vendido en el mes, con su avance sobre el objetivo
+// @Tags Objetivos
+// @Produce json
+// @Security BearerAuth
+// @Param mes query string false "Mes (YYYY-MM), por defecto el mes en curso"
+// @Success 200 {array} models.ProgresoObjetivo
+// @Failure 400 {object} map[string]string "Mes inválido"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/objetivos/ranking [get]
+func GetRankingVendedores(c *gin.Context) {
+	mes, anio, err := mesConsultado(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var usuarios []models.Usuario
+	if err := config.DB.
+		Where("(rol = ? AND activo = ?) OR id IN (SELECT usuario_id FROM objetivos_venta WHERE mes = ? AND anio = ?)", "empleado", true, mes, anio).
+		Find(&usuarios).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener vendedores"})
+		return
+	}
+
+	ranking, err := progresoVendedores(config.DB, mes, anio, usuarios)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al calcular ranking"})
+		return
+	}
+	models.OrdenarRanking(ranking)
+
+	c.JSON(http.StatusOK, ranking)
+}
+
+// mesConsultado lee el parámetro mes (YYYY-MM); por defecto el mes en curso
+func mesConsultado(c *gin.Context) (int, int, error) {
+	fecha := time.Now()
+	if mes := c.Query("mes"); mes != "" {
+		var err error
+		if fecha, err = time.ParseInLocation("2006-01", mes, time.Local); err != nil {
+			return 0, 0, errors.New("Formato de mes inválido. Use YYYY-MM")
+		}
+	}
+	return int(fecha.Month()), fecha.Year(), nil
+}
+
+// progresoVendedores calcula lo vendido en el mes por cada usuario y lo compara con su objetivo
+func progresoVendedores(db *gorm.DB, mes, anio int, usuarios []models.Usuario) ([]models.ProgresoObjetivo, error) {
+	progreso := make([]models.ProgresoObjetivo, len(usuarios))
+	if len(usuarios) == 0 {
+		return progreso, nil
+	}
+	ids := make([]int, len(usuarios))
+	for i, usuario := range usuarios {
+		ids[i] = usuario.ID
+	}
+	desde := time.Date(anio, time.Month(mes), 1, 0, 0, 0, 0, time.Local)
+	hasta := desde.AddDate(0, 1, 0)
+
+	var ventas []struct {
+		UsuarioID int
+		Ventas    int64
+		Vendido   float64
+	}
+	// Lo reintegrado por devoluciones no cuenta como vendido
+	if err := db.Model(&models.Venta{}).
+		Select("usuario_id, COUNT(*) AS ventas, COALESCE(SUM(total_final), 0) - COALESCE(SUM(dev.monto), 0) AS vendido").
+		Joins(`LEFT JOIN (
+			SELECT venta_id, SUM(monto_inicial) AS monto FROM saldos_a_favor WHERE origen = ? GROUP BY venta_id
+		) dev ON dev.venta_id = venta.id`, models.SaldoAFavorDevolucion).
+		Where("usuario_id IN ? AND fecha_venta >= ? AND fecha_venta < ?", ids, desde, hasta).
+		Group("usuario_id").
+		Scan(&ventas).Error; err != nil {
+		return nil, err
+	}
+	var unidades []struct {
+		UsuarioID int
+		Unidades  int64
+	}
+	if err := db.Table("venta_detalles vd").
+		Select("v.usuario_id, COALESCE(SUM(vd.cantidad - vd.cantidad_devuelta), 0) AS unidades").
+		Joins("JOIN venta v ON v.id = vd.venta_id").
+		Where("v.usuario_id IN ? AND v.fecha_venta >= ? AND v.fecha_venta < ?", ids, desde, hasta).
+		Group("v.usuario_id").
+		Scan(&unidades).Error; err != nil {
+		return nil, err
+	}
+	var objetivos []models.ObjetivoVenta
+	if err := db.Where("usuario_id IN ? AND mes = ? AND anio = ?", ids, mes, anio).Find(&objetivos).Error; err != nil {
+		return nil, err
+	}
+
+	indice := make(map[int]int, len(usuarios))
+	for i, usuario := range usuarios {
+		progreso[i] = models.ProgresoObjetivo{UsuarioID: usuario.ID, Nombre: usuario.Nombre, Mes: mes, Anio: anio}
+		indice[usuario.ID] = i
+	}
+	for _, v := range ventas {
+		p := &progreso[indice[v.UsuarioID]]
+		p.Ventas, p.Vendido = v.Ventas, redondear(v.Vendido)
+	}
+	for _, u := range unidades {
+		progreso[indice[u.UsuarioID]].Unidades = u.Unidades
+	}
+	for i := range objetivos {
+		progreso[indice[objetivos[i].UsuarioID]].AplicarObjetivo(&objetivos[i])
+	}
+	return progreso, nil
+}
@@ -32,7 +32,7 @@ func GetPedidos(c *gin.Context) {
 		Joins("JOIN venta ON venta.id = pedidos.venta_id").
 		Where("venta.usuario_id = ?", userID).
 		Preload("Venta").
-		Preload("Venta.Cliente").
+		Preload("Venta.Cliente", conArchivados).
 		Preload("Venta.Detalles").
 		Preload("Venta.Detalles.Producto").
 		Order("pedidos.fecha_creacion DESC").
@@ -69,7 +69,7 @@ func GetPedidosByEstado(c *gin.Context) {
 	if err := config.DB.
 		Where("estado = ?", estado).
 		Preload("Venta").
-		Preload("Venta.Cliente").
+		Preload("Venta.Cliente", conArchivados).
 		Preload("Venta.Usuario").
 		Preload("Venta.Detalles"). // ✅ AGREGAR ESTO
 		Preload("Venta.Detalles.Producto"). // ✅ AGREGAR ESTO (opcional pero útil)
@@ -100,7 +100,7 @@ func GetMisPedidos(c *gin.Context) {
 		Joins("JOIN venta ON venta.id = pedidos.venta_id").
 		Where("venta.usuario_id = ?", userID).
 		Preload("Venta").
-		Preload("Venta.Cliente").
+		Preload("Venta.Cliente", conArchivados).
 		Preload("Venta.Detalles").
 		Preload("Venta.Detalles.Producto").
 		Order("pedidos.fecha_creacion DESC").
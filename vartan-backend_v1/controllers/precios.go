@@ -0,0 +1,176 @@
+package controllers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"vartan-backend/config"
+	"vartan-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ActualizarPrecios godoc
+// @Summary Actualización masiva de precios
+// @Description Aplica un porcentaje o monto fijo al costo y/o precio de venta de los productos activos filtrados por tipo, equipo o IDs. Por defecto devuelve la vista previa; con aplicar=true guarda los cambios y el historial. Los productos sin precio de venta cargado no cambian su precio (solo dueño).
+// @Tags Productos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param aplicar query bool false "Guardar los cambios (por defecto solo vista previa)"
+// @Param request body models.ActualizacionPreciosRequest true "Ajuste a aplicar"
+// @Success 200 {object} models.ActualizacionPreciosResponse
+// @Failure 400 {object} map[string]string "Datos inválidos o precio resultante no positivo"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/precios/actualizar [post]
+func ActualizarPrecios(c *gin.Context) {
+	var req models.ActualizacionPreciosRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+	if req.TipoProductoID == nil && req.EquipoID == nil && len(req.ProductoIDs) == 0 && !req.Todos {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Indique tipo de producto, equipo o productos, o todos=true para todo el catálogo"})
+		return
+	}
+	if req.RedondearA < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "redondear_a no puede ser negativo"})
+		return
+	}
+	if req.Motivo == "" {
+		req.Motivo = fmt.Sprintf("Actualización masiva %+g", req.Valor)
+		if req.Tipo == "porcentaje" {
+			req.Motivo += "%"
+		}
+	}
+
+	aplicar := c.Query("aplicar") == "true"
+	usuarioID := c.GetInt("user_id")
+	var resultado models.ActualizacionPreciosResponse
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		query := tx.Where("activo = ?", true)
+		if aplicar {
+			query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+		}
+		if req.TipoProductoID != nil {
+			query = query.Where("tipo_producto_id = ?", *req.TipoProductoID)
+		}
+		if req.EquipoID != nil {
+			query = query.Where("equipo_id = ?", *req.EquipoID)
+		}
+		if len(req.ProductoIDs) > 0 {
+			query = query.Where("id IN ?", req.ProductoIDs)
+		}
+
+		var productos []models.Producto
+		if err := query.Order("nombre, id").Find(&productos).Error; err != nil {
+			return err
+		}
+
+		resultado.Cambios = make([]models.CambioPrecio, 0, len(productos))
+		for _, p := range productos {
+			cambio := models.CambioPrecio{
+				ProductoID:     p.ID,
+				Nombre:         p.Nombre,
+				CostoAnterior:  p.CostoUnitario,
+				CostoNuevo:     p.CostoUnitario,
+				PrecioAnterior: p.PrecioVenta,
+				PrecioNuevo:    p.PrecioVenta,
+			}
+			if req.AplicarA != "precio" {
+				cambio.CostoNuevo = ajustarPrecio(p.CostoUnitario, req)
+			}
+			if req.AplicarA != "costo" && p.PrecioVenta > 0 {
+				cambio.PrecioNuevo = ajustarPrecio(p.PrecioVenta, req)
+			}
+			if cambio.CostoNuevo <= 0 || req.AplicarA != "costo" && p.PrecioVenta > 0 && cambio.PrecioNuevo <= 0 {
+				return &ventaError{http.StatusBadRequest, "El ajuste deja en cero o negativo el precio de " + p.Nombre}
+			}
+			resultado.Cambios = append(resultado.Cambios, cambio)
+
+			if !aplicar || (cambio.CostoNuevo == cambio.CostoAnterior && cambio.PrecioNuevo == cambio.PrecioAnterior) {
+				continue
+			}
+			if err := tx.Model(&models.Producto{}).Where("id = ?", p.ID).
+				Updates(map[string]interface{}{"costo_unitario": cambio.CostoNuevo, "precio_venta": cambio.PrecioNuevo}).Error; err != nil {
+				return err
+			}
+			if err := registrarCambioPrecio(tx, p, cambio.CostoNuevo, cambio.PrecioNuevo, models.CambioPrecioMasivo, req.Motivo, usuarioID); err != nil {
+				return err
+			}
+		}
+		resultado.Productos = len(resultado.Cambios)
+		resultado.Aplicado = aplicar
+		return nil
+	})
+	if err != nil {
+		responderError(c, err, "Error al actualizar precios")
+		return
+	}
+
+	c.JSON(http.StatusOK, resultado)
+}
+
+// ajustarPrecio aplica el porcentaje o monto y el redondeo pedido
+func ajustarPrecio(valor float64, req models.ActualizacionPreciosRequest) float64 {
+	if req.Tipo == "porcentaje" {
+		valor *= 1 + req.Valor/100
+	} else {
+		valor += req.Valor
+	}
+	if req.RedondearA > 0 {
+		valor = math.Round(valor/req.RedondearA) * req.RedondearA
+	}
+	return redondear(valor)
+}
+
+// registrarCambioPrecio guarda en el historial el cambio de costo o precio del producto.
+// No hace nada si ninguno de los dos cambió.
+func registrarCambioPrecio(tx *gorm.DB, anterior models.Producto, costoNuevo, precioNuevo float64, origen, motivo string, usuarioID int) error {
+	if anterior.CostoUnitario == costoNuevo && anterior.PrecioVenta == precioNuevo {
+		return nil
+	}
+	return tx.Create(&models.HistorialPrecio{
+		ProductoID:     anterior.ID,
+		CostoAnterior:  anterior.CostoUnitario,
+		CostoNuevo:     costoNuevo,
+		PrecioAnterior: anterior.PrecioVenta,
+		PrecioNuevo:    precioNuevo,
+		Origen:         origen,
+		Motivo:         motivo,
+		UsuarioID:      usuarioID,
+	}).Error
+}
+
+// GetHistorialPrecios godoc
+// @Summary Historial de precios de un producto
+// @Description Obtiene los cambios de costo y precio de venta del producto, del más reciente al más antiguo (solo dueño)
+// @Tags Productos
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID del producto"
+// @Success 200 {array} models.HistorialPrecio
+// @Failure 404 {object} map[string]string "Producto no encontrado"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/productos/{id}/precios [get]
+func GetHistorialPrecios(c *gin.Context) {
+	var producto models.Producto
+	if err := config.DB.Select("id").First(&producto, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Producto no encontrado"})
+		return
+	}
+
+	historial := []models.HistorialPrecio{}
+	if err := config.DB.Preload("Usuario").
+		Where("producto_id = ?", producto.ID).
+		Order("fecha DESC, id DESC").
+		Find(&historial).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener historial de precios"})
+		return
+	}
+
+	c.JSON(http.StatusOK, historial)
+}
@@ -0,0 +1,489 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+	"vartan-backend/config"
+	"vartan-backend/models"
+	"vartan-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetPresupuestos godoc
+// @Summary Listar presupuestos
+// @Description Lista los presupuestos. Dueño ve todos, vendedor solo los suyos.
+// @Tags Presupuestos
+// @Produce json
+// @Security BearerAuth
+// @Param estado query string false "Filtrar por estado" Enums(pendiente, aceptado, rechazado, convertido)
+// @Param cliente_id query int false "Filtrar por cliente"
+// @Success 200 {array} models.Presupuesto
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/presupuestos [get]
+func GetPresupuestos(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	userRol := c.GetString("rol")
+
+	query := config.DB.Preload("Cliente", conArchivados).Preload("Usuario")
+
+	// Si es vendedor/empleado, solo ver sus propios presupuestos
+	if userRol == "empleado" {
+		query = query.Where("usuario_id = ?", userID)
+	}
+
+	if estado := c.Query("estado"); estado != "" {
+		query = query.Where("estado = ?", estado)
+	}
+
+	if clienteIDStr := c.Query("cliente_id"); clienteIDStr != "" {
+		if clienteID, err := strconv.Atoi(clienteIDStr); err == nil {
+			query = query.Where("cliente_id = ?", clienteID)
+		}
+	}
+
+	var presupuestos []models.Presupuesto
+	if err := query.Order("fecha_creacion DESC").Find(&presupuestos).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener presupuestos"})
+		return
+	}
+
+	c.JSON(http.StatusOK, presupuestos)
+}
+
+// GetPresupuesto godoc
+// @Summary Obtener presupuesto por ID
+// @Description Obtiene un presupuesto con sus renglones
+// @Tags Presupuestos
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID del presupuesto"
+// @Success 200 {object} models.Presupuesto
+// @Failure 403 {object} map[string]string "Sin permisos"
+// @Failure 404 {object} map[string]string "Presupuesto no encontrado"
+// @Router /api/presupuestos/{id} [get]
+func GetPresupuesto(c *gin.Context) {
+	presupuesto, ok := buscarPresupuesto(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, presupuesto)
+}
+
+// CreatePresupuesto godoc
+// @Summary Crear presupuesto
+// @Description Crea un presupuesto para un cliente. No descuenta stock.
+// @Tags Presupuestos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.PresupuestoCreateRequest true "Datos del presupuesto"
+// @Success 201 {object} models.Presupuesto
+// @Failure 400 {object} map[string]string "Datos inválidos"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/presupuestos [post]
+func CreatePresupuesto(c *gin.Context) {
+	var req models.PresupuestoCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+
+	fechaValidez, err := time.ParseInLocation("2006-01-02", req.FechaValidez, time.Local)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Formato de fecha inválido. Use YYYY-MM-DD"})
+		return
+	}
+
+	var cliente models.Cliente
+	if err := config.DB.First(&cliente, req.ClienteID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cliente no encontrado"})
+		return
+	}
+
+	// Validar renglones y calcular el total
+	var total float64
+	detalles := make([]models.PresupuestoDetalle, 0, len(req.Detalles))
+	for _, d := range req.Detalles {
+		if d.Cantidad <= 0 || d.PrecioUnitario <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cantidad y precio unitario deben ser mayores a cero"})
+			return
+		}
+
+		// Con variante, el producto, el talle y el color salen de ella
+		if d.VarianteID != nil {
+			var variante models.VarianteProducto
+			if err := config.DB.First(&variante, *d.VarianteID).Error; err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Variante %d no encontrada", *d.VarianteID)})
+				return
+			}
+			if d.ProductoID != 0 && d.ProductoID != variante.ProductoID {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "La variante no corresponde al producto indicado"})
+				return
+			}
+			d.ProductoID, d.Talle, d.Color = variante.ProductoID, variante.Talle, variante.Color
+		}
+
+		var producto models.Producto
+		if err := config.DB.Where("id = ? AND activo = ?", d.ProductoID, true).First(&producto).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Producto %d no encontrado", d.ProductoID)})
+			return
+		}
+		var colores []models.ColorEnum
+		if d.Color != "" {
+			colores = []models.ColorEnum{models.ColorEnum(d.Color)}
+		}
+		if err := validarTallesColores(config.DB, producto.TipoProductoID, []models.TalleEnum{models.TalleEnum(d.Talle)}, colores); err != nil {
+			responderError(c, err, "Error al validar talle y color")
+			return
+		}
+
+		subtotal := d.PrecioUnitario * float64(d.Cantidad)
+		total += subtotal
+		detalles = append(detalles, models.PresupuestoDetalle{
+			ProductoID:     d.ProductoID,
+			Talle:          d.Talle,
+			Cantidad:       d.Cantidad,
+			PrecioUnitario: d.PrecioUnitario,
+			Subtotal:       subtotal,
+			Color:          d.Color,
+			VarianteID:     d.VarianteID,
+		})
+	}
+
+	if req.Descuento > total {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "El descuento no puede superar el total"})
+		return
+	}
+
+	var obs *string
+	if req.Observaciones != "" {
+		obs = &req.Observaciones
+	}
+
+	presupuesto := models.Presupuesto{
+		UsuarioID:     c.GetInt("user_id"),
+		ClienteID:     req.ClienteID,
+		Estado:        models.PresupuestoPendiente,
+		FechaValidez:  fechaValidez,
+		Total:         total,
+		Descuento:     req.Descuento,
+		TotalFinal:    total - req.Descuento,
+		Observaciones: obs,
+		Detalles:      detalles,
+	}
+
+	if err := config.DB.Create(&presupuesto).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al crear presupuesto"})
+		return
+	}
+
+	cargarPresupuesto(config.DB, &presupuesto, presupuesto.ID)
+
+	c.JSON(http.StatusCreated, presupuesto)
+}
+
+// UpdatePresupuestoEstado godoc
+// @Summary Actualizar estado de presupuesto
+// @Description Marca un presupuesto como aceptado, rechazado o pendiente. Los presupuestos convertidos no pueden cambiar.
+// @Tags Presupuestos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID del presupuesto"
+// @Param request body models.PresupuestoEstadoRequest true "Nuevo estado"
+// @Success 200 {object} models.Presupuesto
+// @Failure 400 {object} map[string]string "Estado inválido"
+// @Failure 404 {object} map[string]string "Presupuesto no encontrado"
+// @Router /api/presupuestos/{id}/estado [put]
+func UpdatePresupuestoEstado(c *gin.Context) {
+	presupuesto, ok := buscarPresupuesto(c)
+	if !ok {
+		return
+	}
+
+	var req models.PresupuestoEstadoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Estado inválido"})
+		return
+	}
+
+	if presupuesto.Estado == models.PresupuestoConvertido {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "El presupuesto ya fue convertido en venta"})
+		return
+	}
+
+	if err := config.DB.Model(&presupuesto).Update("estado", req.Estado).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar presupuesto"})
+		return
+	}
+
+	c.JSON(http.StatusOK, presupuesto)
+}
+
+// ConvertirPresupuesto godoc
+// @Summary Convertir presupuesto en venta
+// @Description Genera la venta a partir del presupuesto usando la misma lógica que POST /api/ventas: valida y descuenta stock y aplica el descuento de la forma de pago. El descuento pactado en el presupuesto se mantiene y no se suman promociones automáticas.
+// @Tags Presupuestos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID del presupuesto"
+// @Param request body models.PresupuestoConvertirRequest true "Datos de pago"
+// @Success 201 {object} models.Venta
+// @Failure 400 {object} map[string]string "Presupuesto vencido, ya convertido o stock insuficiente"
+// @Failure 404 {object} map[string]string "Presupuesto no encontrado"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/presupuestos/{id}/convertir [post]
+func ConvertirPresupuesto(c *gin.Context) {
+	presupuesto, ok := buscarPresupuesto(c)
+	if !ok {
+		return
+	}
+
+	var req models.PresupuestoConvertirRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+
+	switch {
+	case presupuesto.Estado == models.PresupuestoConvertido:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "El presupuesto ya fue convertido en venta"})
+		return
+	case presupuesto.Estado == models.PresupuestoRechazado:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "El presupuesto fue rechazado"})
+		return
+	case presupuesto.Vencido(time.Now()):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "El presupuesto está vencido"})
+		return
+	}
+
+	observaciones := req.Observaciones
+	if observaciones == "" {
+		observaciones = fmt.Sprintf("Presupuesto #%d", presupuesto.ID)
+		if presupuesto.Observaciones != nil {
+			observaciones += " - " + *presupuesto.Observaciones
+		}
+	}
+
+	ventaReq := models.VentaCreateRequest{
+		UsuarioID:     req.UsuarioID,
+		ClienteID:     presupuesto.ClienteID,
+		FormaPagoID:   req.FormaPagoID,
+		Sena:          req.Sena,
+		Observaciones: observaciones,
+	}
+	for _, d := range presupuesto.Detalles {
+		ventaReq.Detalles = append(ventaReq.Detalles, models.VentaDetalleCreateRequest{
+			VarianteID:     d.VarianteID,
+			ProductoID:     d.ProductoID,
+			Talle:          d.Talle,
+			Color:          d.Color,
+			Cantidad:       d.Cantidad,
+			PrecioUnitario: d.PrecioUnitario,
+		})
+	}
+
+	// Iniciar transacción
+	tx := config.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// El descuento pactado reemplaza a las promociones automáticas vigentes al convertir
+	venta, err := registrarVenta(tx, c.GetInt("user_id"), ventaReq, nil, presupuesto.Descuento, false)
+	if err != nil {
+		tx.Rollback()
+		responderErrorVenta(c, err)
+		return
+	}
+
+	// Marcar el presupuesto como convertido solo si nadie lo convirtió en paralelo
+	result := tx.Model(&models.Presupuesto{}).
+		Where("id = ? AND estado <> ?", presupuesto.ID, models.PresupuestoConvertido).
+		Updates(map[string]interface{}{"estado": models.PresupuestoConvertido, "venta_id": venta.ID})
+	if result.Error != nil || result.RowsAffected == 0 {
+		tx.Rollback()
+		c.JSON(http.StatusConflict, gin.H{"error": "El presupuesto ya fue convertido en venta"})
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al confirmar venta"})
+		return
+	}
+
+	// Cargar la venta con todas sus relaciones
+	config.DB.
+		Preload("Usuario").
+		Preload("Cliente", conArchivados).
+		Preload("FormaPago").
+		Preload("Detalles").
+		Preload("Detalles.Producto").
+		First(&venta, venta.ID)
+
+	c.JSON(http.StatusCreated, venta)
+}
+
+// GetPresupuestoPDF godoc
+// @Summary Descargar presupuesto en PDF
+// @Description Genera el presupuesto imprimible en formato PDF
+// @Tags Presupuestos
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param id path int true "ID del presupuesto"
+// @Success 200 {file} file "Presupuesto en PDF"
+// @Failure 404 {object} map[string]string "Presupuesto no encontrado"
+// @Router /api/presupuestos/{id}/pdf [get]
+func GetPresupuestoPDF(c *gin.Context) {
+	presupuesto, ok := buscarPresupuesto(c)
+	if !ok {
+		return
+	}
+
+	filename := fmt.Sprintf("presupuesto_%d.pdf", presupuesto.ID)
+	c.Header("Content-Disposition", "inline; filename="+filename)
+	c.Data(http.StatusOK, "application/pdf", generarPDFPresupuesto(presupuesto))
+}
+
+// buscarPresupuesto carga el presupuesto del parámetro :id verificando permisos.
+// Si falla ya escribe la respuesta de error.
+func buscarPresupuesto(c *gin.Context) (models.Presupuesto, bool) {
+	var presupuesto models.Presupuesto
+	if err := cargarPresupuesto(config.DB, &presupuesto, c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Presupuesto no encontrado"})
+		return presupuesto, false
+	}
+
+	// Verificar permisos: vendedor solo puede ver sus propios presupuestos
+	if c.GetString("rol") == "empleado" && presupuesto.UsuarioID != c.GetInt("user_id") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permiso para ver este presupuesto"})
+		return presupuesto, false
+	}
+
+	return presupuesto, true
+}
+
+func cargarPresupuesto(db *gorm.DB, presupuesto *models.Presupuesto, id interface{}) error {
+	return db.
+		Preload("Usuario").
+		Preload("Cliente", conArchivados).
+		Preload("Detalles").
+		Preload("Detalles.Producto").
+		First(presupuesto, id).Error
+}
+
+// generarPDFPresupuesto arma el presupuesto imprimible
+func generarPDFPresupuesto(p models.Presupuesto) []byte {
+	pdf := utils.NewPDF()
+	const margen = 40.0
+	derecha := pdf.Ancho - margen
+
+	// Columnas de la tabla de renglones
+	colTalle := 330.0
+	colCantidad := 400.0
+	colPrecio := 480.0
+
+	encabezado := func() float64 {
+		pdf.AddPage()
+		pdf.SetFont(true, 18)
+		pdf.Text(margen, 60, "VARTAN SPORT")
+		pdf.SetFont(true, 14)
+		pdf.TextRight(derecha, 55, fmt.Sprintf("PRESUPUESTO N° %06d", p.ID))
+		pdf.SetFont(false, 10)
+		pdf.TextRight(derecha, 72, "Fecha: "+utils.FormatoFecha(p.FechaCreacion))
+		pdf.TextRight(derecha, 86, "Válido hasta: "+utils.FormatoFecha(p.FechaValidez))
+		pdf.Line(margen, 98, derecha, 98, 1)
+
+		// Tabla
+		y := 190.0
+		pdf.Rect(margen, y-14, derecha-margen, 20, true, 0.9)
+		pdf.SetFont(true, 10)
+		pdf.Text(margen+6, y, "Producto")
+		pdf.Text(colTalle, y, "Talle / Color")
+		pdf.TextRight(colCantidad+30, y, "Cant.")
+		pdf.TextRight(colPrecio+10, y, "P. Unitario")
+		pdf.TextRight(derecha-6, y, "Subtotal")
+		pdf.SetFont(false, 10)
+		return y + 22
+	}
+
+	y := encabezado()
+
+	// Datos del cliente (solo en la primera página)
+	pdf.SetFont(true, 11)
+	pdf.Text(margen, 120, "Cliente: "+p.Cliente.Nombre)
+	pdf.SetFont(false, 10)
+	linea := 136.0
+	for _, dato := range []string{p.Cliente.Telefono, p.Cliente.Email, direccionCliente(p.Cliente)} {
+		if dato != "" {
+			pdf.Text(margen, linea, dato)
+			linea += 13
+		}
+	}
+	pdf.TextRight(derecha, 120, "Vendedor: "+p.Usuario.Nombre)
+
+	for _, d := range p.Detalles {
+		if y > pdf.Alto-140 {
+			y = encabezado()
+		}
+		pdf.Text(margen+6, y, pdf.TruncateText(d.Producto.Nombre, colTalle-margen-16))
+		talle := d.Talle
+		if d.Color != "" {
+			talle += " / " + d.Color
+		}
+		pdf.Text(colTalle, y, pdf.TruncateText(talle, colCantidad-colTalle-40))
+		pdf.TextRight(colCantidad+30, y, strconv.Itoa(d.Cantidad))
+		pdf.TextRight(colPrecio+10, y, utils.FormatoMoneda(d.PrecioUnitario))
+		pdf.TextRight(derecha-6, y, utils.FormatoMoneda(d.Subtotal))
+		pdf.Line(margen, y+6, derecha, y+6, 0.3)
+		y += 20
+	}
+
+	// Totales
+	y += 10
+	pdf.Text(colPrecio-60, y, "Subtotal")
+	pdf.TextRight(derecha-6, y, utils.FormatoMoneda(p.Total))
+	if p.Descuento > 0 {
+		y += 16
+		pdf.Text(colPrecio-60, y, "Descuento")
+		pdf.TextRight(derecha-6, y, "- "+utils.FormatoMoneda(p.Descuento))
+	}
+	y += 20
+	pdf.SetFont(true, 12)
+	pdf.Text(colPrecio-60, y, "TOTAL")
+	pdf.TextRight(derecha-6, y, utils.FormatoMoneda(p.TotalFinal))
+
+	pdf.SetFont(false, 9)
+	if p.Observaciones != nil && *p.Observaciones != "" {
+		y += 30
+		pdf.Text(margen, y, "Observaciones: "+pdf.TruncateText(*p.Observaciones, derecha-margen-80))
+	}
+
+	pdf.Text(margen, pdf.Alto-50, "Precios sujetos a disponibilidad de stock al momento de confirmar la compra.")
+	pdf.Text(margen, pdf.Alto-38, "Los descuentos por forma de pago se calculan al confirmar la venta.")
+
+	return pdf.Bytes()
+}
+
+// direccionCliente arma la dirección del cliente en una sola línea
+func direccionCliente(cliente models.Cliente) string {
+	direccion := cliente.Direccion
+	for _, parte := range []string{cliente.Ciudad, cliente.Provincia} {
+		if parte == "" {
+			continue
+		}
+		if direccion != "" {
+			direccion += ", "
+		}
+		direccion += parte
+	}
+	return direccion
+}
@@ -0,0 +1,370 @@
+package controllers
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"vartan-backend/config"
+	"vartan-backend/models"
+	"vartan-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	dirImagenesProducto = "uploads/productos"
+	urlImagenesProducto = "/imagenes/productos/"
+	maxTamanioImagen    = 8 * 1024 * 1024
+	maxPixelesImagen    = 40_000_000 // Evita decodificar imágenes gigantes
+	ladoMiniatura       = 400
+)
+
+// Tipos aceptados según el contenido del archivo (no la extensión) y la extensión con que se guardan
+var tiposImagenProducto = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+}
+
+// ordenImagenes ordena las imágenes con la principal primero
+func ordenImagenes(db *gorm.DB) *gorm.DB {
+	return db.Order("principal DESC, orden, id")
+}
+
+// UploadImagenesProducto godoc
+// @Summary Subir imágenes de producto
+// @Description Sube una o más fotos (JPG o PNG, hasta 8MB) a un producto, opcionalmente para un color. El tipo se valida por el contenido del archivo y se genera una miniatura. La primera imagen del producto queda como principal. Si alguna falla no se guarda ninguna (solo dueño).
+// @Tags Productos
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID del producto"
+// @Param imagenes formData file true "Imágenes (se puede repetir el campo)"
+// @Param color formData string false "Color al que corresponden las fotos"
+// @Success 201 {array} models.ProductoImagen
+// @Failure 400 {object} map[string]string "Archivo inválido"
+// @Failure 404 {object} map[string]string "Producto no encontrado"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/productos/{id}/imagenes [post]
+func UploadImagenesProducto(c *gin.Context) {
+	var producto models.Producto
+	if err := config.DB.First(&producto, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Producto no encontrado"})
+		return
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil || len(form.File["imagenes"]) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Debe enviar al menos una imagen en el campo imagenes"})
+		return
+	}
+
+	var colorImagen *string
+	if col := strings.TrimSpace(c.PostForm("color")); col != "" {
+		if err := validarTallesColores(config.DB, nil, nil, []models.ColorEnum{models.ColorEnum(col)}); err != nil {
+			responderError(c, err, "Error al validar color")
+			return
+		}
+		colorImagen = &col
+	}
+
+	if err := os.MkdirAll(dirImagenesProducto, os.ModePerm); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al crear directorio de imágenes"})
+		return
+	}
+
+	// Primero se validan y guardan todos los archivos; si alguno falla se borran los ya escritos
+	// y no se registra ninguna imagen
+	creadas := []models.ProductoImagen{}
+	descartar := func() {
+		for _, imagen := range creadas {
+			borrarArchivosImagen(imagen)
+		}
+	}
+	for _, archivo := range form.File["imagenes"] {
+		if archivo.Size > maxTamanioImagen {
+			descartar()
+			c.JSON(http.StatusBadRequest, gin.H{"error": archivo.Filename + ": la imagen no puede superar los 8MB"})
+			return
+		}
+
+		f, err := archivo.Open()
+		if err != nil {
+			descartar()
+			c.JSON(http.StatusBadRequest, gin.H{"error": archivo.Filename + ": no se pudo leer el archivo"})
+			return
+		}
+		data, err := io.ReadAll(io.LimitReader(f, maxTamanioImagen+1))
+		f.Close()
+		if err != nil || len(data) > maxTamanioImagen {
+			descartar()
+			c.JSON(http.StatusBadRequest, gin.H{"error": archivo.Filename + ": no se pudo leer el archivo"})
+			return
+		}
+
+		imagen, err := guardarImagenProducto(data, producto.ID)
+		if err != nil {
+			descartar()
+			c.JSON(http.StatusBadRequest, gin.H{"error": archivo.Filename + ": " + err.Error()})
+			return
+		}
+		imagen.ProductoID = producto.ID
+		imagen.Color = colorImagen
+		creadas = append(creadas, imagen)
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		var siguiente int
+		if err := tx.Model(&models.ProductoImagen{}).Where("producto_id = ?", producto.ID).Select("COALESCE(MAX(orden), 0)").Scan(&siguiente).Error; err != nil {
+			return err
+		}
+		var principales int64
+		if err := tx.Model(&models.ProductoImagen{}).Where("producto_id = ? AND principal = ?", producto.ID, true).Count(&principales).Error; err != nil {
+			return err
+		}
+		for i := range creadas {
+			creadas[i].Orden = siguiente + i + 1
+			creadas[i].Principal = principales == 0 && i == 0
+		}
+		return tx.Create(&creadas).Error
+	})
+	if err != nil {
+		descartar()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al guardar imágenes"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, creadas)
+}
+
+// guardarImagenProducto valida el contenido, guarda el original y su miniatura en disco
+// y devuelve la imagen con las URLs y medidas completas
+func guardarImagenProducto(data []byte, productoID int) (models.ProductoImagen, error) {
+	var imagen models.ProductoImagen
+
+	ext, ok := tiposImagenProducto[http.DetectContentType(data)]
+	if !ok {
+		return imagen, fmt.Errorf("solo se permiten imágenes JPG o PNG")
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return imagen, fmt.Errorf("la imagen está dañada")
+	}
+	if cfg.Width*cfg.Height > maxPixelesImagen {
+		return imagen, fmt.Errorf("la imagen supera los 40 megapíxeles")
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return imagen, fmt.Errorf("la imagen está dañada")
+	}
+
+	// Las transparencias de los PNG quedan sobre fondo blanco en la miniatura JPG
+	mini := utils.Miniatura(img, ladoMiniatura)
+	fondo := image.NewRGBA(mini.Bounds())
+	draw.Draw(fondo, fondo.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(fondo, fondo.Bounds(), mini, mini.Bounds().Min, draw.Over)
+
+	base := fmt.Sprintf("producto_%d_%d", productoID, time.Now().UnixNano())
+	original := base + ext
+	miniatura := base + "_mini.jpg"
+
+	if err := os.WriteFile(filepath.Join(dirImagenesProducto, original), data, 0o644); err != nil {
+		return imagen, fmt.Errorf("no se pudo guardar la imagen")
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, fondo, &jpeg.Options{Quality: 80}); err != nil {
+		os.Remove(filepath.Join(dirImagenesProducto, original))
+		return imagen, fmt.Errorf("no se pudo generar la miniatura")
+	}
+	if err := os.WriteFile(filepath.Join(dirImagenesProducto, miniatura), buf.Bytes(), 0o644); err != nil {
+		os.Remove(filepath.Join(dirImagenesProducto, original))
+		return imagen, fmt.Errorf("no se pudo guardar la miniatura")
+	}
+
+	imagen.URL = urlImagenesProducto + original
+	imagen.MiniaturaURL = urlImagenesProducto + miniatura
+	imagen.Ancho = cfg.Width
+	imagen.Alto = cfg.Height
+	return imagen, nil
+}
+
+// borrarArchivosImagen elimina del disco el original y la miniatura
+func borrarArchivosImagen(imagen models.ProductoImagen) {
+	os.Remove(filepath.Join(dirImagenesProducto, filepath.Base(imagen.URL)))
+	os.Remove(filepath.Join(dirImagenesProducto, filepath.Base(imagen.MiniaturaURL)))
+}
+
+// GetImagenProductoArchivo godoc
+// @Summary Archivo de imagen de producto
+// @Description Devuelve la imagen o miniatura de un producto. Es pública para poder mostrarla y compartirla.
+// @Tags Productos
+// @Produce image/jpeg
+// @Param archivo path string true "Nombre del archivo"
+// @Success 200 {file} file "Imagen"
+// @Failure 404 {object} map[string]string "Imagen no encontrada"
+// @Router /imagenes/productos/{archivo} [get]
+func GetImagenProductoArchivo(c *gin.Context) {
+	ruta := filepath.Join(dirImagenesProducto, filepath.Base(c.Param("archivo")))
+	if _, err := os.Stat(ruta); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Imagen no encontrada"})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=604800")
+	c.File(ruta)
+}
+
+// OrdenarImagenesProducto godoc
+// @Summary Reordenar imágenes de producto
+// @Description Define el orden de todas las imágenes del producto; la primera queda como principal (solo dueño)
+// @Tags Productos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID del producto"
+// @Param request body models.ProductoImagenesOrdenRequest true "IDs de las imágenes en el orden deseado"
+// @Success 200 {array} models.ProductoImagen
+// @Failure 400 {object} map[string]string "Datos inválidos"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/productos/{id}/imagenes/orden [put]
+func OrdenarImagenesProducto(c *gin.Context) {
+	productoID := c.Param("id")
+
+	var req models.ProductoImagenesOrdenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+
+	// La lista tiene que ser una permutación de las imágenes del producto
+	var actuales []int
+	config.DB.Model(&models.ProductoImagen{}).Where("producto_id = ?", productoID).Pluck("id", &actuales)
+	pendientes := make(map[int]bool, len(actuales))
+	for _, id := range actuales {
+		pendientes[id] = true
+	}
+	for _, id := range req.ImagenIDs {
+		if !pendientes[id] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Debe indicar todas las imágenes del producto, sin repetir"})
+			return
+		}
+		delete(pendientes, id)
+	}
+	if len(pendientes) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Debe indicar todas las imágenes del producto, sin repetir"})
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		for i, id := range req.ImagenIDs {
+			if err := tx.Model(&models.ProductoImagen{}).Where("id = ?", id).
+				Updates(map[string]interface{}{"orden": i + 1, "principal": i == 0}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al ordenar imágenes"})
+		return
+	}
+
+	responderImagenesProducto(c, productoID)
+}
+
+// SetImagenPrincipalProducto godoc
+// @Summary Elegir imagen principal
+// @Description Marca una imagen como principal del producto (solo dueño)
+// @Tags Productos
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID del producto"
+// @Param imagenId path int true "ID de la imagen"
+// @Success 200 {array} models.ProductoImagen
+// @Failure 404 {object} map[string]string "Imagen no encontrada"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/productos/{id}/imagenes/{imagenId}/principal [put]
+func SetImagenPrincipalProducto(c *gin.Context) {
+	productoID := c.Param("id")
+
+	var imagen models.ProductoImagen
+	if err := config.DB.Where("id = ? AND producto_id = ?", c.Param("imagenId"), productoID).First(&imagen).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Imagen no encontrada"})
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.ProductoImagen{}).Where("producto_id = ?", imagen.ProductoID).Update("principal", false).Error; err != nil {
+			return err
+		}
+		return tx.Model(&imagen).Update("principal", true).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar imagen principal"})
+		return
+	}
+
+	responderImagenesProducto(c, productoID)
+}
+
+// DeleteImagenProducto godoc
+// @Summary Eliminar imagen de producto
+// @Description Elimina una imagen y sus archivos. Si era la principal pasa a serlo la siguiente (solo dueño).
+// @Tags Productos
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID del producto"
+// @Param imagenId path int true "ID de la imagen"
+// @Success 200 {array} models.ProductoImagen
+// @Failure 404 {object} map[string]string "Imagen no encontrada"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/productos/{id}/imagenes/{imagenId} [delete]
+func DeleteImagenProducto(c *gin.Context) {
+	productoID := c.Param("id")
+
+	var imagen models.ProductoImagen
+	if err := config.DB.Where("id = ? AND producto_id = ?", c.Param("imagenId"), productoID).First(&imagen).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Imagen no encontrada"})
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&imagen).Error; err != nil {
+			return err
+		}
+		if !imagen.Principal {
+			return nil
+		}
+		var siguiente models.ProductoImagen
+		if err := ordenImagenes(tx.Where("producto_id = ?", imagen.ProductoID)).First(&siguiente).Error; err != nil {
+			return nil // Era la única imagen
+		}
+		return tx.Model(&siguiente).Update("principal", true).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al eliminar imagen"})
+		return
+	}
+	borrarArchivosImagen(imagen)
+
+	responderImagenesProducto(c, productoID)
+}
+
+// responderImagenesProducto devuelve las imágenes del producto en orden
+func responderImagenesProducto(c *gin.Context, productoID string) {
+	imagenes := []models.ProductoImagen{}
+	ordenImagenes(config.DB.Where("producto_id = ?", productoID)).Find(&imagenes)
+	c.JSON(http.StatusOK, imagenes)
+}
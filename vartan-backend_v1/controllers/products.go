@@ -1,58 +1,199 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"vartan-backend/config"
 	"vartan-backend/models"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // GetProductos godoc
 // @Summary Listar productos
-// @Description Obtiene todos los productos activos con stock total
+// @Description Obtiene los productos activos con stock total, filtrados y paginados
 // @Tags Productos
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {array} models.ProductoResponse
+// @Param q query string false "Busca en nombre, equipo y tipo de producto"
+// @Param equipo_id query int false "Filtrar por equipo"
+// @Param tipo_producto_id query int false "Filtrar por tipo de producto"
+// @Param talle query string false "Solo productos con stock en el talle"
+// @Param color query string false "Solo productos con stock en el color"
+// @Param precio_min query number false "Precio de venta mínimo"
+// @Param precio_max query number false "Precio de venta máximo"
+// @Param con_stock query bool false "Solo productos con stock"
+// @Param ordenar_por query string false "nombre (por defecto), precio_venta, costo_unitario, stock_total o fecha_creacion"
+// @Param orden query string false "asc (por defecto) o desc"
+// @Param page query int false "Página (por defecto 1)"
+// @Param limit query int false "Resultados por página (por defecto 50, máximo 500)"
+// @Success 200 {object} map[string]interface{} "productos, total, page, limit"
+// @Failure 400 {object} map[string]string "Parámetro inválido"
 // @Failure 500 {object} map[string]string "Error interno"
 // @Router /api/productos [get]
 func GetProductos(c *gin.Context) {
-	var productos []models.Producto
+	query, err := filtrarProductos(c, config.DB.Model(&models.Producto{}).Where("activo = ?", true))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Ordenamiento
+	columna, ok := ordenesProducto[c.DefaultQuery("ordenar_por", "nombre")]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parámetro inválido: ordenar_por"})
+		return
+	}
+	direccion := "ASC"
+	if strings.EqualFold(c.Query("orden"), "desc") {
+		direccion = "DESC"
+	}
+
+	// Paginación
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit < 1 || limit > 500 {
+		limit = 50
+	}
 
-	if err := config.DB.Preload("TipoProducto").Preload("Equipo").Where("activo = ?", true).Find(&productos).Error; err != nil {
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener productos"})
 		return
 	}
 
+	var productos []models.Producto
+	if err := query.
+		Preload("TipoProducto").
+		Preload("Equipo").
+		Preload("Imagenes", ordenImagenes).
+		Order(columna + " " + direccion).
+		Order("id " + direccion).
+		Limit(limit).
+		Offset((page - 1) * limit).
+		Find(&productos).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener productos"})
+		return
+	}
+
+	// Stock total de la página en una sola consulta agrupada
+	ids := make([]int, len(productos))
+	for i, p := range productos {
+		ids[i] = p.ID
+	}
+	var totales []struct {
+		ProductoID int
+		Total      int
+	}
+	if len(ids) > 0 {
+		if err := config.DB.Model(&models.ProductoStock{}).
+			Select("producto_id, COALESCE(SUM(cantidad), 0) AS total").
+			Where("producto_id IN ?", ids).
+			Group("producto_id").
+			Scan(&totales).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener stock"})
+			return
+		}
+	}
+	stockPorProducto := make(map[int]int, len(totales))
+	for _, t := range totales {
+		stockPorProducto[t.ProductoID] = t.Total
+	}
+
 	// Construir respuesta con stock total
-	var response []models.ProductoResponse
+	response := []models.ProductoResponse{}
 	for _, p := range productos {
-		var stockTotal int
-		config.DB.Model(&models.ProductoStock{}).
-			Where("producto_id = ?", p.ID).
-			Select("COALESCE(SUM(cantidad), 0)").
-			Scan(&stockTotal)
-
 		response = append(response, models.ProductoResponse{
 			ID:                 p.ID,
 			Nombre:             p.Nombre,
 			CostoUnitario:      p.CostoUnitario,
+			PrecioVenta:        p.PrecioVenta,
 			Activo:             p.Activo,
 			FechaCreacion:      p.FechaCreacion,
 			TallesDisponibles:  p.TallesDisponibles,
 			ColoresDisponibles: p.ColoresDisponibles,
-			StockTotal:         stockTotal,
+			StockTotal:         stockPorProducto[p.ID],
 			TipoProductoID:     p.TipoProductoID,
 			TipoProducto:       p.TipoProducto,
 			EquipoID:           p.EquipoID,
 			Equipo:             p.Equipo,
+			Imagenes:           p.Imagenes,
 		})
 	}
 
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, gin.H{
+		"productos": response,
+		"total":     total,
+		"page":      page,
+		"limit":     limit,
+	})
+}
+
+// Columnas por las que se puede ordenar el catálogo
+var ordenesProducto = map[string]string{
+	"nombre":         "nombre",
+	"precio_venta":   "precio_venta",
+	"costo_unitario": "costo_unitario",
+	"fecha_creacion": "fecha_creacion",
+	"stock_total":    "(SELECT COALESCE(SUM(s.cantidad), 0) FROM producto_stocks s WHERE s.producto_id = productos.id)",
+}
+
+// filtrarProductos aplica a la consulta los filtros del catálogo recibidos por query string
+func filtrarProductos(c *gin.Context, query *gorm.DB) (*gorm.DB, error) {
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		patron := "%" + q + "%"
+		query = query.Where(
+			"nombre ILIKE ? OR equipo_id IN (SELECT id FROM equipos WHERE nombre ILIKE ?) OR tipo_producto_id IN (SELECT id FROM tipo_productos WHERE nombre ILIKE ?)",
+			patron, patron, patron)
+	}
+
+	for _, campo := range []string{"equipo_id", "tipo_producto_id"} {
+		if valor := c.Query(campo); valor != "" {
+			id, err := strconv.Atoi(valor)
+			if err != nil {
+				return nil, fmt.Errorf("Parámetro inválido: %s", campo)
+			}
+			query = query.Where(campo+" = ?", id)
+		}
+	}
+
+	if valor := c.Query("precio_min"); valor != "" {
+		precio, err := strconv.ParseFloat(valor, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Parámetro inválido: precio_min")
+		}
+		query = query.Where("precio_venta >= ?", precio)
+	}
+	if valor := c.Query("precio_max"); valor != "" {
+		precio, err := strconv.ParseFloat(valor, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Parámetro inválido: precio_max")
+		}
+		query = query.Where("precio_venta <= ?", precio)
+	}
+
+	// Talle y color se filtran sobre el stock disponible; si vienen los dos, en la misma variante
+	talle, color := c.Query("talle"), c.Query("color")
+	if talle != "" || color != "" || c.Query("con_stock") == "true" {
+		stock := config.DB.Table("producto_stocks s").Select("1").Where("s.producto_id = productos.id AND s.cantidad > 0")
+		if talle != "" {
+			stock = stock.Where("s.talle = ?", talle)
+		}
+		if color != "" {
+			stock = stock.Where("s.color = ?", color)
+		}
+		query = query.Where("EXISTS (?)", stock)
+	}
+
+	return query, nil
 }
 
 // GetProducto godoc
@@ -70,7 +211,7 @@ func GetProducto(c *gin.Context) {
 	id := c.Param("id")
 
 	var producto models.Producto
-	if err := config.DB.Preload("TipoProducto").Preload("Equipo").First(&producto, id).Error; err != nil {
+	if err := config.DB.Preload("TipoProducto").Preload("Equipo").Preload("Imagenes", ordenImagenes).First(&producto, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Producto no encontrado"})
 		return
 	}
@@ -98,25 +239,16 @@ func CreateProducto(c *gin.Context) {
 		return
 	}
 
-	// Validar talles
-	for _, talle := range req.Talles {
-		if !models.TallesValidos[talle] {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Talle inválido: " + string(talle)})
-			return
-		}
-	}
-
-	// Validar colores
-	for _, color := range req.Colores {
-		if !models.ColoresValidos[color] {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Color inválido: " + string(color)})
-			return
-		}
+	// Validar talles y colores contra los catálogos
+	if err := validarTallesColores(config.DB, req.TipoProductoID, req.Talles, req.Colores); err != nil {
+		responderError(c, err, "Error al validar talles y colores")
+		return
 	}
 
 	producto := models.Producto{
 		Nombre:             req.Nombre,
 		CostoUnitario:      req.CostoUnitario,
+		PrecioVenta:        req.PrecioVenta,
 		Activo:             true,
 		TallesDisponibles:  models.TalleArray(req.Talles),
 		ColoresDisponibles: models.ColorArray(req.Colores),
@@ -164,6 +296,8 @@ func UpdateProducto(c *gin.Context) {
 		return
 	}
 
+	anterior := producto
+
 	// Actualizar nombre si se proporciona
 	if req.Nombre != "" {
 		producto.Nombre = req.Nombre
@@ -174,25 +308,28 @@ func UpdateProducto(c *gin.Context) {
 		producto.CostoUnitario = req.CostoUnitario
 	}
 
+	// Actualizar precio de venta si se proporciona
+	if req.PrecioVenta > 0 {
+		producto.PrecioVenta = req.PrecioVenta
+	}
+
+	// Validar los talles y colores nuevos contra los catálogos y el tipo resultante
+	tipoProductoID := producto.TipoProductoID
+	if req.TipoProductoID != nil {
+		tipoProductoID = req.TipoProductoID
+	}
+	if err := validarTallesColores(config.DB, tipoProductoID, req.Talles, req.Colores); err != nil {
+		responderError(c, err, "Error al validar talles y colores")
+		return
+	}
+
 	// Actualizar talles si se proporcionan
 	if req.Talles != nil {
-		for _, talle := range req.Talles {
-			if !models.TallesValidos[talle] {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "Talle inválido: " + string(talle)})
-				return
-			}
-		}
 		producto.TallesDisponibles = models.TalleArray(req.Talles)
 	}
 
 	// Actualizar colores si se proporcionan
 	if req.Colores != nil {
-		for _, color := range req.Colores {
-			if !models.ColoresValidos[color] {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "Color inválido: " + string(color)})
-				return
-			}
-		}
 		producto.ColoresDisponibles = models.ColorArray(req.Colores)
 	}
 
@@ -211,7 +348,13 @@ func UpdateProducto(c *gin.Context) {
 		producto.EquipoID = req.EquipoID
 	}
 
-	if err := config.DB.Save(&producto).Error; err != nil {
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&producto).Error; err != nil {
+			return err
+		}
+		return registrarCambioPrecio(tx, anterior, producto.CostoUnitario, producto.PrecioVenta, models.CambioPrecioManual, "", c.GetInt("user_id"))
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar producto"})
 		return
 	}
@@ -289,7 +432,7 @@ func GetStockByProducto(c *gin.Context) {
 	productoID := c.Param("id")
 
 	var stock []models.ProductoStock
-	if err := config.DB.Where("producto_id = ?", productoID).Find(&stock).Error; err != nil {
+	if err := config.DB.Preload("Variante").Where("producto_id = ?", productoID).Find(&stock).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener stock"})
 		return
 	}
@@ -324,20 +467,10 @@ func AddStock(c *gin.Context) {
 		return
 	}
 
-	// Validar talles
-	for _, talle := range req.Talles {
-		if !models.TallesValidos[talle] {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Talle inválido: " + string(talle)})
-			return
-		}
-	}
-
-	// Validar colores
-	for _, color := range req.Colores {
-		if !models.ColoresValidos[color] {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Color inválido: " + string(color)})
-			return
-		}
+	// Validar talles y colores contra los catálogos y el tipo del producto
+	if err := validarTallesColores(config.DB, producto.TipoProductoID, req.Talles, req.Colores); err != nil {
+		responderError(c, err, "Error al validar talles y colores")
+		return
 	}
 
 	// Crear registros por cada combinación talle + color
@@ -345,29 +478,10 @@ func AddStock(c *gin.Context) {
 
 	for _, talle := range req.Talles {
 		for _, color := range req.Colores {
-			// Buscar si ya existe stock para esa combinación
-			var stock models.ProductoStock
-			result := config.DB.Where("producto_id = ? AND talle = ? AND color = ?", req.ProductoID, talle, color).First(&stock)
-
-			if result.Error != nil {
-				// No existe, crear nuevo
-				stock = models.ProductoStock{
-					ProductoID: req.ProductoID,
-					Talle:      talle,
-					Color:      color,
-					Cantidad:   req.Cantidad,
-				}
-				if err := config.DB.Create(&stock).Error; err != nil {
-					c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al crear stock"})
-					return
-				}
-			} else {
-				// Ya existe, sumar cantidad
-				stock.Cantidad += req.Cantidad
-				if err := config.DB.Save(&stock).Error; err != nil {
-					c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar stock"})
-					return
-				}
+			stock, _, err := sumarStock(config.DB, req.ProductoID, talle, color, req.Cantidad)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar stock"})
+				return
 			}
 			stocksCreados = append(stocksCreados, stock)
 		}
@@ -382,15 +496,47 @@ func AddStock(c *gin.Context) {
 	c.JSON(http.StatusCreated, response)
 }
 
+// sumarStock suma la cantidad a la combinación talle + color del producto, creando el registro
+// y su variante (SKU y código de barras) si no existían. Devuelve si el registro es nuevo.
+func sumarStock(db *gorm.DB, productoID int, talle models.TalleEnum, color models.ColorEnum, cantidad int) (models.ProductoStock, bool, error) {
+	var stock models.ProductoStock
+	nuevo := false
+	err := db.Where("producto_id = ? AND talle = ? AND color = ?", productoID, talle, color).First(&stock).Error
+	switch {
+	case err == nil:
+		// Ya existe, sumar cantidad
+		if err := db.Model(&stock).Update("cantidad", gorm.Expr("cantidad + ?", cantidad)).Error; err != nil {
+			return stock, false, err
+		}
+		stock.Cantidad += cantidad
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		// No existe, crear nuevo
+		stock = models.ProductoStock{ProductoID: productoID, Talle: talle, Color: color, Cantidad: cantidad}
+		if err := db.Create(&stock).Error; err != nil {
+			return stock, false, err
+		}
+		nuevo = true
+	default:
+		return stock, false, err
+	}
+
+	variante, err := asegurarVariante(db, stock)
+	if err != nil {
+		return stock, nuevo, err
+	}
+	stock.Variante = &variante
+	return stock, nuevo, nil
+}
+
 // UpdateStock godoc
 // @Summary Actualizar stock
-// @Description Actualiza la cantidad de stock (solo dueño)
+// @Description Corrige la cantidad de stock y registra el ajuste como movimiento (solo dueño)
 // @Tags Stock
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "ID del stock"
-// @Param request body object true "Cantidad de stock" example({"cantidad": 10})
+// @Param request body object true "Cantidad de stock y motivo opcional" example({"cantidad": 10, "motivo": "Rotura"})
 // @Success 200 {object} models.ProductoStock
 // @Failure 400 {object} map[string]string "Datos inválidos"
 // @Failure 404 {object} map[string]string "Stock no encontrado"
@@ -406,7 +552,8 @@ func UpdateStock(c *gin.Context) {
 	}
 
 	var req struct {
-		Cantidad int `json:"cantidad" binding:"required"`
+		Cantidad int    `json:"cantidad" binding:"required"`
+		Motivo   string `json:"motivo"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -414,12 +561,21 @@ func UpdateStock(c *gin.Context) {
 		return
 	}
 
-	stock.Cantidad = req.Cantidad
+	if req.Motivo == "" {
+		req.Motivo = "Ajuste manual"
+	}
 
-	if err := config.DB.Save(&stock).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar stock"})
-		return
+	// La corrección queda registrada como movimiento de stock
+	if diferencia := req.Cantidad - stock.Cantidad; diferencia != 0 {
+		err := config.DB.Transaction(func(tx *gorm.DB) error {
+			return ajustarStock(tx, stock.ID, diferencia, models.MovimientoStockAjusteManual, req.Motivo, nil, c.GetInt("user_id"))
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar stock"})
+			return
+		}
 	}
 
+	config.DB.First(&stock, stock.ID)
 	c.JSON(http.StatusOK, stock)
 }
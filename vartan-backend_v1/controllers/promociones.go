@@ -0,0 +1,385 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+	"vartan-backend/config"
+	"vartan-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetPromociones godoc
+// @Summary Listar promociones
+// @Description Obtiene las promociones y cupones vigentes. Con todos=true incluye las inactivas, vencidas o agotadas.
+// @Tags Promociones
+// @Produce json
+// @Security BearerAuth
+// @Param todos query bool false "Incluir no vigentes"
+// @Success 200 {array} models.Promocion
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/promociones [get]
+func GetPromociones(c *gin.Context) {
+	var promociones []models.Promocion
+	if err := config.DB.Order("nombre, id").Find(&promociones).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener promociones"})
+		return
+	}
+
+	resultado := []models.Promocion{}
+	hoy := time.Now()
+	for _, p := range promociones {
+		if c.Query("todos") == "true" || p.Vigente(hoy) {
+			resultado = append(resultado, p)
+		}
+	}
+
+	c.JSON(http.StatusOK, resultado)
+}
+
+// CreatePromocion godoc
+// @Summary Crear promoción
+// @Description Crea una promoción automática o, si se indica código, un cupón. Tipos: porcentaje (valor = %), monto (valor = $ por unidad), lleva_paga (lleva cantidad y paga cantidad_paga, p. ej. 2x1) y precio_fijo (cantidad unidades por valor). Se puede limitar a un producto, equipo o tipo de producto (solo dueño).
+// @Tags Promociones
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.PromocionCreateRequest true "Datos de la promoción"
+// @Success 201 {object} models.Promocion
+// @Failure 400 {object} map[string]string "Datos inválidos o código existente"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/promociones [post]
+func CreatePromocion(c *gin.Context) {
+	var req models.PromocionCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+
+	promocion := models.Promocion{
+		Nombre:         strings.TrimSpace(req.Nombre),
+		Tipo:           req.Tipo,
+		Valor:          req.Valor,
+		Cantidad:       req.Cantidad,
+		CantidadPaga:   req.CantidadPaga,
+		ProductoID:     req.ProductoID,
+		EquipoID:       req.EquipoID,
+		TipoProductoID: req.TipoProductoID,
+		FechaDesde:     req.FechaDesde,
+		FechaHasta:     req.FechaHasta,
+		UsosMaximos:    req.UsosMaximos,
+		Activo:         true,
+	}
+	if codigo := normalizarCupon(req.Codigo); codigo != "" {
+		if len(codigo) > 30 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "El código no puede superar los 30 caracteres"})
+			return
+		}
+		var count int64
+		config.DB.Model(&models.Promocion{}).Where("codigo = ?", codigo).Count(&count)
+		if count > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Ya existe el cupón " + codigo})
+			return
+		}
+		promocion.Codigo = &codigo
+	}
+	if msg := validarPromocion(promocion); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	if err := config.DB.Create(&promocion).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al crear promoción"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, promocion)
+}
+
+// UpdatePromocion godoc
+// @Summary Actualizar promoción
+// @Description Modifica el nombre, valor, vigencia, límite de usos o estado de una promoción. El tipo y el alcance no se cambian: se crea otra promoción (solo dueño).
+// @Tags Promociones
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID de la promoción"
+// @Param request body models.PromocionUpdateRequest true "Datos a actualizar"
+// @Success 200 {object} models.Promocion
+// @Failure 400 {object} map[string]string "Datos inválidos"
+// @Failure 404 {object} map[string]string "Promoción no encontrada"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/promociones/{id} [put]
+func UpdatePromocion(c *gin.Context) {
+	var promocion models.Promocion
+	if err := config.DB.First(&promocion, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Promoción no encontrada"})
+		return
+	}
+
+	var req models.PromocionUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+
+	if nombre := strings.TrimSpace(req.Nombre); nombre != "" {
+		promocion.Nombre = nombre
+	}
+	if req.Valor != nil {
+		promocion.Valor = *req.Valor
+	}
+	if req.FechaDesde != nil {
+		promocion.FechaDesde = req.FechaDesde
+	}
+	if req.FechaHasta != nil {
+		promocion.FechaHasta = req.FechaHasta
+	}
+	if req.UsosMaximos != nil {
+		promocion.UsosMaximos = req.UsosMaximos
+	}
+	if req.Activo != nil {
+		promocion.Activo = *req.Activo
+	}
+	if msg := validarPromocion(promocion); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	if err := config.DB.Save(&promocion).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar promoción"})
+		return
+	}
+
+	c.JSON(http.StatusOK, promocion)
+}
+
+// SimularPromociones godoc
+// @Summary Simular promociones
+// @Description Calcula las promociones y el cupón que se aplicarían a los renglones, sin registrar la venta ni consumir usos. No incluye el descuento por forma de pago.
+// @Tags Promociones
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.SimularPromocionesRequest true "Renglones y cupón"
+// @Success 200 {object} models.SimulacionPromocionesResponse
+// @Failure 400 {object} map[string]string "Datos inválidos, stock inexistente o cupón inválido"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/promociones/simular [post]
+func SimularPromociones(c *gin.Context) {
+	var req models.SimularPromocionesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+
+	// Se usa una transacción descartada porque la identificación de los renglones bloquea el stock
+	tx := config.DB.Begin()
+	defer tx.Rollback()
+
+	renglones, err := identificarRenglones(tx, req.Detalles)
+	if err != nil {
+		responderError(c, err, "Error al simular promociones")
+		return
+	}
+	asignadas, err := aplicarPromociones(tx, renglones, req.Cupon, time.Now())
+	if err != nil {
+		responderError(c, err, "Error al simular promociones")
+		return
+	}
+
+	var promociones []models.Promocion
+	tx.Where("id IN ?", idsPromociones(asignadas)).Find(&promociones)
+	nombres := map[int]string{}
+	for _, p := range promociones {
+		nombres[p.ID] = p.Nombre
+	}
+
+	resultado := models.SimulacionPromocionesResponse{Detalles: make([]models.DetalleSimulacion, len(renglones))}
+	for i, r := range renglones {
+		detalle := models.DetalleSimulacion{Subtotal: redondear(r.detalle.PrecioUnitario * float64(r.detalle.Cantidad))}
+		if a := asignadas[i]; a != nil {
+			id := a.PromocionID
+			detalle.PromocionID = &id
+			detalle.Promocion = nombres[id]
+			detalle.Descuento = a.Descuento
+		}
+		resultado.Total += detalle.Subtotal
+		resultado.DescuentoPromociones += detalle.Descuento
+		resultado.Detalles[i] = detalle
+	}
+	resultado.Total = redondear(resultado.Total)
+	resultado.DescuentoPromociones = redondear(resultado.DescuentoPromociones)
+
+	c.JSON(http.StatusOK, resultado)
+}
+
+// validarPromocion controla que los valores tengan sentido para el tipo de promoción
+func validarPromocion(p models.Promocion) string {
+	switch p.Tipo {
+	case models.PromocionPorcentaje:
+		if p.Valor <= 0 || p.Valor > 100 {
+			return "El porcentaje debe estar entre 0 y 100"
+		}
+	case models.PromocionMonto:
+		if p.Valor <= 0 {
+			return "El monto de descuento debe ser mayor a cero"
+		}
+	case models.PromocionLlevaPaga:
+		if p.Cantidad < 2 || p.CantidadPaga < 1 || p.CantidadPaga >= p.Cantidad {
+			return "Indique cuántas unidades se llevan (2 o más) y cuántas se pagan (menos que las que se llevan)"
+		}
+	case models.PromocionPrecioFijo:
+		if p.Cantidad < 2 || p.Valor <= 0 {
+			return "Indique la cantidad de unidades (2 o más) y el precio del grupo"
+		}
+	}
+	if p.FechaDesde != nil && p.FechaHasta != nil && p.FechaHasta.Before(*p.FechaDesde) {
+		return "La fecha hasta no puede ser anterior a la fecha desde"
+	}
+	if p.UsosMaximos != nil && *p.UsosMaximos <= 0 {
+		return "El límite de usos debe ser mayor a cero"
+	}
+	return ""
+}
+
+func normalizarCupon(codigo string) string {
+	return strings.ToUpper(strings.TrimSpace(codigo))
+}
+
+// renglonVenta - Renglón pedido con el stock y el producto que le corresponden
+type renglonVenta struct {
+	detalle  models.VentaDetalleCreateRequest
+	stock    models.ProductoStock
+	producto models.Producto
+	comboID  *int
+}
+
+// identificarRenglones busca (y bloquea) el stock de cada renglón y los datos del producto
+// que usan los costos y las promociones. Controla que las cantidades sean positivas y que
+// alcance el stock, sumando los renglones de la misma variante.
+func identificarRenglones(tx *gorm.DB, detalles []models.VentaDetalleCreateRequest) ([]renglonVenta, error) {
+	renglones := make([]renglonVenta, 0, len(detalles))
+	pedidas := map[int]int{}
+	for _, d := range detalles {
+		if d.Cantidad <= 0 {
+			return nil, &ventaError{http.StatusBadRequest, "La cantidad de cada renglón debe ser mayor a cero"}
+		}
+		stock, err := stockParaVenta(tx, d)
+		if err != nil {
+			return nil, err
+		}
+		pedidas[stock.ID] += d.Cantidad
+		if pedidas[stock.ID] > stock.Cantidad {
+			return nil, &ventaError{http.StatusBadRequest, "Stock insuficiente"}
+		}
+		var producto models.Producto
+		if err := tx.Select("id", "costo_unitario", "equipo_id", "tipo_producto_id").First(&producto, stock.ProductoID).Error; err != nil {
+			return nil, &ventaError{http.StatusBadRequest, "Producto no encontrado"}
+		}
+		renglones = append(renglones, renglonVenta{detalle: d, stock: stock, producto: producto})
+	}
+	return renglones, nil
+}
+
+// aplicarPromociones elige la promoción de cada renglón entre las automáticas vigentes y el cupón
+// ingresado. Un cupón inválido, vencido o que no alcanza a ningún renglón es un error.
+func aplicarPromociones(tx *gorm.DB, renglones []renglonVenta, cupon string, fecha time.Time) ([]*models.PromocionLinea, error) {
+	var automaticas []models.Promocion
+	if err := tx.Where("activo = ? AND codigo IS NULL", true).Find(&automaticas).Error; err != nil {
+		return nil, err
+	}
+	promociones := make([]models.Promocion, 0, len(automaticas)+1)
+	for _, p := range automaticas {
+		if p.Vigente(fecha) {
+			promociones = append(promociones, p)
+		}
+	}
+
+	codigo := normalizarCupon(cupon)
+	cuponID := 0
+	if codigo != "" {
+		var p models.Promocion
+		if err := tx.Where("codigo = ?", codigo).First(&p).Error; err != nil || !p.Vigente(fecha) {
+			return nil, &ventaError{http.StatusBadRequest, "Cupón " + codigo + " inválido o vencido"}
+		}
+		promociones = append(promociones, p)
+		cuponID = p.ID
+	}
+
+	lineas := make([]models.LineaPromocion, len(renglones))
+	for i, r := range renglones {
+		lineas[i] = models.LineaPromocion{
+			ProductoID:     r.producto.ID,
+			EquipoID:       r.producto.EquipoID,
+			TipoProductoID: r.producto.TipoProductoID,
+			Cantidad:       r.detalle.Cantidad,
+			PrecioUnitario: r.detalle.PrecioUnitario,
+		}
+		// Los combos ya tienen su precio de paquete: sin unidades no entran en ninguna promoción
+		if r.comboID != nil {
+			lineas[i].Cantidad = 0
+		}
+	}
+	asignadas := models.AsignarPromociones(lineas, promociones)
+
+	if cuponID != 0 {
+		aplicado := false
+		for _, a := range asignadas {
+			aplicado = aplicado || a != nil && a.PromocionID == cuponID
+		}
+		if !aplicado {
+			return nil, &ventaError{http.StatusBadRequest, "El cupón " + codigo + " no aplica a los productos de la venta"}
+		}
+	}
+	return asignadas, nil
+}
+
+// registrarUsoPromociones suma un uso a cada promoción aplicada en la venta, controlando el límite
+// en la misma sentencia para que dos ventas simultáneas no lo superen
+func registrarUsoPromociones(tx *gorm.DB, asignadas []*models.PromocionLinea) error {
+	for _, id := range idsPromociones(asignadas) {
+		result := tx.Model(&models.Promocion{}).
+			Where("id = ? AND (usos_maximos IS NULL OR usos < usos_maximos)", id).
+			Update("usos", gorm.Expr("usos + 1"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return &ventaError{http.StatusBadRequest, "Una de las promociones alcanzó su límite de usos"}
+		}
+	}
+	return nil
+}
+
+// devolverUsoPromociones descuenta el uso de las promociones de una venta eliminada
+func devolverUsoPromociones(tx *gorm.DB, detalles []models.VentaDetalle) error {
+	ids := map[int]bool{}
+	for _, d := range detalles {
+		if d.PromocionID != nil {
+			ids[*d.PromocionID] = true
+		}
+	}
+	for id := range ids {
+		if err := tx.Model(&models.Promocion{}).Where("id = ?", id).
+			Update("usos", gorm.Expr("GREATEST(usos - 1, 0)")).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// idsPromociones devuelve las promociones distintas asignadas, en orden de aparición
+func idsPromociones(asignadas []*models.PromocionLinea) []int {
+	ids := []int{}
+	vistos := map[int]bool{}
+	for _, a := range asignadas {
+		if a != nil && !vistos[a.PromocionID] {
+			vistos[a.PromocionID] = true
+			ids = append(ids, a.PromocionID)
+		}
+	}
+	return ids
+}
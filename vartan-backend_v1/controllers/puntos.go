@@ -0,0 +1,343 @@
+package controllers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+	"vartan-backend/config"
+	"vartan-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetProgramaPuntos godoc
+// @Summary Configuración del programa de puntos
+// @Description Obtiene si el programa está activo, cuántos pesos hacen falta para ganar un punto, cuánto vale un punto al canjearlo y en cuántos días vencen
+// @Tags Puntos
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.ProgramaPuntos
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/programa-puntos [get]
+func GetProgramaPuntos(c *gin.Context) {
+	programa, err := programaPuntos(config.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener programa de puntos"})
+		return
+	}
+
+	c.JSON(http.StatusOK, programa)
+}
+
+// UpdateProgramaPuntos godoc
+// @Summary Configurar programa de puntos
+// @Description Activa o desactiva el programa y cambia las tasas de acumulación y canje o el vencimiento. Los cambios no afectan los puntos ya acreditados (solo dueño).
+// @Tags Puntos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.ProgramaPuntosRequest true "Configuración"
+// @Success 200 {object} models.ProgramaPuntos
+// @Failure 400 {object} map[string]string "Datos inválidos"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/programa-puntos [put]
+func UpdateProgramaPuntos(c *gin.Context) {
+	var req models.ProgramaPuntosRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+
+	programa, err := programaPuntos(config.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener programa de puntos"})
+		return
+	}
+
+	if req.Activo != nil {
+		programa.Activo = *req.Activo
+	}
+	if req.PesosPorPunto != nil {
+		programa.PesosPorPunto = *req.PesosPorPunto
+	}
+	if req.ValorPunto != nil {
+		programa.ValorPunto = *req.ValorPunto
+	}
+	if req.DiasVencimiento != nil {
+		programa.DiasVencimiento = *req.DiasVencimiento
+	}
+	if programa.PesosPorPunto <= 0 || programa.ValorPunto <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Indique los pesos por punto y el valor del punto"})
+		return
+	}
+
+	if err := config.DB.Save(&programa).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar programa de puntos"})
+		return
+	}
+
+	c.JSON(http.StatusOK, programa)
+}
+
+// GetPuntosCliente godoc
+// @Summary Puntos de un cliente
+// @Description Obtiene el saldo de puntos del cliente, su valor en pesos, el próximo vencimiento y el libro de movimientos (del más reciente al más antiguo). Antes de calcular se dan de baja los puntos vencidos.
+// @Tags Puntos
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID del cliente"
+// @Success 200 {object} models.PuntosClienteResponse
+// @Failure 404 {object} map[string]string "Cliente no encontrado"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/clientes/{id}/puntos [get]
+func GetPuntosCliente(c *gin.Context) {
+	var cliente models.Cliente
+	if err := config.DB.Scopes(conArchivados).First(&cliente, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cliente no encontrado"})
+		return
+	}
+
+	respuesta := models.PuntosClienteResponse{ClienteID: cliente.ID, Movimientos: []models.MovimientoPuntos{}}
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := vencerPuntos(tx, cliente.ID, c.GetInt("user_id")); err != nil {
+			return err
+		}
+		saldo, err := saldoPuntos(tx, cliente.ID)
+		if err != nil {
+			return err
+		}
+		respuesta.Saldo = saldo
+
+		var proximo models.MovimientoPuntos
+		err = tx.Where("cliente_id = ? AND restantes > 0 AND fecha_vencimiento IS NOT NULL", cliente.ID).
+			Order("fecha_vencimiento").Limit(1).Find(&proximo).Error
+		if err != nil {
+			return err
+		}
+		if proximo.ID != 0 {
+			respuesta.ProximoVencimiento = proximo.FechaVencimiento
+			if err := tx.Model(&models.MovimientoPuntos{}).
+				Select("COALESCE(SUM(restantes), 0)").
+				Where("cliente_id = ? AND restantes > 0 AND fecha_vencimiento = ?", cliente.ID, proximo.FechaVencimiento).
+				Scan(&respuesta.PuntosPorVencer).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Where("cliente_id = ?", cliente.ID).Order("fecha DESC, id DESC").Find(&respuesta.Movimientos).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener puntos del cliente"})
+		return
+	}
+
+	programa, err := programaPuntos(config.DB)
+	if err == nil {
+		respuesta.ValorEnPesos = redondear(float64(respuesta.Saldo) * programa.ValorPunto)
+	}
+
+	c.JSON(http.StatusOK, respuesta)
+}
+
+// programaPuntos devuelve la configuración del programa (inactivo si todavía no se cargó)
+func programaPuntos(db *gorm.DB) (models.ProgramaPuntos, error) {
+	var programa models.ProgramaPuntos
+	err := db.Order("id").Limit(1).Find(&programa).Error
+	return programa, err
+}
+
+// saldoPuntos suma el libro de puntos del cliente
+func saldoPuntos(db *gorm.DB, clienteID int) (int, error) {
+	var saldo int
+	err := db.Model(&models.MovimientoPuntos{}).
+		Select("COALESCE(SUM(puntos), 0)").
+		Where("cliente_id = ?", clienteID).
+		Scan(&saldo).Error
+	return saldo, err
+}
+
+// vencerPuntos da de baja los puntos acreditados cuya fecha de vencimiento ya pasó
+func vencerPuntos(tx *gorm.DB, clienteID, usuarioID int) error {
+	var vencidos []models.MovimientoPuntos
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("cliente_id = ? AND restantes > 0 AND fecha_vencimiento < ?", clienteID, time.Now()).
+		Find(&vencidos).Error; err != nil {
+		return err
+	}
+	for _, m := range vencidos {
+		if err := tx.Model(&m).Update("restantes", 0).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.MovimientoPuntos{
+			ClienteID: clienteID,
+			Tipo:      models.MovimientoPuntosVencimiento,
+			Puntos:    -m.Restantes,
+			UsuarioID: usuarioID,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// acreditarPuntos registra los puntos ganados por la venta con su vencimiento
+func acreditarPuntos(tx *gorm.DB, programa models.ProgramaPuntos, clienteID, puntos int, ventaID *int, tipo string, usuarioID int) error {
+	movimiento := models.MovimientoPuntos{
+		ClienteID: clienteID,
+		Tipo:      tipo,
+		Puntos:    puntos,
+		Restantes: puntos,
+		VentaID:   ventaID,
+		UsuarioID: usuarioID,
+	}
+	if programa.DiasVencimiento > 0 {
+		vence := time.Now().AddDate(0, 0, programa.DiasVencimiento)
+		movimiento.FechaVencimiento = &vence
+	}
+	return tx.Create(&movimiento).Error
+}
+
+// puntosGanados calcula los puntos que da un total final: uno cada PesosPorPunto, sin fracciones
+func puntosGanados(programa models.ProgramaPuntos, totalFinal float64) int {
+	if !programa.Activo || programa.PesosPorPunto <= 0 || totalFinal <= 0 {
+		return 0
+	}
+	return int(math.Floor(totalFinal/programa.PesosPorPunto + 1e-9))
+}
+
+// canjearPuntos descuenta los puntos del cliente usando primero los que vencen antes
+func canjearPuntos(tx *gorm.DB, clienteID, puntos, ventaID, usuarioID int) error {
+	if err := vencerPuntos(tx, clienteID, usuarioID); err != nil {
+		return err
+	}
+
+	var disponibles []models.MovimientoPuntos
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("cliente_id = ? AND restantes > 0", clienteID).
+		Order("fecha_vencimiento NULLS LAST, id").
+		Find(&disponibles).Error; err != nil {
+		return err
+	}
+	total := 0
+	for _, m := range disponibles {
+		total += m.Restantes
+	}
+	if total < puntos {
+		return &ventaError{http.StatusBadRequest, fmt.Sprintf("El cliente tiene %d puntos disponibles", total)}
+	}
+
+	pendientes := puntos
+	for _, m := range disponibles {
+		if pendientes == 0 {
+			break
+		}
+		usados := min(m.Restantes, pendientes)
+		if err := tx.Model(&m).Update("restantes", m.Restantes-usados).Error; err != nil {
+			return err
+		}
+		pendientes -= usados
+	}
+
+	return tx.Create(&models.MovimientoPuntos{
+		ClienteID: clienteID,
+		Tipo:      models.MovimientoPuntosCanje,
+		Puntos:    -puntos,
+		VentaID:   &ventaID,
+		UsuarioID: usuarioID,
+	}).Error
+}
+
+// anularPuntosVenta quita los puntos que la venta eliminada hizo ganar (los que todavía no se usaron)
+// y devuelve los que se canjearon en ella
+func anularPuntosVenta(tx *gorm.DB, venta models.Venta, usuarioID int) error {
+	if venta.PuntosGanados > 0 {
+		if err := quitarPuntosGanados(tx, venta.ID, venta.PuntosGanados, usuarioID); err != nil {
+			return err
+		}
+	}
+
+	if venta.PuntosCanjeados > 0 {
+		programa, err := programaPuntos(tx)
+		if err != nil {
+			return err
+		}
+		return acreditarPuntos(tx, programa, venta.ClienteID, venta.PuntosCanjeados, nil, models.MovimientoPuntosReintegro, usuarioID)
+	}
+	return nil
+}
+
+// quitarPuntosGanados da de baja hasta puntos de los acreditados por la venta que todavía no se usaron
+func quitarPuntosGanados(tx *gorm.DB, ventaID, puntos, usuarioID int) error {
+	var acreditaciones []models.MovimientoPuntos
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("venta_id = ? AND tipo = ? AND restantes > 0", ventaID, models.MovimientoPuntosAcreditacion).
+		Order("id DESC").
+		Find(&acreditaciones).Error; err != nil {
+		return err
+	}
+
+	pendientes := puntos
+	for _, acreditacion := range acreditaciones {
+		if pendientes == 0 {
+			break
+		}
+		quitados := min(acreditacion.Restantes, pendientes)
+		if err := tx.Model(&acreditacion).Update("restantes", acreditacion.Restantes-quitados).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.MovimientoPuntos{
+			ClienteID: acreditacion.ClienteID,
+			Tipo:      models.MovimientoPuntosAnulacion,
+			Puntos:    -quitados,
+			UsuarioID: usuarioID,
+		}).Error; err != nil {
+			return err
+		}
+		pendientes -= quitados
+	}
+	return nil
+}
+
+// actualizarPuntosVenta ajusta el libro de puntos de una venta editada. Si cambió el cliente,
+// anula los puntos en el anterior (como al eliminar la venta) y vuelve a canjear y acreditar en el
+// nuevo; si solo cambió el total final, acredita o quita la diferencia de puntos ganados.
+// Con el programa inactivo se conservan los puntos ganados originalmente.
+func actualizarPuntosVenta(tx *gorm.DB, anterior models.Venta, venta *models.Venta, usuarioID int) error {
+	programa, err := programaPuntos(tx)
+	if err != nil {
+		return err
+	}
+	ganados := venta.PuntosGanados
+	if programa.Activo {
+		ganados = puntosGanados(programa, venta.TotalFinal)
+	}
+
+	if anterior.ClienteID != venta.ClienteID {
+		if err := anularPuntosVenta(tx, anterior, usuarioID); err != nil {
+			return err
+		}
+		if venta.PuntosCanjeados > 0 {
+			if err := canjearPuntos(tx, venta.ClienteID, venta.PuntosCanjeados, venta.ID, usuarioID); err != nil {
+				return err
+			}
+		}
+		venta.PuntosGanados = ganados
+		if ganados > 0 {
+			return acreditarPuntos(tx, programa, venta.ClienteID, ganados, &venta.ID, models.MovimientoPuntosAcreditacion, usuarioID)
+		}
+		return nil
+	}
+
+	diferencia := ganados - venta.PuntosGanados
+	venta.PuntosGanados = ganados
+	switch {
+	case diferencia > 0:
+		return acreditarPuntos(tx, programa, venta.ClienteID, diferencia, &venta.ID, models.MovimientoPuntosAcreditacion, usuarioID)
+	case diferencia < 0:
+		return quitarPuntosGanados(tx, venta.ID, -diferencia, usuarioID)
+	}
+	return nil
+}
@@ -0,0 +1,306 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"vartan-backend/assets"
+	"vartan-backend/config"
+	"vartan-backend/models"
+	"vartan-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetReciboVenta godoc
+// @Summary Descargar recibo de la venta
+// @Description Genera el recibo imprimible de la venta en PDF con los datos del cliente, los renglones con talle y color, el desglose del descuento, la seña, lo pagado con saldo a favor, los pagos posteriores de cuenta corriente, el saldo y la forma de pago. Un vendedor solo puede descargar los de sus ventas.
+// @Tags Ventas
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param id path int true "ID de la venta"
+// @Success 200 {file} file "Recibo en PDF"
+// @Failure 403 {object} map[string]string "Sin permiso"
+// @Failure 404 {object} map[string]string "Venta no encontrada"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/ventas/{id}/recibo.pdf [get]
+func GetReciboVenta(c *gin.Context) {
+	var venta models.Venta
+	if err := cargarVentaDocumento(config.DB).First(&venta, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Venta no encontrada"})
+		return
+	}
+
+	// Verificar permisos: vendedor solo puede ver sus propias ventas
+	if c.GetString("rol") == "empleado" && venta.UsuarioID != c.GetInt("user_id") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permiso para ver esta venta"})
+		return
+	}
+
+	// Pagos y notas de crédito imputados después de la venta
+	pagos, err := creditosImputados(config.DB, venta.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener pagos de la venta"})
+		return
+	}
+
+	filename := fmt.Sprintf("recibo_%d.pdf", venta.ID)
+	c.Header("Content-Disposition", "inline; filename="+filename)
+	c.Data(http.StatusOK, "application/pdf", generarPDFRecibo(venta, pagos))
+}
+
+// GetRemitoPedido godoc
+// @Summary Descargar remito del pedido
+// @Description Genera el remito (nota de entrega) del pedido en PDF: cliente, dirección y unidades con talle y color, sin precios, con lugar para la firma de quien recibe. Un vendedor solo puede descargar los de sus ventas.
+// @Tags Pedidos
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param id path int true "ID del pedido"
+// @Success 200 {file} file "Remito en PDF"
+// @Failure 403 {object} map[string]string "Sin permiso"
+// @Failure 404 {object} map[string]string "Pedido no encontrado"
+// @Router /api/pedidos/{id}/remito.pdf [get]
+func GetRemitoPedido(c *gin.Context) {
+	var pedido models.Pedido
+	if err := config.DB.First(&pedido, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pedido no encontrado"})
+		return
+	}
+	if err := cargarVentaDocumento(config.DB).First(&pedido.Venta, pedido.VentaID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Venta del pedido no encontrada"})
+		return
+	}
+
+	if c.GetString("rol") == "empleado" && pedido.Venta.UsuarioID != c.GetInt("user_id") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permiso para ver este pedido"})
+		return
+	}
+
+	filename := fmt.Sprintf("remito_%d.pdf", pedido.ID)
+	c.Header("Content-Disposition", "inline; filename="+filename)
+	c.Data(http.StatusOK, "application/pdf", generarPDFRemito(pedido))
+}
+
+// cargarVentaDocumento precarga lo que necesitan el recibo y el remito
+func cargarVentaDocumento(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Usuario").
+		Preload("Cliente", conArchivados).
+		Preload("FormaPago").
+		Preload("Detalles", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Detalles.Producto")
+}
+
+// membrete dibuja el logo, la marca y el título del documento en una página nueva
+func membrete(pdf *utils.PDF, margen float64, titulo string) {
+	derecha := pdf.Ancho - margen
+
+	pdf.AddPage()
+	x := margen
+	if err := pdf.Image(assets.Logo, margen, 28, 60, 60); err == nil {
+		x += 72
+	}
+	pdf.SetFont(true, 18)
+	pdf.Text(x, 60, "VARTAN SPORT")
+	pdf.SetFont(true, 14)
+	pdf.TextRight(derecha, 55, titulo)
+	pdf.Line(margen, 98, derecha, 98, 1)
+}
+
+// datosCliente escribe el nombre y los datos de contacto del cliente; devuelve la y siguiente
+func datosCliente(pdf *utils.PDF, x, y float64, cliente models.Cliente) float64 {
+	pdf.SetFont(true, 11)
+	pdf.Text(x, y, "Cliente: "+cliente.Nombre)
+	pdf.SetFont(false, 10)
+	y += 16
+	for _, dato := range []string{cliente.Telefono, cliente.Email, direccionCliente(cliente)} {
+		if dato != "" {
+			pdf.Text(x, y, dato)
+			y += 13
+		}
+	}
+	return y
+}
+
+// generarPDFRecibo arma el recibo imprimible de la venta; pagos es lo cobrado después por cuenta corriente
+func generarPDFRecibo(v models.Venta, pagos float64) []byte {
+	pdf := utils.NewPDF()
+	const margen = 40.0
+	derecha := pdf.Ancho - margen
+
+	// Columnas de la tabla de renglones
+	colTalle := 280.0
+	colColor := 330.0
+	colCantidad := 420.0
+	colPrecio := 480.0
+
+	encabezado := func() float64 {
+		membrete(pdf, margen, fmt.Sprintf("RECIBO N° %06d", v.ID))
+		pdf.SetFont(false, 10)
+		pdf.TextRight(derecha, 72, "Fecha: "+utils.FormatoFecha(v.FechaVenta))
+		pdf.TextRight(derecha, 86, "Vendedor: "+v.Usuario.Nombre)
+
+		// Tabla
+		y := 190.0
+		pdf.Rect(margen, y-14, derecha-margen, 20, true, 0.9)
+		pdf.SetFont(true, 10)
+		pdf.Text(margen+6, y, "Producto")
+		pdf.Text(colTalle, y, "Talle")
+		pdf.Text(colColor, y, "Color")
+		pdf.TextRight(colCantidad+20, y, "Cant.")
+		pdf.TextRight(colPrecio+10, y, "P. Unitario")
+		pdf.TextRight(derecha-6, y, "Subtotal")
+		pdf.SetFont(false, 10)
+		return y + 22
+	}
+
+	y := encabezado()
+
+	// Cliente y forma de pago (solo en la primera página)
+	datosCliente(pdf, margen, 120, v.Cliente)
+	pdf.TextRight(derecha, 120, "Forma de pago: "+v.FormaPago.Nombre)
+
+	for _, d := range v.Detalles {
+		if y > pdf.Alto-140 {
+			y = encabezado()
+		}
+		pdf.Text(margen+6, y, pdf.TruncateText(d.Producto.Nombre, colTalle-margen-16))
+		pdf.Text(colTalle, y, d.Talle)
+		pdf.Text(colColor, y, pdf.TruncateText(d.Color, colCantidad-colColor-30))
+		pdf.TextRight(colCantidad+20, y, strconv.Itoa(d.Cantidad))
+		pdf.TextRight(colPrecio+10, y, utils.FormatoMoneda(d.PrecioUnitario))
+		pdf.TextRight(derecha-6, y, utils.FormatoMoneda(d.Subtotal))
+		pdf.Line(margen, y+6, derecha, y+6, 0.3)
+		y += 20
+	}
+
+	// Totales con el desglose del descuento (en otra página si no entran)
+	if y > pdf.Alto-260 {
+		y = encabezado()
+	}
+	etiqueta := colPrecio - 100
+	renglon := func(texto, valor string) {
+		y += 16
+		pdf.Text(etiqueta, y, texto)
+		pdf.TextRight(derecha-6, y, valor)
+	}
+	y -= 6
+	renglon("Subtotal", utils.FormatoMoneda(v.Total))
+	descuentos := []struct {
+		texto string
+		monto float64
+	}{
+		{"Descuento pactado", v.DescuentoPactado},
+		{"Promociones", v.DescuentoPromociones},
+		{fmt.Sprintf("Canje de %d puntos", v.PuntosCanjeados), v.DescuentoPuntos},
+		{"Transferencia financiera (3%)", v.DescuentoFinanciera},
+	}
+	for _, d := range descuentos {
+		if d.monto > 0 {
+			renglon(d.texto, "- "+utils.FormatoMoneda(d.monto))
+		}
+	}
+	if v.Descuento > 0 {
+		renglon("Descuento total", "- "+utils.FormatoMoneda(v.Descuento))
+	}
+	y += 4
+	pdf.SetFont(true, 12)
+	renglon("TOTAL", utils.FormatoMoneda(v.TotalFinal))
+	pdf.SetFont(false, 10)
+	y += 4
+	renglon("Seña", utils.FormatoMoneda(v.Sena))
+	if v.PagoSaldoAFavor > 0 {
+		renglon("Saldo a favor / gift card", utils.FormatoMoneda(v.PagoSaldoAFavor))
+	}
+	if pagos > 0 {
+		renglon("Pagos posteriores", utils.FormatoMoneda(pagos))
+	}
+	pendiente := redondear(v.TotalFinal - v.Sena - v.PagoSaldoAFavor - pagos)
+	pdf.SetFont(true, 10)
+	renglon("Saldo", utils.FormatoMoneda(max(pendiente, 0)))
+
+	pdf.SetFont(false, 9)
+	if v.PuntosGanados > 0 {
+		y += 30
+		pdf.Text(margen, y, fmt.Sprintf("Con esta compra sumaste %d puntos.", v.PuntosGanados))
+	}
+	if v.Observaciones != nil && *v.Observaciones != "" {
+		y += 20
+		pdf.Text(margen, y, "Observaciones: "+pdf.TruncateText(*v.Observaciones, derecha-margen-80))
+	}
+
+	pdf.Text(margen, pdf.Alto-50, "¡Gracias por tu compra!")
+	pdf.Text(margen, pdf.Alto-38, "Comprobante no válido como factura.")
+
+	return pdf.Bytes()
+}
+
+// generarPDFRemito arma el remito del pedido: solo unidades, sin precios
+func generarPDFRemito(p models.Pedido) []byte {
+	pdf := utils.NewPDF()
+	const margen = 40.0
+	derecha := pdf.Ancho - margen
+	v := p.Venta
+
+	// Columnas de la tabla de renglones
+	colTalle := 330.0
+	colColor := 390.0
+
+	encabezado := func() float64 {
+		membrete(pdf, margen, fmt.Sprintf("REMITO N° %06d", p.ID))
+		pdf.SetFont(false, 10)
+		pdf.TextRight(derecha, 72, "Fecha: "+utils.FormatoFecha(p.FechaCreacion))
+		pdf.TextRight(derecha, 86, fmt.Sprintf("Venta N° %06d", v.ID))
+
+		// Tabla
+		y := 190.0
+		pdf.Rect(margen, y-14, derecha-margen, 20, true, 0.9)
+		pdf.SetFont(true, 10)
+		pdf.Text(margen+6, y, "Producto")
+		pdf.Text(colTalle, y, "Talle")
+		pdf.Text(colColor, y, "Color")
+		pdf.TextRight(derecha-6, y, "Cantidad")
+		pdf.SetFont(false, 10)
+		return y + 22
+	}
+
+	y := encabezado()
+	datosCliente(pdf, margen, 120, v.Cliente)
+
+	// Las unidades devueltas no se entregan
+	unidades := 0
+	for _, d := range v.Detalles {
+		cantidad := d.Cantidad - d.CantidadDevuelta
+		if cantidad <= 0 {
+			continue
+		}
+		if y > pdf.Alto-160 {
+			y = encabezado()
+		}
+		pdf.Text(margen+6, y, pdf.TruncateText(d.Producto.Nombre, colTalle-margen-16))
+		pdf.Text(colTalle, y, d.Talle)
+		pdf.Text(colColor, y, pdf.TruncateText(d.Color, derecha-colColor-70))
+		pdf.TextRight(derecha-6, y, strconv.Itoa(cantidad))
+		pdf.Line(margen, y+6, derecha, y+6, 0.3)
+		unidades += cantidad
+		y += 20
+	}
+
+	y += 10
+	pdf.SetFont(true, 11)
+	pdf.Text(colColor, y, "Total de unidades")
+	pdf.TextRight(derecha-6, y, strconv.Itoa(unidades))
+
+	// Conformidad de quien recibe
+	pdf.SetFont(false, 9)
+	firma := pdf.Alto - 90
+	for i, texto := range []string{"Firma", "Aclaración", "DNI"} {
+		x := margen + float64(i)*((derecha-margen)/3)
+		pdf.Line(x, firma, x+150, firma, 0.5)
+		pdf.Text(x, firma+12, texto)
+	}
+	pdf.Text(margen, pdf.Alto-50, "Recibí conforme la mercadería detallada.")
+
+	return pdf.Bytes()
+}
@@ -0,0 +1,196 @@
+package controllers
+
+import (
+	"math"
+	"net/http"
+	"time"
+	"vartan-backend/config"
+	"vartan-backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UpdateReposicionStock godoc
+// @Summary Configurar reposición de una variante
+// @Description Define el stock mínimo y la cantidad a reponer de un producto/talle/color. Con null se usan los valores del tipo de producto (solo dueño).
+// @Tags Stock
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID del stock"
+// @Param request body models.ReposicionRequest true "Valores de reposición"
+// @Success 200 {object} models.ProductoStock
+// @Failure 400 {object} map[string]string "Datos inválidos"
+// @Failure 404 {object} map[string]string "Stock no encontrado"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/stock/{id}/reposicion [put]
+func UpdateReposicionStock(c *gin.Context) {
+	var stock models.ProductoStock
+	if err := config.DB.First(&stock, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Stock no encontrado"})
+		return
+	}
+
+	var req models.ReposicionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+
+	stock.StockMinimo = req.StockMinimo
+	stock.CantidadReposicion = req.CantidadReposicion
+
+	if err := config.DB.Model(&stock).Select("stock_minimo", "cantidad_reposicion").Updates(&stock).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar stock"})
+		return
+	}
+
+	c.JSON(http.StatusOK, stock)
+}
+
+// UpdateReposicionTipoProducto godoc
+// @Summary Configurar reposición por tipo de producto
+// @Description Define el stock mínimo y la cantidad a reponer por defecto para las variantes del tipo (solo dueño)
+// @Tags TiposProducto
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID del tipo de producto"
+// @Param request body models.ReposicionRequest true "Valores de reposición"
+// @Success 200 {object} models.TipoProducto
+// @Failure 400 {object} map[string]string "Datos inválidos"
+// @Failure 404 {object} map[string]string "Tipo de producto no encontrado"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/tipos-producto/{id}/reposicion [put]
+func UpdateReposicionTipoProducto(c *gin.Context) {
+	var tipo models.TipoProducto
+	if err := config.DB.First(&tipo, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tipo de producto no encontrado"})
+		return
+	}
+
+	var req models.ReposicionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+
+	tipo.StockMinimo = req.StockMinimo
+	tipo.CantidadReposicion = req.CantidadReposicion
+
+	if err := config.DB.Model(&tipo).Select("stock_minimo", "cantidad_reposicion").Updates(&tipo).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar tipo de producto"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tipo)
+}
+
+// GetAlertasStock godoc
+// @Summary Alertas de stock bajo y pedido sugerido
+// @Description Lista las variantes por debajo de su stock mínimo y arma un pedido sugerido para cubrir los próximos días al ritmo de venta reciente (solo dueño)
+// @Tags Stock
+// @Produce json
+// @Security BearerAuth
+// @Param dias query int false "Días de ventas para calcular el ritmo (por defecto 30)"
+// @Param cobertura query int false "Días que debe cubrir la compra (por defecto 30)"
+// @Success 200 {object} models.AlertasStockResponse
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/stock/alertas [get]
+func GetAlertasStock(c *gin.Context) {
+	dias := parametroEntero(c, "dias", 30)
+	cobertura := parametroEntero(c, "cobertura", 30)
+
+	// Ritmo de venta de cada variante (producto, talle y color)
+	var variantes []struct {
+		StockID            int
+		ProductoID         int
+		Nombre             string
+		Talle              string
+		Color              string
+		Cantidad           int
+		StockMinimo        *int
+		CantidadReposicion *int
+		CostoUnitario      float64
+		Vendidas           int
+	}
+	if err := config.DB.Table("producto_stocks ps").
+		Select(`ps.id AS stock_id, p.id AS producto_id, p.nombre, ps.talle, ps.color, ps.cantidad,
+			COALESCE(ps.stock_minimo, tp.stock_minimo) AS stock_minimo,
+			COALESCE(ps.cantidad_reposicion, tp.cantidad_reposicion) AS cantidad_reposicion,
+			p.costo_unitario, COALESCE(ven.vendidas, 0) AS vendidas`).
+		Joins("JOIN productos p ON p.id = ps.producto_id").
+		Joins("LEFT JOIN tipo_productos tp ON tp.id = p.tipo_producto_id").
+		Joins(`LEFT JOIN (
+			SELECT vd.producto_id, vd.talle, vd.color, SUM(vd.cantidad) AS vendidas FROM venta_detalles vd
+			JOIN venta v ON v.id = vd.venta_id
+			WHERE v.fecha_venta >= ? GROUP BY vd.producto_id, vd.talle, vd.color
+		) ven ON ven.producto_id = ps.producto_id AND ven.talle = ps.talle AND ven.color = ps.color`, time.Now().AddDate(0, 0, -dias)).
+		Where("p.activo = ?", true).
+		Order("p.nombre, ps.talle, ps.color").
+		Scan(&variantes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al calcular alertas de stock"})
+		return
+	}
+
+	response := models.AlertasStockResponse{
+		Dias:             dias,
+		Cobertura:        cobertura,
+		Alertas:          []models.AlertaStock{},
+		SugerenciaCompra: []models.ItemSugerenciaCompra{},
+	}
+
+	for _, v := range variantes {
+		ventaDiaria := float64(v.Vendidas) / float64(dias)
+
+		minimo := 0
+		if v.StockMinimo != nil {
+			minimo = *v.StockMinimo
+		}
+
+		// Comprar lo necesario para terminar la cobertura con el mínimo en stock,
+		// y al menos la cantidad de reposición pactada
+		sugerida := int(math.Ceil(float64(minimo) + ventaDiaria*float64(cobertura) - float64(v.Cantidad)))
+		if sugerida > 0 && v.CantidadReposicion != nil && sugerida < *v.CantidadReposicion {
+			sugerida = *v.CantidadReposicion
+		}
+
+		bajoMinimo := v.StockMinimo != nil && v.Cantidad < *v.StockMinimo
+		if bajoMinimo {
+			response.Alertas = append(response.Alertas, models.AlertaStock{
+				StockID:          v.StockID,
+				ProductoID:       v.ProductoID,
+				Nombre:           v.Nombre,
+				Talle:            v.Talle,
+				Color:            v.Color,
+				Cantidad:         v.Cantidad,
+				StockMinimo:      *v.StockMinimo,
+				Faltante:         *v.StockMinimo - v.Cantidad,
+				VentaDiaria:      redondear(ventaDiaria),
+				CantidadSugerida: max(sugerida, 0),
+			})
+		}
+
+		if sugerida <= 0 {
+			continue
+		}
+		subtotal := redondear(float64(sugerida) * v.CostoUnitario)
+		response.SugerenciaCompra = append(response.SugerenciaCompra, models.ItemSugerenciaCompra{
+			StockID:          v.StockID,
+			ProductoID:       v.ProductoID,
+			Nombre:           v.Nombre,
+			Talle:            v.Talle,
+			Color:            v.Color,
+			Cantidad:         v.Cantidad,
+			VentaDiaria:      redondear(ventaDiaria),
+			CantidadSugerida: sugerida,
+			CostoUnitario:    v.CostoUnitario,
+			Subtotal:         subtotal,
+		})
+		response.TotalUnidades += sugerida
+		response.TotalCosto += subtotal
+	}
+	response.TotalCosto = redondear(response.TotalCosto)
+
+	c.JSON(http.StatusOK, response)
+}
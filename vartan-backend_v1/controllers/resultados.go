@@ -0,0 +1,205 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+	"vartan-backend/config"
+	"vartan-backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Categoría de gasto que corresponde a compras de mercadería
+const categoriaMercaderia = "Mercadería"
+
+// GetEstadoResultados godoc
+// @Summary Estado de resultados mensual
+// @Description Ingresos netos de descuentos y devoluciones, costo de lo vendido sin las unidades devueltas, margen bruto, gastos por categoría, comisiones, sueldos y resultado neto de los 12 meses que terminan en el mes indicado (solo dueño). Las compras de mercadería se informan aparte y no se restan, porque ya están en el costo de lo vendido.
+// @Tags Reportes
+// @Produce json
+// @Security BearerAuth
+// @Param hasta query string false "Último mes (YYYY-MM), por defecto el mes en curso"
+// @Success 200 {object} models.EstadoResultadosResponse
+// @Failure 400 {object} map[string]string "Mes inválido"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/reportes/resultados [get]
+func GetEstadoResultados(c *gin.Context) {
+	hoy := time.Now()
+	hasta := time.Date(hoy.Year(), hoy.Month(), 1, 0, 0, 0, 0, time.Local)
+	if mes := c.Query("hasta"); mes != "" {
+		var err error
+		if hasta, err = time.ParseInLocation("2006-01", mes, time.Local); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Formato de mes inválido. Use YYYY-MM"})
+			return
+		}
+	}
+	desde := hasta.AddDate(0, -11, 0)
+	fin := hasta.AddDate(0, 1, 0)
+
+	// Un renglón por mes, en orden
+	meses := make([]models.EstadoResultadosMes, 12)
+	indice := make(map[string]int, 12)
+	for i := range meses {
+		mes := desde.AddDate(0, i, 0)
+		meses[i] = models.EstadoResultadosMes{Anio: mes.Year(), Mes: int(mes.Month()), GastosPorCategoria: []models.GastoResumen{}}
+		indice[claveMes(mes.Year(), int(mes.Month()))] = i
+	}
+
+	// Ventas
+	var ventas []struct {
+		Anio         int
+		Mes          int
+		VentasBrutas float64
+		Descuentos   float64
+		Devoluciones float64
+		Ingresos     float64
+	}
+	// Lo reintegrado por devoluciones se resta en el mes de la venta, igual que su costo
+	if err := config.DB.Model(&models.Venta{}).
+		Select(`EXTRACT(YEAR FROM fecha_venta)::int AS anio, EXTRACT(MONTH FROM fecha_venta)::int AS mes,
+			COALESCE(SUM(total), 0) AS ventas_brutas, COALESCE(SUM(descuento), 0) AS descuentos,
+			COALESCE(SUM(dev.monto), 0) AS devoluciones, COALESCE(SUM(total_final), 0) - COALESCE(SUM(dev.monto), 0) AS ingresos`).
+		Joins(`LEFT JOIN (
+			SELECT venta_id, SUM(monto_inicial) AS monto FROM saldos_a_favor WHERE origen = ? GROUP BY venta_id
+		) dev ON dev.venta_id = venta.id`, models.SaldoAFavorDevolucion).
+		Where("fecha_venta >= ? AND fecha_venta < ?", desde, fin).
+		Group("1, 2").
+		Scan(&ventas).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al calcular ventas"})
+		return
+	}
+	for _, v := range ventas {
+		if i, ok := indice[claveMes(v.Anio, v.Mes)]; ok {
+			meses[i].VentasBrutas = v.VentasBrutas
+			meses[i].Descuentos = v.Descuentos
+			meses[i].Devoluciones = v.Devoluciones
+			meses[i].Ingresos = v.Ingresos
+		}
+	}
+
+	// Costo de lo vendido con el costo guardado en cada renglón, sin las unidades devueltas
+	var costos []struct {
+		Anio  int
+		Mes   int
+		Costo float64
+	}
+	if err := config.DB.Table("venta_detalles vd").
+		Select(`EXTRACT(YEAR FROM v.fecha_venta)::int AS anio, EXTRACT(MONTH FROM v.fecha_venta)::int AS mes,
+			COALESCE(SUM((vd.cantidad - vd.cantidad_devuelta) * COALESCE(vd.costo_unitario, 0)), 0) AS costo`).
+		Joins("JOIN venta v ON v.id = vd.venta_id").
+		Where("v.fecha_venta >= ? AND v.fecha_venta < ?", desde, fin).
+		Group("1, 2").
+		Scan(&costos).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al calcular costo de mercadería"})
+		return
+	}
+	for _, cm := range costos {
+		if i, ok := indice[claveMes(cm.Anio, cm.Mes)]; ok {
+			meses[i].CostoMercaderia = cm.Costo
+		}
+	}
+
+	// Gastos por categoría
+	var gastos []struct {
+		Anio int
+		Mes  int
+		models.GastoResumen
+	}
+	if err := config.DB.Model(&models.Gasto{}).
+		Select(`EXTRACT(YEAR FROM fecha)::int AS anio, EXTRACT(MONTH FROM fecha)::int AS mes,
+			categoria, COALESCE(SUM(monto), 0) AS total, COUNT(*) AS cantidad`).
+		Where("fecha >= ? AND fecha < ?", desde, fin).
+		Group("1, 2, categoria").
+		Order("total DESC").
+		Scan(&gastos).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al calcular gastos"})
+		return
+	}
+	for _, g := range gastos {
+		i, ok := indice[claveMes(g.Anio, g.Mes)]
+		if !ok {
+			continue
+		}
+		meses[i].GastosPorCategoria = append(meses[i].GastosPorCategoria, g.GastoResumen)
+		if g.Categoria == categoriaMercaderia {
+			meses[i].ComprasMercaderia += g.Total
+		} else {
+			meses[i].GastosOperativos += g.Total
+		}
+	}
+
+	// Comisiones y sueldos liquidados
+	var comisiones []struct {
+		Anio       int
+		Mes        int
+		Comisiones float64
+		Sueldos    float64
+	}
+	if err := config.DB.Model(&models.Comision{}).
+		Select("anio, mes, COALESCE(SUM(total_comision), 0) AS comisiones, COALESCE(SUM(sueldo), 0) AS sueldos").
+		Where("(anio * 100 + mes) BETWEEN ? AND ?", desde.Year()*100+int(desde.Month()), hasta.Year()*100+int(hasta.Month())).
+		Group("anio, mes").
+		Scan(&comisiones).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al calcular comisiones"})
+		return
+	}
+	for _, cm := range comisiones {
+		if i, ok := indice[claveMes(cm.Anio, cm.Mes)]; ok {
+			meses[i].Comisiones = cm.Comisiones
+			meses[i].Sueldos = cm.Sueldos
+		}
+	}
+
+	totales := models.EstadoResultadosMes{GastosPorCategoria: []models.GastoResumen{}}
+	porCategoria := map[string]int{}
+	for i := range meses {
+		m := &meses[i]
+		calcularResultado(m)
+		if i > 0 {
+			m.VariacionIngresos = variacionPorcentual(m.Ingresos, meses[i-1].Ingresos)
+			m.VariacionResultado = variacionPorcentual(m.ResultadoNeto, meses[i-1].ResultadoNeto)
+		}
+
+		totales.VentasBrutas += m.VentasBrutas
+		totales.Descuentos += m.Descuentos
+		totales.Devoluciones += m.Devoluciones
+		totales.Ingresos += m.Ingresos
+		totales.CostoMercaderia += m.CostoMercaderia
+		totales.GastosOperativos += m.GastosOperativos
+		totales.ComprasMercaderia += m.ComprasMercaderia
+		totales.Comisiones += m.Comisiones
+		totales.Sueldos += m.Sueldos
+		for _, g := range m.GastosPorCategoria {
+			if j, ok := porCategoria[g.Categoria]; ok {
+				totales.GastosPorCategoria[j].Total += g.Total
+				totales.GastosPorCategoria[j].Cantidad += g.Cantidad
+			} else {
+				porCategoria[g.Categoria] = len(totales.GastosPorCategoria)
+				totales.GastosPorCategoria = append(totales.GastosPorCategoria, g)
+			}
+		}
+	}
+	calcularResultado(&totales)
+
+	c.JSON(http.StatusOK, models.EstadoResultadosResponse{
+		Desde:   desde.Format("2006-01"),
+		Hasta:   hasta.Format("2006-01"),
+		Meses:   meses,
+		Totales: totales,
+	})
+}
+
+// calcularResultado completa el margen y el resultado neto a partir de los importes cargados
+func calcularResultado(m *models.EstadoResultadosMes) {
+	m.MargenBruto = redondear(m.Ingresos - m.CostoMercaderia)
+	if m.Ingresos != 0 {
+		m.MargenBrutoPorcentaje = redondear(m.MargenBruto / m.Ingresos * 100)
+	}
+	m.ResultadoNeto = redondear(m.MargenBruto - m.GastosOperativos - m.Comisiones - m.Sueldos)
+}
+
+// claveMes arma la clave YYYY-MM usada para ubicar cada fila en su mes
+func claveMes(anio, mes int) string {
+	return fmt.Sprintf("%04d-%02d", anio, mes)
+}
@@ -0,0 +1,340 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"vartan-backend/config"
+	"vartan-backend/models"
+	"vartan-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Caracteres de los códigos: sin 0/O ni 1/I para que se puedan dictar sin confusión
+const alfabetoCodigoSaldo = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// GetSaldoAFavor godoc
+// @Summary Consultar gift card o saldo a favor
+// @Description Obtiene el saldo disponible de un código con su historial de movimientos
+// @Tags Saldos a favor
+// @Produce json
+// @Security BearerAuth
+// @Param codigo path string true "Código de la gift card o saldo a favor"
+// @Success 200 {object} models.SaldoAFavor
+// @Failure 404 {object} map[string]string "Código inexistente"
+// @Router /api/saldos-a-favor/{codigo} [get]
+func GetSaldoAFavor(c *gin.Context) {
+	var saldo models.SaldoAFavor
+	err := config.DB.
+		Preload("Cliente", conArchivados).
+		Preload("Movimientos", func(db *gorm.DB) *gorm.DB { return db.Order("fecha, id") }).
+		Where("codigo = ?", normalizarCodigoSaldo(c.Param("codigo"))).
+		First(&saldo).Error
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Código inexistente"})
+		return
+	}
+
+	c.JSON(http.StatusOK, saldo)
+}
+
+// CrearGiftCard godoc
+// @Summary Emitir gift card
+// @Description Emite una gift card con un código único por el monto indicado, opcionalmente a nombre de un cliente (solo dueño)
+// @Tags Saldos a favor
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.GiftCardCreateRequest true "Monto y destinatario"
+// @Success 201 {object} models.SaldoAFavor
+// @Failure 400 {object} map[string]string "Datos inválidos"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/saldos-a-favor [post]
+func CrearGiftCard(c *gin.Context) {
+	var req models.GiftCardCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+	if req.ClienteID != nil {
+		var cliente models.Cliente
+		if err := config.DB.First(&cliente, *req.ClienteID).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cliente no encontrado"})
+			return
+		}
+	}
+
+	var saldo models.SaldoAFavor
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		saldo, err = emitirSaldoAFavor(tx, models.SaldoAFavorGiftCard, redondear(req.Monto), req.ClienteID, nil, req.Descripcion, c.GetInt("user_id"))
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al emitir gift card"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, saldo)
+}
+
+// RegistrarDevolucion godoc
+// @Summary Registrar devolución
+// @Description Reingresa al stock las unidades devueltas de una venta y emite un saldo a favor del cliente por lo que pagó por ellas (precio con promociones, descuento pactado y de financiera)
+// @Tags Saldos a favor
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID de la venta"
+// @Param request body models.DevolucionRequest true "Renglones y unidades devueltas"
+// @Success 201 {object} models.DevolucionResponse
+// @Failure 400 {object} map[string]string "Datos inválidos o unidades ya devueltas"
+// @Failure 404 {object} map[string]string "Venta no encontrada"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/ventas/{id}/devoluciones [post]
+func RegistrarDevolucion(c *gin.Context) {
+	var req models.DevolucionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+
+	var venta models.Venta
+	if err := config.DB.Preload("Detalles").Preload("Detalles.Producto").First(&venta, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Venta no encontrada"})
+		return
+	}
+
+	detalles := map[int]models.VentaDetalle{}
+	for _, d := range venta.Detalles {
+		detalles[d.ID] = d
+	}
+
+	// Proporción del precio de los renglones que efectivamente se cobró (pactado y financiera)
+	factor := 1.0
+	if base := venta.Total - venta.DescuentoPromociones; base > 0 {
+		factor = venta.TotalFinal / base
+	}
+
+	usuarioID := c.GetInt("user_id")
+	motivo := "Devolución de la venta " + fmt.Sprint(venta.ID)
+	if req.Motivo != "" {
+		motivo += ": " + req.Motivo
+	}
+
+	var respuesta models.DevolucionResponse
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		monto := 0.0
+		for _, item := range req.Items {
+			detalle, ok := detalles[item.DetalleID]
+			if !ok {
+				return &ventaError{http.StatusBadRequest, fmt.Sprintf("El renglón %d no pertenece a la venta", item.DetalleID)}
+			}
+
+			// La condición evita devolver más de lo vendido aunque haya devoluciones simultáneas
+			result := tx.Model(&models.VentaDetalle{}).
+				Where("id = ? AND cantidad_devuelta + ? <= cantidad", detalle.ID, item.Cantidad).
+				Update("cantidad_devuelta", gorm.Expr("cantidad_devuelta + ?", item.Cantidad))
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return &ventaError{http.StatusBadRequest, "La cantidad devuelta de " + detalle.Producto.Nombre + " supera las unidades vendidas pendientes de devolución"}
+			}
+
+			stock, err := stockDeDetalle(tx, detalle)
+			if err != nil {
+				return &ventaError{http.StatusBadRequest, "No se encontró el stock de " + detalle.Producto.Nombre + " talle " + detalle.Talle}
+			}
+			if err := ajustarStock(tx, stock.ID, item.Cantidad, models.MovimientoStockDevolucion, motivo, nil, usuarioID); err != nil {
+				return err
+			}
+
+			unitario := (detalle.Subtotal - detalle.Descuento) / float64(detalle.Cantidad) * factor
+			monto += unitario * float64(item.Cantidad)
+			respuesta.Unidades += item.Cantidad
+		}
+
+		if monto = redondear(monto); monto <= 0 {
+			return &ventaError{http.StatusBadRequest, "Las unidades devueltas no tienen importe a reintegrar"}
+		}
+		saldo, err := emitirSaldoAFavor(tx, models.SaldoAFavorDevolucion, monto, &venta.ClienteID, &venta.ID, motivo, usuarioID)
+		respuesta.SaldoAFavor = saldo
+		return err
+	})
+	if err != nil {
+		responderError(c, err, "Error al registrar devolución")
+		return
+	}
+
+	c.JSON(http.StatusCreated, respuesta)
+}
+
+// emitirSaldoAFavor crea el saldo con un código nuevo y su movimiento de emisión
+func emitirSaldoAFavor(tx *gorm.DB, origen string, monto float64, clienteID, ventaID *int, descripcion string, usuarioID int) (models.SaldoAFavor, error) {
+	codigo, err := generarCodigoSaldo(tx)
+	if err != nil {
+		return models.SaldoAFavor{}, err
+	}
+
+	saldo := models.SaldoAFavor{
+		Codigo:       codigo,
+		Origen:       origen,
+		MontoInicial: monto,
+		Saldo:        monto,
+		ClienteID:    clienteID,
+		VentaID:      ventaID,
+		UsuarioID:    usuarioID,
+	}
+	if descripcion != "" {
+		saldo.Descripcion = &descripcion
+	}
+	if err := tx.Create(&saldo).Error; err != nil {
+		return models.SaldoAFavor{}, err
+	}
+
+	movimiento := models.MovimientoSaldoAFavor{
+		SaldoAFavorID: saldo.ID,
+		Tipo:          models.MovimientoSaldoEmision,
+		Monto:         monto,
+		UsuarioID:     usuarioID,
+	}
+	if err := tx.Create(&movimiento).Error; err != nil {
+		return models.SaldoAFavor{}, err
+	}
+	saldo.Movimientos = []models.MovimientoSaldoAFavor{movimiento}
+	return saldo, nil
+}
+
+// generarCodigoSaldo arma un código aleatorio XXXX-XXXX-XXXX que no esté en uso
+func generarCodigoSaldo(tx *gorm.DB) (string, error) {
+	for intento := 0; intento < 5; intento++ {
+		var b strings.Builder
+		for i := 0; i < 12; i++ {
+			if i > 0 && i%4 == 0 {
+				b.WriteByte('-')
+			}
+			n, err := rand.Int(rand.Reader, big.NewInt(int64(len(alfabetoCodigoSaldo))))
+			if err != nil {
+				return "", err
+			}
+			b.WriteByte(alfabetoCodigoSaldo[n.Int64()])
+		}
+
+		var count int64
+		if err := tx.Model(&models.SaldoAFavor{}).Where("codigo = ?", b.String()).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return b.String(), nil
+		}
+	}
+	return "", errors.New("no se pudo generar un código único")
+}
+
+func normalizarCodigoSaldo(codigo string) string {
+	return strings.ToUpper(strings.TrimSpace(codigo))
+}
+
+// montoSaldoAFavor bloquea el saldo del código y calcula cuánto se usa para pagar: lo pedido o,
+// si no se indica, lo que alcance a cubrir del pendiente
+func montoSaldoAFavor(tx *gorm.DB, codigo string, pedido, pendiente float64) (models.SaldoAFavor, float64, error) {
+	var saldo models.SaldoAFavor
+	codigo = normalizarCodigoSaldo(codigo)
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("codigo = ?", codigo).First(&saldo).Error; err != nil {
+		return saldo, 0, &ventaError{http.StatusBadRequest, "Gift card o saldo a favor " + codigo + " inexistente"}
+	}
+
+	monto := pedido
+	if monto <= 0 {
+		monto = min(saldo.Saldo, pendiente)
+	}
+	monto = redondear(monto)
+	switch {
+	case saldo.Saldo <= 0:
+		return saldo, 0, &ventaError{http.StatusBadRequest, "La gift card " + codigo + " no tiene saldo"}
+	case monto > saldo.Saldo+0.005:
+		return saldo, 0, &ventaError{http.StatusBadRequest, "El saldo disponible de " + codigo + " es " + utils.FormatoMoneda(saldo.Saldo)}
+	case monto > pendiente+0.005:
+		return saldo, 0, &ventaError{http.StatusBadRequest, "El pago con saldo a favor supera lo que resta pagar (" + utils.FormatoMoneda(pendiente) + ")"}
+	case monto <= 0:
+		return saldo, 0, &ventaError{http.StatusBadRequest, "No queda saldo de la venta para pagar con saldo a favor"}
+	}
+	return saldo, monto, nil
+}
+
+// consumirSaldoAFavor descuenta el monto usado en la venta y registra el consumo
+func consumirSaldoAFavor(tx *gorm.DB, saldo models.SaldoAFavor, monto float64, ventaID, usuarioID int) error {
+	if err := tx.Model(&saldo).Update("saldo", gorm.Expr("saldo - ?", monto)).Error; err != nil {
+		return err
+	}
+	return tx.Create(&models.MovimientoSaldoAFavor{
+		SaldoAFavorID: saldo.ID,
+		Tipo:          models.MovimientoSaldoConsumo,
+		Monto:         monto,
+		VentaID:       &ventaID,
+		UsuarioID:     usuarioID,
+	}).Error
+}
+
+// anularSaldosDevolucion deja sin saldo los créditos emitidos por devoluciones de una venta eliminada.
+// Si alguno ya se usó la venta no se puede eliminar.
+func anularSaldosDevolucion(tx *gorm.DB, ventaID, usuarioID int) error {
+	var saldos []models.SaldoAFavor
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("venta_id = ? AND origen = ?", ventaID, models.SaldoAFavorDevolucion).
+		Find(&saldos).Error; err != nil {
+		return err
+	}
+	for _, saldo := range saldos {
+		if saldo.Saldo < saldo.MontoInicial-0.005 {
+			return &ventaError{http.StatusBadRequest, "El saldo a favor " + saldo.Codigo + " emitido por una devolución de esta venta ya se usó; no se puede eliminar la venta"}
+		}
+		if saldo.Saldo <= 0 {
+			continue
+		}
+		if err := tx.Model(&saldo).Update("saldo", 0).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.MovimientoSaldoAFavor{
+			SaldoAFavorID: saldo.ID,
+			Tipo:          models.MovimientoSaldoAnulacion,
+			Monto:         saldo.Saldo,
+			VentaID:       &ventaID,
+			UsuarioID:     usuarioID,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reintegrarSaldoAFavor devuelve a cada gift card lo consumido en una venta eliminada
+func reintegrarSaldoAFavor(tx *gorm.DB, ventaID, usuarioID int) error {
+	var consumos []models.MovimientoSaldoAFavor
+	if err := tx.Where("venta_id = ? AND tipo = ?", ventaID, models.MovimientoSaldoConsumo).Find(&consumos).Error; err != nil {
+		return err
+	}
+	for _, consumo := range consumos {
+		if err := tx.Model(&models.SaldoAFavor{}).Where("id = ?", consumo.SaldoAFavorID).
+			Update("saldo", gorm.Expr("saldo + ?", consumo.Monto)).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.MovimientoSaldoAFavor{
+			SaldoAFavorID: consumo.SaldoAFavorID,
+			Tipo:          models.MovimientoSaldoReintegro,
+			Monto:         consumo.Monto,
+			VentaID:       &ventaID,
+			UsuarioID:     usuarioID,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
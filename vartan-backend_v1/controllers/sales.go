@@ -3,6 +3,7 @@ package controllers
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -11,8 +12,10 @@ import (
 	"time"
 	"vartan-backend/config"
 	"vartan-backend/models"
+	"vartan-backend/utils"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // CreateVenta godoc
@@ -44,7 +47,7 @@ func CreateVenta(c *gin.Context) {
 			return
 		}
 		// Procesar como JSON (sin comprobante)
-		processVenta(c, jsonReq.UsuarioID, jsonReq.ClienteID, jsonReq.FormaPagoID, jsonReq.Sena, jsonReq.Observaciones, jsonReq.Detalles, nil)
+		processVenta(c, jsonReq, nil)
 		return
 	}
 
@@ -56,11 +59,20 @@ func CreateVenta(c *gin.Context) {
 			return
 		}
 
-		// Parsear detalles desde JSON string
+		// Parsear detalles y combos desde JSON string
 		var detalles []models.VentaDetalleCreateRequest
-		if err := json.Unmarshal([]byte(formReq.Detalles), &detalles); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Formato de detalles inválido: " + err.Error()})
-			return
+		if formReq.Detalles != "" {
+			if err := json.Unmarshal([]byte(formReq.Detalles), &detalles); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Formato de detalles inválido: " + err.Error()})
+				return
+			}
+		}
+		var combos []models.VentaComboCreateRequest
+		if formReq.Combos != "" {
+			if err := json.Unmarshal([]byte(formReq.Combos), &combos); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Formato de combos inválido: " + err.Error()})
+				return
+			}
 		}
 
 		// Manejar el archivo comprobante
@@ -130,7 +142,48 @@ func CreateVenta(c *gin.Context) {
 			return
 		}
 
-		processVenta(c, usuarioID, clienteID, formaPagoID, sena, formReq.Observaciones, detalles, comprobanteURL)
+		var saldoAFavorMonto float64
+		if formReq.SaldoAFavorMonto != "" {
+			if saldoAFavorMonto, err = strconv.ParseFloat(formReq.SaldoAFavorMonto, 64); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "saldo_a_favor_monto inválido"})
+				return
+			}
+		}
+
+		var puntosCanje int
+		if formReq.PuntosCanje != "" {
+			if puntosCanje, err = strconv.Atoi(formReq.PuntosCanje); err != nil || puntosCanje < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "puntos_canje inválido"})
+				return
+			}
+		}
+
+		var campanaID *int
+		if formReq.CampanaID != "" {
+			id, err := strconv.Atoi(formReq.CampanaID)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "campana_id inválido"})
+				return
+			}
+			campanaID = &id
+		}
+
+		processVenta(c, models.VentaCreateRequest{
+			UsuarioID:     usuarioID,
+			ClienteID:     clienteID,
+			FormaPagoID:   formaPagoID,
+			Sena:          sena,
+			Observaciones: formReq.Observaciones,
+			Cupon:         formReq.Cupon,
+			Detalles:      detalles,
+			Combos:        combos,
+
+			SaldoAFavorCodigo: formReq.SaldoAFavorCodigo,
+			SaldoAFavorMonto:  saldoAFavorMonto,
+			PuntosCanje:       puntosCanje,
+			CanalVenta:        formReq.CanalVenta,
+			CampanaID:         campanaID,
+		}, comprobanteURL)
 		return
 	}
 
@@ -138,127 +191,265 @@ func CreateVenta(c *gin.Context) {
 	c.JSON(http.StatusBadRequest, gin.H{"error": "Content-Type no soportado. Use application/json o multipart/form-data"})
 }
 
+// ventaError - error de negocio al registrar una venta junto con el status HTTP a devolver
+type ventaError struct {
+	status  int
+	mensaje string
+}
+
+func (e *ventaError) Error() string {
+	return e.mensaje
+}
+
+// responderErrorVenta traduce el error de registrarVenta a la respuesta HTTP
+func responderErrorVenta(c *gin.Context, err error) {
+	responderError(c, err, "Error al crear venta")
+}
+
+// responderError responde con el status del ventaError o con un 500 y el mensaje indicado
+func responderError(c *gin.Context, err error, mensaje string) {
+	if ve, ok := err.(*ventaError); ok {
+		c.JSON(ve.status, gin.H{"error": ve.mensaje})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": mensaje})
+}
+
 // processVenta procesa la creación de la venta
-func processVenta(c *gin.Context, usuarioID *int, clienteID int, formaPagoID int, sena float64, observaciones string, detalles []models.VentaDetalleCreateRequest, comprobanteURL *string) {
+func processVenta(c *gin.Context, req models.VentaCreateRequest, comprobanteURL *string) {
+	// Iniciar transacción
+	tx := config.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	venta, err := registrarVenta(tx, c.GetInt("user_id"), req, comprobanteURL, 0, true)
+	if err != nil {
+		tx.Rollback()
+		responderErrorVenta(c, err)
+		return
+	}
+
+	// Commit de la transacción
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al confirmar venta"})
+		return
+	}
+
+	// Cargar la venta con todas sus relaciones
+	config.DB.
+		Preload("Usuario").
+		Preload("Cliente", conArchivados).
+		Preload("FormaPago").
+		Preload("Detalles").
+		Preload("Detalles.Producto").
+		First(&venta, venta.ID)
+
+	c.JSON(http.StatusCreated, venta)
+}
+
+// registrarVenta crea la venta, sus detalles y el pedido dentro de la transacción recibida,
+// descontando el stock y aplicando los descuentos según la forma de pago.
+// descuentoPactado permite arrastrar un descuento acordado previamente (p. ej. en un presupuesto);
+// en ese caso el precio ya está negociado y no se suman las promociones automáticas.
+// No hace commit ni rollback: eso queda a cargo de quien la llama.
+func registrarVenta(tx *gorm.DB, usuarioAutenticadoID int, req models.VentaCreateRequest, comprobanteURL *string, descuentoPactado float64, conPromociones bool) (models.Venta, error) {
 	// Determinar el vendedor que realiza la venta
 	var vendedorID int
-	if usuarioID != nil && *usuarioID > 0 {
+	if req.UsuarioID != nil && *req.UsuarioID > 0 {
 		// Verificar que el usuario existe y es un vendedor
 		var usuario models.Usuario
-		if err := config.DB.First(&usuario, *usuarioID).Error; err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Usuario vendedor no encontrado"})
-			return
+		if err := tx.First(&usuario, *req.UsuarioID).Error; err != nil {
+			return models.Venta{}, &ventaError{http.StatusBadRequest, "Usuario vendedor no encontrado"}
 		}
 		if usuario.Rol != "empleado" && usuario.Rol != "dueño" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "El usuario seleccionado no es un vendedor"})
-			return
+			return models.Venta{}, &ventaError{http.StatusBadRequest, "El usuario seleccionado no es un vendedor"}
 		}
-		vendedorID = *usuarioID
+		vendedorID = *req.UsuarioID
 	} else {
 		// Si no se especifica, usar el usuario autenticado
-		vendedorID = c.GetInt("user_id")
+		vendedorID = usuarioAutenticadoID
+	}
+
+	if len(req.Detalles) == 0 && len(req.Combos) == 0 {
+		return models.Venta{}, &ventaError{http.StatusBadRequest, "La venta debe incluir al menos un producto o combo"}
 	}
 
-	// Calcular el total de la venta (suma de productos)
+	// Los combos se venden como un renglón por componente
+	detallesCombos, comboIDs, err := expandirCombos(tx, req.Combos)
+	if err != nil {
+		return models.Venta{}, err
+	}
+
+	// Identificar el stock de cada renglón y calcular el total de la venta (suma de productos)
+	renglones, err := identificarRenglones(tx, append(append([]models.VentaDetalleCreateRequest{}, req.Detalles...), detallesCombos...))
+	if err != nil {
+		return models.Venta{}, err
+	}
+	for i := range comboIDs {
+		renglones[len(req.Detalles)+i].comboID = &comboIDs[i]
+	}
 	var total float64
-	for _, detalle := range detalles {
-		total += detalle.PrecioUnitario * float64(detalle.Cantidad)
+	for _, renglon := range renglones {
+		total += renglon.detalle.PrecioUnitario * float64(renglon.detalle.Cantidad)
 	}
 
-	// Calcular el saldo (lo que resta pagar)
-	saldo := total - sena
+	// Promociones automáticas y cupón de cada renglón
+	promociones := make([]*models.PromocionLinea, len(renglones))
+	if conPromociones {
+		if promociones, err = aplicarPromociones(tx, renglones, req.Cupon, time.Now()); err != nil {
+			return models.Venta{}, err
+		}
+	}
+	if err := registrarUsoPromociones(tx, promociones); err != nil {
+		return models.Venta{}, err
+	}
+	var descuentoPromociones float64
+	for _, p := range promociones {
+		if p != nil {
+			descuentoPromociones += p.Descuento
+		}
+	}
 
-	// Calcular descuento según forma de pago
-	var descuento float64
 	var formaPago models.FormaPago
-	var usaFinanciera bool
-
-	if err := config.DB.First(&formaPago, formaPagoID).Error; err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Forma de pago no encontrada"})
-		return
+	if err := tx.First(&formaPago, req.FormaPagoID).Error; err != nil {
+		return models.Venta{}, &ventaError{http.StatusBadRequest, "Forma de pago no encontrada"}
 	}
 
-	// Si es "Transferencia Financiera" (ID 1), aplicar 3% de descuento sobre el SALDO
-	if formaPago.Nombre == "Transferencia Financiera" {
-		descuento = saldo * 0.03
-		usaFinanciera = true
+	// Saldo, 3% de financiera y total final según el desglose de descuentos
+	venta := models.Venta{
+		UsuarioID:            vendedorID,
+		ClienteID:            req.ClienteID,
+		FormaPagoID:          req.FormaPagoID,
+		Total:                total,
+		Sena:                 req.Sena,
+		UsaFinanciera:        formaPago.Nombre == "Transferencia Financiera",
+		DescuentoPactado:     descuentoPactado,
+		DescuentoPromociones: redondear(descuentoPromociones),
+		ComprobanteURL:       comprobanteURL,
+	}
+	if codigo := normalizarCupon(req.Cupon); codigo != "" {
+		venta.Cupon = &codigo
+	}
+	if venta.CanalVenta, venta.CampanaID, err = origenVenta(tx, req.CanalVenta, req.CampanaID); err != nil {
+		return models.Venta{}, err
 	}
 
-	// Total final = Total - Descuento
-	totalFinal := total - descuento
+	// Puntos del cliente canjeados como descuento (se descuentan del libro al crear la venta)
+	programa, err := programaPuntos(tx)
+	if err != nil {
+		return models.Venta{}, err
+	}
+	if req.PuntosCanje > 0 {
+		if !programa.Activo {
+			return models.Venta{}, &ventaError{http.StatusBadRequest, "El programa de puntos no está activo"}
+		}
+		venta.PuntosCanjeados = req.PuntosCanje
+		venta.DescuentoPuntos = redondear(float64(req.PuntosCanje) * programa.ValorPunto)
+		if venta.DescuentoPuntos > redondear(total-descuentoPactado-venta.DescuentoPromociones-req.Sena)+0.005 {
+			return models.Venta{}, &ventaError{http.StatusBadRequest, "Los puntos canjeados superan el saldo a pagar"}
+		}
+	}
 
-	// Manejar observaciones
-	var obs *string
-	if observaciones != "" {
-		obs = &observaciones
+	// Parte del saldo pagada con gift card o saldo a favor
+	var saldoAFavor models.SaldoAFavor
+	if req.SaldoAFavorCodigo != "" {
+		pendiente := redondear(total - descuentoPactado - venta.DescuentoPromociones - venta.DescuentoPuntos - req.Sena)
+		saldoAFavor, venta.PagoSaldoAFavor, err = montoSaldoAFavor(tx, req.SaldoAFavorCodigo, req.SaldoAFavorMonto, pendiente)
+		if err != nil {
+			return models.Venta{}, err
+		}
 	}
+	recalcularDescuentos(&venta)
+	venta.PuntosGanados = puntosGanados(programa, venta.TotalFinal)
 
-	// Iniciar transacción
-	tx := config.DB.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
+	// Verificar el límite de crédito del cliente para lo que queda pendiente
+	var cliente models.Cliente
+	if err := tx.First(&cliente, req.ClienteID).Error; err != nil {
+		return models.Venta{}, &ventaError{http.StatusBadRequest, "Cliente no encontrado"}
+	}
+	if pendiente := venta.TotalFinal - req.Sena - venta.PagoSaldoAFavor; cliente.LimiteCredito != nil && pendiente > 0 {
+		deuda, err := saldoCuentaCorriente(tx, cliente.ID)
+		if err != nil {
+			return models.Venta{}, err
 		}
-	}()
+		if deuda+pendiente > *cliente.LimiteCredito+0.005 {
+			disponible := math.Max(*cliente.LimiteCredito-deuda, 0)
+			return models.Venta{}, &ventaError{http.StatusBadRequest, "La venta supera el límite de crédito del cliente (disponible: " + utils.FormatoMoneda(disponible) + ")"}
+		}
+	}
 
-	// Crear la venta
-	venta := models.Venta{
-		UsuarioID:      vendedorID,
-		ClienteID:      clienteID,
-		FormaPagoID:    formaPagoID,
-		Total:          total,
-		Sena:           sena,
-		Saldo:          saldo,
-		Descuento:      descuento,
-		TotalFinal:     totalFinal,
-		UsaFinanciera:  usaFinanciera,
-		ComprobanteURL: comprobanteURL,
-		Observaciones:  obs,
+	// Manejar observaciones
+	if req.Observaciones != "" {
+		venta.Observaciones = &req.Observaciones
 	}
 
 	if err := tx.Create(&venta).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al crear venta"})
-		return
+		return models.Venta{}, err
+	}
+	if venta.PagoSaldoAFavor > 0 {
+		if err := consumirSaldoAFavor(tx, saldoAFavor, venta.PagoSaldoAFavor, venta.ID, usuarioAutenticadoID); err != nil {
+			return models.Venta{}, &ventaError{http.StatusInternalServerError, "Error al registrar pago con saldo a favor"}
+		}
+	}
+	if venta.PuntosCanjeados > 0 {
+		if err := canjearPuntos(tx, venta.ClienteID, venta.PuntosCanjeados, venta.ID, usuarioAutenticadoID); err != nil {
+			return models.Venta{}, err
+		}
+	}
+	if venta.PuntosGanados > 0 {
+		if err := acreditarPuntos(tx, programa, venta.ClienteID, venta.PuntosGanados, &venta.ID, models.MovimientoPuntosAcreditacion, usuarioAutenticadoID); err != nil {
+			return models.Venta{}, &ventaError{http.StatusInternalServerError, "Error al acreditar puntos"}
+		}
 	}
 
-	for _, detalleReq := range detalles {
+	for i, renglon := range renglones {
+		detalleReq := renglon.detalle
 		subtotal := detalleReq.PrecioUnitario * float64(detalleReq.Cantidad)
 
+		// Releer el stock (ya bloqueado) por si otro renglón de la misma variante lo descontó
+		var stock models.ProductoStock
+		if err := tx.First(&stock, renglon.stock.ID).Error; err != nil {
+			return models.Venta{}, &ventaError{http.StatusInternalServerError, "Error al obtener stock"}
+		}
+		variante, err := asegurarVariante(tx, stock)
+		if err != nil {
+			return models.Venta{}, &ventaError{http.StatusInternalServerError, "Error al obtener variante"}
+		}
+
+		// Guardar el costo vigente para que los reportes no cambien si después se actualiza
+		costo := renglon.producto.CostoUnitario
 		detalle := models.VentaDetalle{
 			VentaID:        venta.ID,
-			ProductoID:     detalleReq.ProductoID,
-			Talle:          detalleReq.Talle,
+			ProductoID:     stock.ProductoID,
+			Talle:          string(stock.Talle),
+			Color:          string(stock.Color),
+			VarianteID:     &variante.ID,
 			Cantidad:       detalleReq.Cantidad,
 			PrecioUnitario: detalleReq.PrecioUnitario,
 			Subtotal:       subtotal,
+			CostoUnitario:  &costo,
+			ComboID:        renglon.comboID,
+		}
+		if p := promociones[i]; p != nil {
+			detalle.PromocionID = &p.PromocionID
+			detalle.Descuento = p.Descuento
 		}
 
 		if err := tx.Create(&detalle).Error; err != nil {
-			tx.Rollback()
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al crear detalle de venta"})
-			return
+			return models.Venta{}, &ventaError{http.StatusInternalServerError, "Error al crear detalle de venta"}
 		}
 
 		// Descontar del stock
-		var stock models.ProductoStock
-		if err := tx.Where("producto_id = ? AND talle = ?", detalleReq.ProductoID, detalleReq.Talle).First(&stock).Error; err != nil {
-			tx.Rollback()
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Stock no encontrado para el producto y talle especificado"})
-			return
-		}
-
 		if stock.Cantidad < detalleReq.Cantidad {
-			tx.Rollback()
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Stock insuficiente"})
-			return
+			return models.Venta{}, &ventaError{http.StatusBadRequest, "Stock insuficiente"}
 		}
 
 		stock.Cantidad -= detalleReq.Cantidad
 		if err := tx.Save(&stock).Error; err != nil {
-			tx.Rollback()
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar stock"})
-			return
+			return models.Venta{}, &ventaError{http.StatusInternalServerError, "Error al actualizar stock"}
 		}
 	}
 
@@ -269,114 +460,279 @@ func processVenta(c *gin.Context, usuarioID *int, clienteID int, formaPagoID int
 	}
 
 	if err := tx.Create(&pedido).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al crear pedido"})
-		return
+		return models.Venta{}, &ventaError{http.StatusInternalServerError, "Error al crear pedido"}
 	}
 
-	// Commit de la transacción
-	if err := tx.Commit().Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al confirmar venta"})
-		return
+	// Asentar la venta y la seña en la cuenta corriente del cliente
+	if err := registrarVentaEnCuentaCorriente(tx, venta, usuarioAutenticadoID); err != nil {
+		return models.Venta{}, &ventaError{http.StatusInternalServerError, "Error al registrar cuenta corriente"}
 	}
 
-	// Cargar la venta con todas sus relaciones
-	config.DB.
-		Preload("Usuario").
-		Preload("Cliente").
-		Preload("FormaPago").
-		Preload("Detalles").
-		Preload("Detalles.Producto").
-		First(&venta, venta.ID)
+	// La seña cobrada en efectivo entra en la caja abierta
+	if err := sincronizarCajaVenta(tx, venta, usuarioAutenticadoID); err != nil {
+		return models.Venta{}, &ventaError{http.StatusInternalServerError, "Error al registrar movimiento de caja"}
+	}
 
-	c.JSON(http.StatusCreated, venta)
+	return venta, nil
+}
+
+// recalcularDescuentos calcula el saldo, el 3% de transferencia financiera (sobre el saldo) y el
+// total final a partir del total, la seña, lo pagado con saldo a favor y los descuentos pactado, de promociones y por puntos
+func recalcularDescuentos(venta *models.Venta) {
+	venta.Saldo = venta.Total - venta.DescuentoPactado - venta.DescuentoPromociones - venta.DescuentoPuntos - venta.Sena - venta.PagoSaldoAFavor
+	venta.DescuentoFinanciera = 0
+	if venta.UsaFinanciera {
+		venta.DescuentoFinanciera = venta.Saldo * 0.03
+	}
+	venta.Descuento = venta.DescuentoPactado + venta.DescuentoPromociones + venta.DescuentoPuntos + venta.DescuentoFinanciera
+	venta.TotalFinal = venta.Total - venta.Descuento
 }
 
 // GetMisVentas godoc
 // @Summary Obtener mis ventas
-// @Description Obtiene las ventas del usuario autenticado
+// @Description Obtiene las ventas del usuario autenticado, filtradas y paginadas
 // @Tags Ventas
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {array} models.Venta
+// @Param fecha_desde query string false "Desde (YYYY-MM-DD)"
+// @Param fecha_hasta query string false "Hasta inclusive (YYYY-MM-DD)"
+// @Param cliente_id query int false "Filtrar por cliente"
+// @Param forma_pago_id query int false "Filtrar por forma de pago"
+// @Param con_saldo query bool false "Solo ventas con saldo pendiente"
+// @Param producto_id query int false "Ventas que incluyen el producto"
+// @Param equipo_id query int false "Ventas que incluyen productos del equipo"
+// @Param canal_venta query string false "Canal de venta"
+// @Param campana_id query int false "Campaña de origen"
+// @Param total_min query number false "Total final mínimo"
+// @Param total_max query number false "Total final máximo"
+// @Param ordenar_por query string false "fecha_venta (por defecto), total_final, saldo o id"
+// @Param orden query string false "desc (por defecto) o asc"
+// @Param page query int false "Página (por defecto 1)"
+// @Param limit query int false "Resultados por página (por defecto 50, máximo 500)"
+// @Success 200 {object} map[string]interface{} "ventas, total, page, limit"
+// @Failure 400 {object} map[string]string "Parámetro inválido"
 // @Failure 500 {object} map[string]string "Error interno"
 // @Router /api/mis-ventas [get]
 func GetMisVentas(c *gin.Context) {
 	userID := c.GetInt("user_id")
 
-	var ventas []models.Venta
-	if err := config.DB.
-		Where("usuario_id = ?", userID).
-		Preload("Cliente").
-		Preload("FormaPago").
-		Preload("Detalles").
-		Preload("Detalles.Producto").
-		Order("fecha_venta DESC").
-		Find(&ventas).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener ventas"})
-		return
-	}
-
-	c.JSON(http.StatusOK, ventas)
+	listarVentas(c, config.DB.Where("usuario_id = ?", userID), false)
 }
 
 // GetVentas godoc
 // @Summary Listar todas las ventas
-// @Description Obtiene todas las ventas (solo dueño)
+// @Description Obtiene las ventas de todos los vendedores, filtradas y paginadas (solo dueño)
 // @Tags Ventas
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {array} models.Venta
+// @Param usuario_id query int false "Filtrar por vendedor"
+// @Param fecha_desde query string false "Desde (YYYY-MM-DD)"
+// @Param fecha_hasta query string false "Hasta inclusive (YYYY-MM-DD)"
+// @Param cliente_id query int false "Filtrar por cliente"
+// @Param forma_pago_id query int false "Filtrar por forma de pago"
+// @Param con_saldo query bool false "Solo ventas con saldo pendiente"
+// @Param producto_id query int false "Ventas que incluyen el producto"
+// @Param equipo_id query int false "Ventas que incluyen productos del equipo"
+// @Param canal_venta query string false "Canal de venta"
+// @Param campana_id query int false "Campaña de origen"
+// @Param total_min query number false "Total final mínimo"
+// @Param total_max query number false "Total final máximo"
+// @Param ordenar_por query string false "fecha_venta (por defecto), total_final, saldo o id"
+// @Param orden query string false "desc (por defecto) o asc"
+// @Param page query int false "Página (por defecto 1)"
+// @Param limit query int false "Resultados por página (por defecto 50, máximo 500)"
+// @Success 200 {object} map[string]interface{} "ventas, total, page, limit"
+// @Failure 400 {object} map[string]string "Parámetro inválido"
 // @Failure 500 {object} map[string]string "Error interno"
 // @Router /api/owner/ventas [get]
 func GetVentas(c *gin.Context) {
-	var ventas []models.Venta
-	if err := config.DB.
-		Preload("Usuario").
-		Preload("Cliente").
-		Preload("FormaPago").
-		Preload("Detalles").
-		Preload("Detalles.Producto").
-		Order("fecha_venta DESC").
-		Find(&ventas).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener ventas"})
-		return
+	query := config.DB
+	if usuarioID := c.Query("usuario_id"); usuarioID != "" {
+		id, err := strconv.Atoi(usuarioID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parámetro inválido: usuario_id"})
+			return
+		}
+		query = query.Where("usuario_id = ?", id)
 	}
 
-	c.JSON(http.StatusOK, ventas)
+	listarVentas(c, query, true)
 }
 
 // GetVentasByUsuario godoc
 // @Summary Obtener ventas por usuario
-// @Description Obtiene las ventas de un usuario específico (solo dueño)
+// @Description Obtiene las ventas de un usuario específico, filtradas y paginadas (solo dueño)
 // @Tags Ventas
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "ID del usuario"
-// @Success 200 {array} models.Venta
+// @Param fecha_desde query string false "Desde (YYYY-MM-DD)"
+// @Param fecha_hasta query string false "Hasta inclusive (YYYY-MM-DD)"
+// @Param cliente_id query int false "Filtrar por cliente"
+// @Param forma_pago_id query int false "Filtrar por forma de pago"
+// @Param con_saldo query bool false "Solo ventas con saldo pendiente"
+// @Param producto_id query int false "Ventas que incluyen el producto"
+// @Param equipo_id query int false "Ventas que incluyen productos del equipo"
+// @Param canal_venta query string false "Canal de venta"
+// @Param campana_id query int false "Campaña de origen"
+// @Param total_min query number false "Total final mínimo"
+// @Param total_max query number false "Total final máximo"
+// @Param ordenar_por query string false "fecha_venta (por defecto), total_final, saldo o id"
+// @Param orden query string false "desc (por defecto) o asc"
+// @Param page query int false "Página (por defecto 1)"
+// @Param limit query int false "Resultados por página (por defecto 50, máximo 500)"
+// @Success 200 {object} map[string]interface{} "ventas, total, page, limit"
+// @Failure 400 {object} map[string]string "Parámetro inválido"
 // @Failure 500 {object} map[string]string "Error interno"
 // @Router /api/owner/ventas/usuario/{id} [get]
 func GetVentasByUsuario(c *gin.Context) {
 	usuarioID := c.Param("id")
 
-	var ventas []models.Venta
-	if err := config.DB.
-		Where("usuario_id = ?", usuarioID).
-		Preload("Usuario").
-		Preload("Cliente").
+	listarVentas(c, config.DB.Where("usuario_id = ?", usuarioID), true)
+}
+
+// Columnas por las que se puede ordenar el listado de ventas
+var ordenesVenta = map[string]string{
+	"fecha_venta": "fecha_venta",
+	"total_final": "total_final",
+	"saldo":       "saldo",
+	"id":          "id",
+}
+
+// listarVentas aplica los filtros comunes, ordena y pagina. Las relaciones se precargan
+// solo para la página pedida.
+func listarVentas(c *gin.Context, query *gorm.DB, conUsuario bool) {
+	query, err := filtrarVentas(c, query.Model(&models.Venta{}))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Ordenamiento
+	columna, ok := ordenesVenta[c.DefaultQuery("ordenar_por", "fecha_venta")]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parámetro inválido: ordenar_por"})
+		return
+	}
+	direccion := "DESC"
+	if strings.EqualFold(c.Query("orden"), "asc") {
+		direccion = "ASC"
+	}
+
+	// Paginación
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit < 1 || limit > 500 {
+		limit = 50
+	}
+
+	// Contar total
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener ventas"})
+		return
+	}
+
+	if conUsuario {
+		query = query.Preload("Usuario")
+	}
+
+	// El id desempata para que la paginación sea estable
+	ventas := []models.Venta{}
+	if err := query.
+		Preload("Cliente", conArchivados).
 		Preload("FormaPago").
 		Preload("Detalles").
 		Preload("Detalles.Producto").
-		Order("fecha_venta DESC").
+		Order(columna + " " + direccion).
+		Order("id " + direccion).
+		Limit(limit).
+		Offset((page - 1) * limit).
 		Find(&ventas).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener ventas"})
 		return
 	}
 
-	c.JSON(http.StatusOK, ventas)
+	c.JSON(http.StatusOK, gin.H{
+		"ventas": ventas,
+		"total":  total,
+		"page":   page,
+		"limit":  limit,
+	})
+}
+
+// filtrarVentas aplica los filtros opcionales del listado de ventas
+func filtrarVentas(c *gin.Context, query *gorm.DB) (*gorm.DB, error) {
+	if fechaDesde := c.Query("fecha_desde"); fechaDesde != "" {
+		desde, err := time.Parse("2006-01-02", fechaDesde)
+		if err != nil {
+			return nil, fmt.Errorf("Parámetro inválido: fecha_desde")
+		}
+		query = query.Where("fecha_venta >= ?", desde)
+	}
+
+	if fechaHasta := c.Query("fecha_hasta"); fechaHasta != "" {
+		hasta, err := time.Parse("2006-01-02", fechaHasta)
+		if err != nil {
+			return nil, fmt.Errorf("Parámetro inválido: fecha_hasta")
+		}
+		// Incluye el día completo
+		query = query.Where("fecha_venta < ?", hasta.AddDate(0, 0, 1))
+	}
+
+	enteros := []struct {
+		parametro string
+		condicion string
+	}{
+		{"cliente_id", "cliente_id = ?"},
+		{"forma_pago_id", "forma_pago_id = ?"},
+		{"producto_id", "EXISTS (SELECT 1 FROM venta_detalles vd WHERE vd.venta_id = venta.id AND vd.producto_id = ?)"},
+		{"equipo_id", "EXISTS (SELECT 1 FROM venta_detalles vd JOIN productos p ON p.id = vd.producto_id WHERE vd.venta_id = venta.id AND p.equipo_id = ?)"},
+		{"campana_id", "campana_id = ?"},
+	}
+	for _, f := range enteros {
+		valor := c.Query(f.parametro)
+		if valor == "" {
+			continue
+		}
+		id, err := strconv.Atoi(valor)
+		if err != nil {
+			return nil, fmt.Errorf("Parámetro inválido: %s", f.parametro)
+		}
+		query = query.Where(f.condicion, id)
+	}
+
+	if canal := c.Query("canal_venta"); canal != "" {
+		query = query.Where("canal_venta = ?", canal)
+	}
+
+	if c.Query("con_saldo") == "true" {
+		query = query.Where("saldo > 0")
+	}
+
+	if totalMin := c.Query("total_min"); totalMin != "" {
+		valor, err := strconv.ParseFloat(totalMin, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Parámetro inválido: total_min")
+		}
+		query = query.Where("total_final >= ?", valor)
+	}
+
+	if totalMax := c.Query("total_max"); totalMax != "" {
+		valor, err := strconv.ParseFloat(totalMax, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Parámetro inválido: total_max")
+		}
+		query = query.Where("total_final <= ?", valor)
+	}
+
+	return query, nil
 }
 
 // GetVentaComprobante godoc
@@ -454,7 +810,7 @@ func DeleteVentaComprobante(c *gin.Context) {
 
 // UpdateVenta godoc
 // @Summary Actualizar venta
-// @Description Actualiza los datos de una venta existente (solo campos básicos, no detalles)
+// @Description Actualiza los datos de una venta existente (solo campos básicos, no detalles). No se puede cambiar el cliente de una venta pagada con saldo a favor. Si cambia el cliente o el total final, se mueven y recalculan los puntos de la venta.
 // @Tags Ventas
 // @Accept json
 // @Produce json
@@ -474,6 +830,7 @@ func UpdateVenta(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Venta no encontrada"})
 		return
 	}
+	anterior := venta
 
 	var req models.VentaUpdateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -503,6 +860,11 @@ func UpdateVenta(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Cliente no encontrado"})
 			return
 		}
+		// El saldo a favor usado como pago quedó consumido en la cuenta del cliente original
+		if *req.ClienteID != venta.ClienteID && venta.PagoSaldoAFavor > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "No se puede cambiar el cliente de una venta pagada con saldo a favor"})
+			return
+		}
 		venta.ClienteID = *req.ClienteID
 	}
 
@@ -514,35 +876,79 @@ func UpdateVenta(c *gin.Context) {
 			return
 		}
 		venta.FormaPagoID = *req.FormaPagoID
-
-		// Recalcular descuento si cambió la forma de pago
-		if formaPago.Nombre == "Transferencia Financiera" {
-			venta.UsaFinanciera = true
-			venta.Descuento = venta.Saldo * 0.03
-		} else {
-			venta.UsaFinanciera = false
-			venta.Descuento = 0
-		}
-		venta.TotalFinal = venta.Total - venta.Descuento
+		venta.UsaFinanciera = formaPago.Nombre == "Transferencia Financiera"
 	}
 
 	if req.Sena != nil {
 		venta.Sena = *req.Sena
-		venta.Saldo = venta.Total - venta.Sena
+	}
 
-		// Recalcular descuento con el nuevo saldo
-		if venta.UsaFinanciera {
-			venta.Descuento = venta.Saldo * 0.03
+	// Recalcular el 3% de financiera con la forma de pago y el saldo nuevos, conservando
+	// el descuento pactado y el de las promociones
+	if req.FormaPagoID != nil || req.Sena != nil {
+		recalcularDescuentos(&venta)
+
+		// Los pagos y notas de crédito ya imputados a la venta siguen cancelando parte del saldo
+		imputado, err := creditosImputados(config.DB, venta.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener pagos de la venta"})
+			return
 		}
-		venta.TotalFinal = venta.Total - venta.Descuento
+		venta.Saldo = math.Max(redondear(venta.Saldo-imputado), 0)
 	}
 
 	if req.Observaciones != nil {
 		venta.Observaciones = req.Observaciones
 	}
 
-	// Guardar cambios
-	if err := config.DB.Save(&venta).Error; err != nil {
+	if req.CanalVenta != nil || req.CampanaID != nil {
+		canal := ""
+		if venta.CanalVenta != nil {
+			canal = *venta.CanalVenta
+		}
+		if req.CanalVenta != nil {
+			canal = *req.CanalVenta
+		}
+		campanaID := venta.CampanaID
+		if req.CampanaID != nil {
+			campanaID = req.CampanaID
+		}
+		var err error
+		if venta.CanalVenta, venta.CampanaID, err = origenVenta(config.DB, canal, campanaID); err != nil {
+			responderError(c, err, "Error al validar canal de venta")
+			return
+		}
+	}
+
+	// Guardar cambios junto con la cuenta corriente y los puntos
+	tx := config.DB.Begin()
+	if venta.ClienteID != anterior.ClienteID || venta.TotalFinal != anterior.TotalFinal {
+		if err := actualizarPuntosVenta(tx, anterior, &venta, c.GetInt("user_id")); err != nil {
+			tx.Rollback()
+			responderError(c, err, "Error al actualizar puntos")
+			return
+		}
+	}
+
+	if err := tx.Save(&venta).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar venta"})
+		return
+	}
+
+	if err := sincronizarCuentaCorrienteVenta(tx, venta, c.GetInt("user_id")); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar cuenta corriente"})
+		return
+	}
+
+	if err := sincronizarCajaVenta(tx, venta, c.GetInt("user_id")); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar caja"})
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar venta"})
 		return
 	}
@@ -550,7 +956,7 @@ func UpdateVenta(c *gin.Context) {
 	// Cargar la venta con todas sus relaciones
 	config.DB.
 		Preload("Usuario").
-		Preload("Cliente").
+		Preload("Cliente", conArchivados).
 		Preload("FormaPago").
 		Preload("Detalles").
 		Preload("Detalles.Producto").
@@ -561,12 +967,13 @@ func UpdateVenta(c *gin.Context) {
 
 // DeleteVenta godoc
 // @Summary Eliminar venta
-// @Description Elimina una venta y restaura el stock de los productos
+// @Description Elimina una venta y restaura el stock de los productos. Anula el saldo a favor emitido por sus devoluciones; si ya se usó, la venta no se puede eliminar.
 // @Tags Ventas
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "ID de la venta"
 // @Success 200 {object} map[string]string "Venta eliminada"
+// @Failure 400 {object} map[string]string "El saldo a favor de una devolución ya se usó"
 // @Failure 404 {object} map[string]string "Venta no encontrada"
 // @Failure 500 {object} map[string]string "Error interno"
 // @Router /api/ventas/{id} [delete]
@@ -587,11 +994,10 @@ func DeleteVenta(c *gin.Context) {
 		}
 	}()
 
-	// Restaurar stock de cada detalle
+	// Restaurar stock de cada detalle (las unidades devueltas ya volvieron al stock)
 	for _, detalle := range venta.Detalles {
-		var stock models.ProductoStock
-		if err := tx.Where("producto_id = ? AND talle = ?", detalle.ProductoID, detalle.Talle).First(&stock).Error; err == nil {
-			stock.Cantidad += detalle.Cantidad
+		if stock, err := stockDeDetalle(tx, detalle); err == nil {
+			stock.Cantidad += detalle.Cantidad - detalle.CantidadDevuelta
 			if err := tx.Save(&stock).Error; err != nil {
 				tx.Rollback()
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al restaurar stock"})
@@ -619,6 +1025,43 @@ func DeleteVenta(c *gin.Context) {
 		return
 	}
 
+	if err := anularSaldosDevolucion(tx, venta.ID, c.GetInt("user_id")); err != nil {
+		tx.Rollback()
+		responderError(c, err, "Error al anular saldo a favor de devoluciones")
+		return
+	}
+
+	if err := reintegrarSaldoAFavor(tx, venta.ID, c.GetInt("user_id")); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al reintegrar saldo a favor"})
+		return
+	}
+
+	if err := anularPuntosVenta(tx, venta, c.GetInt("user_id")); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al anular puntos"})
+		return
+	}
+
+	if err := devolverUsoPromociones(tx, venta.Detalles); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar promociones"})
+		return
+	}
+
+	// Quitar la venta de la cuenta corriente del cliente
+	if err := anularVentaEnCuentaCorriente(tx, venta.ID); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar cuenta corriente"})
+		return
+	}
+
+	if err := anularCajaVenta(tx, venta.ID); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar caja"})
+		return
+	}
+
 	// Eliminar detalles de la venta
 	if err := tx.Where("venta_id = ?", venta.ID).Delete(&models.VentaDetalle{}).Error; err != nil {
 		tx.Rollback()
@@ -658,7 +1101,7 @@ func GetVenta(c *gin.Context) {
 	var venta models.Venta
 	if err := config.DB.
 		Preload("Usuario").
-		Preload("Cliente").
+		Preload("Cliente", conArchivados).
 		Preload("FormaPago").
 		Preload("Detalles").
 		Preload("Detalles.Producto").
@@ -0,0 +1,346 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"vartan-backend/config"
+	"vartan-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetTalles godoc
+// @Summary Listar talles
+// @Description Obtiene los talles activos en orden. Con todos=true incluye los inactivos.
+// @Tags Talles y colores
+// @Produce json
+// @Security BearerAuth
+// @Param todos query bool false "Incluir inactivos"
+// @Success 200 {array} models.Talle
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/talles [get]
+func GetTalles(c *gin.Context) {
+	query := config.DB.Order("orden, nombre")
+	if c.Query("todos") != "true" {
+		query = query.Where("activo = ?", true)
+	}
+
+	talles := []models.Talle{}
+	if err := query.Find(&talles).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener talles"})
+		return
+	}
+
+	c.JSON(http.StatusOK, talles)
+}
+
+// CreateTalle godoc
+// @Summary Crear talle
+// @Description Crea un talle nuevo (solo dueño)
+// @Tags Talles y colores
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.TalleColorCreateRequest true "Datos del talle"
+// @Success 201 {object} models.Talle
+// @Failure 400 {object} map[string]string "Datos inválidos o talle existente"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/talles [post]
+func CreateTalle(c *gin.Context) {
+	var req models.TalleColorCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+
+	talle := models.Talle{Nombre: strings.TrimSpace(req.Nombre), Activo: true}
+	if len(talle.Nombre) > 10 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "El talle no puede superar los 10 caracteres"})
+		return
+	}
+
+	var count int64
+	config.DB.Model(&models.Talle{}).Where("LOWER(nombre) = LOWER(?)", talle.Nombre).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ya existe el talle " + talle.Nombre})
+		return
+	}
+
+	talle.Orden = siguienteOrden(config.DB.Model(&models.Talle{}), req.Orden)
+
+	if err := config.DB.Create(&talle).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al crear talle"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, talle)
+}
+
+// UpdateTalle godoc
+// @Summary Actualizar talle
+// @Description Cambia el orden o desactiva un talle (solo dueño). Desactivarlo no afecta el stock existente.
+// @Tags Talles y colores
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID del talle"
+// @Param request body models.TalleColorUpdateRequest true "Datos actualizados"
+// @Success 200 {object} models.Talle
+// @Failure 400 {object} map[string]string "Datos inválidos"
+// @Failure 404 {object} map[string]string "Talle no encontrado"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/talles/{id} [put]
+func UpdateTalle(c *gin.Context) {
+	var talle models.Talle
+	if err := config.DB.First(&talle, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Talle no encontrado"})
+		return
+	}
+
+	var req models.TalleColorUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+
+	if req.Orden != nil {
+		talle.Orden = *req.Orden
+	}
+	if req.Activo != nil {
+		talle.Activo = *req.Activo
+	}
+
+	if err := config.DB.Save(&talle).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar talle"})
+		return
+	}
+
+	c.JSON(http.StatusOK, talle)
+}
+
+// GetColores godoc
+// @Summary Listar colores
+// @Description Obtiene los colores activos en orden. Con todos=true incluye los inactivos.
+// @Tags Talles y colores
+// @Produce json
+// @Security BearerAuth
+// @Param todos query bool false "Incluir inactivos"
+// @Success 200 {array} models.Color
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/colores [get]
+func GetColores(c *gin.Context) {
+	query := config.DB.Order("orden, nombre")
+	if c.Query("todos") != "true" {
+		query = query.Where("activo = ?", true)
+	}
+
+	colores := []models.Color{}
+	if err := query.Find(&colores).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener colores"})
+		return
+	}
+
+	c.JSON(http.StatusOK, colores)
+}
+
+// CreateColor godoc
+// @Summary Crear color
+// @Description Crea un color nuevo (solo dueño)
+// @Tags Talles y colores
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.TalleColorCreateRequest true "Datos del color"
+// @Success 201 {object} models.Color
+// @Failure 400 {object} map[string]string "Datos inválidos o color existente"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/colores [post]
+func CreateColor(c *gin.Context) {
+	var req models.TalleColorCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+
+	color := models.Color{Nombre: strings.TrimSpace(req.Nombre), Activo: true}
+	if len([]rune(color.Nombre)) > 20 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "El color no puede superar los 20 caracteres"})
+		return
+	}
+
+	var count int64
+	config.DB.Model(&models.Color{}).Where("LOWER(nombre) = LOWER(?)", color.Nombre).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ya existe el color " + color.Nombre})
+		return
+	}
+
+	color.Orden = siguienteOrden(config.DB.Model(&models.Color{}), req.Orden)
+
+	if err := config.DB.Create(&color).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al crear color"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, color)
+}
+
+// UpdateColor godoc
+// @Summary Actualizar color
+// @Description Cambia el orden o desactiva un color (solo dueño). Desactivarlo no afecta el stock existente.
+// @Tags Talles y colores
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID del color"
+// @Param request body models.TalleColorUpdateRequest true "Datos actualizados"
+// @Success 200 {object} models.Color
+// @Failure 400 {object} map[string]string "Datos inválidos"
+// @Failure 404 {object} map[string]string "Color no encontrado"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/colores/{id} [put]
+func UpdateColor(c *gin.Context) {
+	var color models.Color
+	if err := config.DB.First(&color, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Color no encontrado"})
+		return
+	}
+
+	var req models.TalleColorUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+
+	if req.Orden != nil {
+		color.Orden = *req.Orden
+	}
+	if req.Activo != nil {
+		color.Activo = *req.Activo
+	}
+
+	if err := config.DB.Save(&color).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar color"})
+		return
+	}
+
+	c.JSON(http.StatusOK, color)
+}
+
+// UpdateTallesTipoProducto godoc
+// @Summary Definir talles de un tipo de producto
+// @Description Restringe los talles que pueden usar los productos del tipo (por ejemplo talles de niño para camisetas infantiles). Una lista vacía permite todos (solo dueño).
+// @Tags TiposProducto
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID del tipo de producto"
+// @Param request body models.TipoProductoTallesRequest true "Talles permitidos"
+// @Success 200 {object} models.TipoProducto
+// @Failure 400 {object} map[string]string "Datos inválidos"
+// @Failure 404 {object} map[string]string "Tipo de producto no encontrado"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/tipos-producto/{id}/talles [put]
+func UpdateTallesTipoProducto(c *gin.Context) {
+	var tipo models.TipoProducto
+	if err := config.DB.First(&tipo, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tipo de producto no encontrado"})
+		return
+	}
+
+	var req models.TipoProductoTallesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+
+	talles := []models.Talle{}
+	if len(req.TalleIDs) > 0 {
+		if err := config.DB.Where("id IN ?", req.TalleIDs).Find(&talles).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener talles"})
+			return
+		}
+		if len(talles) != len(req.TalleIDs) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Alguno de los talles no existe"})
+			return
+		}
+	}
+
+	if err := config.DB.Model(&tipo).Association("Talles").Replace(talles); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar talles del tipo de producto"})
+		return
+	}
+
+	config.DB.Preload("Talles", func(db *gorm.DB) *gorm.DB { return db.Order("orden") }).First(&tipo, tipo.ID)
+	c.JSON(http.StatusOK, tipo)
+}
+
+// validarTallesColores verifica contra la base que los talles y colores existan y estén activos,
+// y que los talles estén permitidos para el tipo de producto
+func validarTallesColores(db *gorm.DB, tipoProductoID *int, talles []models.TalleEnum, colores []models.ColorEnum) error {
+	if len(talles) > 0 {
+		nombres := make([]string, len(talles))
+		for i, t := range talles {
+			nombres[i] = string(t)
+		}
+
+		var validos []string
+		query := db.Model(&models.Talle{}).Where("activo = ? AND nombre IN ?", true, nombres)
+		// Si el tipo tiene talles definidos, solo se aceptan esos
+		if tipoProductoID != nil {
+			var definidos int64
+			db.Table("tipo_producto_talles").Where("tipo_producto_id = ?", *tipoProductoID).Count(&definidos)
+			if definidos > 0 {
+				query = query.Where("id IN (?)", db.Table("tipo_producto_talles").Select("talle_id").Where("tipo_producto_id = ?", *tipoProductoID))
+			}
+		}
+		if err := query.Pluck("nombre", &validos).Error; err != nil {
+			return err
+		}
+		if faltante := primerFaltante(nombres, validos); faltante != "" {
+			return &ventaError{http.StatusBadRequest, "Talle inválido: " + faltante}
+		}
+	}
+
+	if len(colores) > 0 {
+		nombres := make([]string, len(colores))
+		for i, col := range colores {
+			nombres[i] = string(col)
+		}
+
+		var validos []string
+		if err := db.Model(&models.Color{}).Where("activo = ? AND nombre IN ?", true, nombres).Pluck("nombre", &validos).Error; err != nil {
+			return err
+		}
+		if faltante := primerFaltante(nombres, validos); faltante != "" {
+			return &ventaError{http.StatusBadRequest, "Color inválido: " + faltante}
+		}
+	}
+
+	return nil
+}
+
+// primerFaltante devuelve el primer nombre pedido que no está entre los válidos
+func primerFaltante(pedidos, validos []string) string {
+	existe := make(map[string]bool, len(validos))
+	for _, v := range validos {
+		existe[v] = true
+	}
+	for _, p := range pedidos {
+		if !existe[p] {
+			return p
+		}
+	}
+	return ""
+}
+
+// siguienteOrden devuelve el orden pedido o el siguiente al último existente
+func siguienteOrden(query *gorm.DB, orden *int) int {
+	if orden != nil {
+		return *orden
+	}
+	var maximo int
+	query.Select("COALESCE(MAX(orden), 0)").Scan(&maximo)
+	return maximo + 1
+}
@@ -6,6 +6,7 @@ import (
 	"vartan-backend/models"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // GetTiposProducto godoc
@@ -44,7 +45,7 @@ func GetTipoProducto(c *gin.Context) {
 	id := c.Param("id")
 
 	var tipo models.TipoProducto
-	if err := config.DB.First(&tipo, id).Error; err != nil {
+	if err := config.DB.Preload("Talles", func(db *gorm.DB) *gorm.DB { return db.Order("orden") }).First(&tipo, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Tipo de producto no encontrado"})
 		return
 	}
@@ -82,7 +82,6 @@ func UpdateComisionConfig(c *gin.Context) {
 
 	// Actualizar configuración
 	usuario.PorcentajeComision = req.PorcentajeComision
-	usuario.GastoPublicitario = req.GastoPublicitario
 	usuario.Sueldo = req.Sueldo
 	usuario.ObservacionesConfig = req.Observaciones
 
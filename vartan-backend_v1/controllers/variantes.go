@@ -0,0 +1,303 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"vartan-backend/config"
+	"vartan-backend/models"
+	"vartan-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetVariantePorCodigo godoc
+// @Summary Buscar variante por código
+// @Description Busca una variante por código de barras o SKU (para escanear en la pantalla de ventas)
+// @Tags Variantes
+// @Produce json
+// @Security BearerAuth
+// @Param code path string true "Código de barras o SKU"
+// @Success 200 {object} models.VarianteResponse
+// @Failure 404 {object} map[string]string "Variante no encontrada"
+// @Router /api/variantes/codigo/{code} [get]
+func GetVariantePorCodigo(c *gin.Context) {
+	codigo := strings.TrimSpace(c.Param("code"))
+
+	var variante models.VarianteProducto
+	err := config.DB.Preload("Producto").
+		Where("codigo_barras = ? OR UPPER(sku) = UPPER(?)", codigo, codigo).
+		First(&variante).Error
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Variante no encontrada"})
+		return
+	}
+
+	var cantidad int
+	config.DB.Model(&models.ProductoStock{}).Where("id = ?", variante.StockID).Select("cantidad").Scan(&cantidad)
+
+	c.JSON(http.StatusOK, models.VarianteResponse{VarianteProducto: variante, Cantidad: cantidad})
+}
+
+// GetVariantesProducto godoc
+// @Summary Listar variantes de un producto
+// @Description Obtiene las variantes de un producto con su SKU, código de barras y stock
+// @Tags Variantes
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID del producto"
+// @Success 200 {array} models.VarianteResponse
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/productos/{id}/variantes [get]
+func GetVariantesProducto(c *gin.Context) {
+	variantes := []models.VarianteResponse{}
+	err := config.DB.Table("variantes_producto v").
+		Select("v.*, s.cantidad").
+		Joins("JOIN producto_stocks s ON s.id = v.stock_id").
+		Joins("LEFT JOIN talles t ON t.nombre = v.talle").
+		Where("v.producto_id = ?", c.Param("id")).
+		Order("t.orden, v.talle, v.color").
+		Scan(&variantes).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener variantes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, variantes)
+}
+
+// UpdateVariante godoc
+// @Summary Actualizar variante
+// @Description Cambia el SKU o el código de barras de una variante, por ejemplo para usar el EAN del proveedor (solo dueño)
+// @Tags Variantes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID de la variante"
+// @Param request body models.VarianteUpdateRequest true "Códigos nuevos"
+// @Success 200 {object} models.VarianteProducto
+// @Failure 400 {object} map[string]string "Datos inválidos o código repetido"
+// @Failure 404 {object} map[string]string "Variante no encontrada"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /api/owner/variantes/{id} [put]
+func UpdateVariante(c *gin.Context) {
+	var variante models.VarianteProducto
+	if err := config.DB.First(&variante, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Variante no encontrada"})
+		return
+	}
+
+	var req models.VarianteUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+
+	if req.SKU != nil {
+		sku := strings.ToUpper(strings.TrimSpace(*req.SKU))
+		if sku == "" || len(sku) > 50 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "El SKU debe tener entre 1 y 50 caracteres"})
+			return
+		}
+		var count int64
+		config.DB.Model(&models.VarianteProducto{}).Where("UPPER(sku) = ? AND id <> ?", sku, variante.ID).Count(&count)
+		if count > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Ya existe otra variante con el SKU " + sku})
+			return
+		}
+		variante.SKU = sku
+	}
+
+	if req.CodigoBarras != nil {
+		codigo := strings.TrimSpace(*req.CodigoBarras)
+		if codigo == "" || len(codigo) > 20 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "El código de barras debe tener entre 1 y 20 caracteres"})
+			return
+		}
+		// Un código de 13 dígitos se toma como EAN-13 y tiene que tener el verificador correcto
+		if len(codigo) == 13 && strings.Trim(codigo, "0123456789") == "" && !utils.EsEAN13(codigo) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "El dígito verificador del EAN-13 es incorrecto"})
+			return
+		}
+		var count int64
+		config.DB.Model(&models.VarianteProducto{}).Where("codigo_barras = ? AND id <> ?", codigo, variante.ID).Count(&count)
+		if count > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Ya existe otra variante con el código " + codigo})
+			return
+		}
+		variante.CodigoBarras = codigo
+	}
+
+	if err := config.DB.Save(&variante).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar variante"})
+		return
+	}
+
+	c.JSON(http.StatusOK, variante)
+}
+
+// Medidas de la hoja de etiquetas: A4 con 3 columnas y 8 filas (70 x 37 mm)
+const (
+	etiquetaColumnas = 3
+	etiquetaFilas    = 8
+	etiquetaAncho    = utils.A4Ancho / etiquetaColumnas
+	etiquetaAlto     = 104.88
+	etiquetaMargenY  = (utils.A4Alto - etiquetaFilas*etiquetaAlto) / 2
+)
+
+// GenerarEtiquetasVariantes godoc
+// @Summary Hoja de etiquetas con código de barras
+// @Description Genera un PDF A4 de etiquetas (3 x 8) con nombre, talle, color, SKU y código de barras de las variantes pedidas (solo dueño)
+// @Tags Variantes
+// @Accept json
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param request body models.EtiquetasRequest true "Variantes y copias"
+// @Success 200 {file} file "PDF de etiquetas"
+// @Failure 400 {object} map[string]string "Datos inválidos"
+// @Failure 404 {object} map[string]string "Variante no encontrada"
+// @Router /api/owner/variantes/etiquetas [post]
+func GenerarEtiquetasVariantes(c *gin.Context) {
+	var req models.EtiquetasRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+
+	ids := make([]int, 0, len(req.Items))
+	total := 0
+	for _, item := range req.Items {
+		if item.Copias < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Las copias no pueden ser negativas"})
+			return
+		}
+		ids = append(ids, item.VarianteID)
+		total += max(item.Copias, 1)
+	}
+	if total > 2000 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No se pueden imprimir más de 2000 etiquetas por vez"})
+		return
+	}
+
+	var variantes []models.VarianteProducto
+	if err := config.DB.Preload("Producto").Where("id IN ?", ids).Find(&variantes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener variantes"})
+		return
+	}
+	porID := make(map[int]models.VarianteProducto, len(variantes))
+	for _, v := range variantes {
+		porID[v.ID] = v
+	}
+
+	pdf := utils.NewPDF()
+	posicion := 0
+	for _, item := range req.Items {
+		variante, ok := porID[item.VarianteID]
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Variante %d no encontrada", item.VarianteID)})
+			return
+		}
+		for i := 0; i < max(item.Copias, 1); i++ {
+			if posicion%(etiquetaColumnas*etiquetaFilas) == 0 {
+				pdf.AddPage()
+			}
+			celda := posicion % (etiquetaColumnas * etiquetaFilas)
+			dibujarEtiqueta(pdf, float64(celda%etiquetaColumnas)*etiquetaAncho, etiquetaMargenY+float64(celda/etiquetaColumnas)*etiquetaAlto, variante)
+			posicion++
+		}
+	}
+
+	c.Header("Content-Disposition", `inline; filename="etiquetas.pdf"`)
+	c.Data(http.StatusOK, "application/pdf", pdf.Bytes())
+}
+
+// dibujarEtiqueta dibuja una etiqueta con su esquina superior izquierda en (x, y)
+func dibujarEtiqueta(pdf *utils.PDF, x, y float64, variante models.VarianteProducto) {
+	const margen = 10
+	nombre := ""
+	if variante.Producto != nil {
+		nombre = variante.Producto.Nombre
+	}
+
+	pdf.SetFont(true, 9)
+	pdf.Text(x+margen, y+margen+8, pdf.TruncateText(nombre, etiquetaAncho-2*margen))
+	pdf.SetFont(false, 8)
+	pdf.Text(x+margen, y+margen+19, pdf.TruncateText("Talle "+variante.Talle+" - "+variante.Color, etiquetaAncho-2*margen))
+	pdf.Text(x+margen, y+margen+29, variante.SKU)
+
+	// Los códigos del proveedor que no son EAN-13 se imprimen solo como texto
+	if err := pdf.EAN13(x+margen, y+margen+35, etiquetaAncho-2*margen, 38, variante.CodigoBarras); err != nil {
+		pdf.SetFont(true, 10)
+		pdf.Text(x+margen, y+margen+55, variante.CodigoBarras)
+	}
+}
+
+// asegurarVariante devuelve la variante del registro de stock y la crea si todavía no existe
+func asegurarVariante(tx *gorm.DB, stock models.ProductoStock) (models.VarianteProducto, error) {
+	var variante models.VarianteProducto
+	err := tx.Where("stock_id = ?", stock.ID).First(&variante).Error
+	if err == nil {
+		return variante, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return variante, err
+	}
+
+	variante = models.NuevaVariante(stock)
+	return variante, tx.Create(&variante).Error
+}
+
+// stockParaVenta bloquea el registro de stock del renglón vendido. Si el renglón no trae variante
+// ni color se usa el color con más unidades del talle, como hacía la carga de ventas por talle.
+func stockParaVenta(tx *gorm.DB, d models.VentaDetalleCreateRequest) (models.ProductoStock, error) {
+	var stock models.ProductoStock
+	bloqueo := tx.Clauses(clause.Locking{Strength: "UPDATE"})
+
+	if d.VarianteID != nil {
+		var variante models.VarianteProducto
+		if err := tx.First(&variante, *d.VarianteID).Error; err != nil {
+			return stock, &ventaError{http.StatusBadRequest, fmt.Sprintf("Variante %d no encontrada", *d.VarianteID)}
+		}
+		if d.ProductoID != 0 && d.ProductoID != variante.ProductoID {
+			return stock, &ventaError{http.StatusBadRequest, "La variante no corresponde al producto indicado"}
+		}
+		if err := bloqueo.First(&stock, variante.StockID).Error; err != nil {
+			return stock, &ventaError{http.StatusBadRequest, "Stock no encontrado para la variante"}
+		}
+		return stock, nil
+	}
+
+	if d.ProductoID == 0 || d.Talle == "" {
+		return stock, &ventaError{http.StatusBadRequest, "Cada renglón debe indicar la variante o el producto y talle"}
+	}
+
+	query := bloqueo.Where("producto_id = ? AND talle = ?", d.ProductoID, d.Talle)
+	if d.Color != "" {
+		query = query.Where("color = ?", d.Color)
+	}
+	if err := query.Order("cantidad DESC").First(&stock).Error; err != nil {
+		return stock, &ventaError{http.StatusBadRequest, "Stock no encontrado para el producto, talle y color especificado"}
+	}
+	return stock, nil
+}
+
+// stockDeDetalle busca el registro de stock de un renglón ya vendido, por variante si la tiene
+func stockDeDetalle(tx *gorm.DB, detalle models.VentaDetalle) (models.ProductoStock, error) {
+	var stock models.ProductoStock
+	if detalle.VarianteID != nil {
+		var variante models.VarianteProducto
+		if err := tx.First(&variante, *detalle.VarianteID).Error; err != nil {
+			return stock, err
+		}
+		return stock, tx.First(&stock, variante.StockID).Error
+	}
+
+	query := tx.Where("producto_id = ? AND talle = ?", detalle.ProductoID, detalle.Talle)
+	if detalle.Color != "" {
+		query = query.Where("color = ?", detalle.Color)
+	}
+	return stock, query.First(&stock).Error
+}
@@ -52,7 +52,6 @@ func main() {
 		Rol:                "dueño",
 		Activo:             true,
 		PorcentajeComision: 0.0,
-		GastoPublicitario:  0.0,
 	}
 
 	if err := config.DB.Create(&usuario).Error; err != nil {
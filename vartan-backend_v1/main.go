@@ -36,10 +36,25 @@ func main() {
 
 	config.AutoMigrate(
 		&models.Usuario{},
+		&models.Talle{},
+		&models.Color{},
 		&models.TipoProducto{},
 		&models.Equipo{},
 		&models.Producto{},
 		&models.ProductoStock{},
+		&models.VarianteProducto{},
+		&models.ProductoImagen{},
+		&models.HistorialPrecio{},
+		&models.Promocion{},
+		&models.Combo{},
+		&models.ComboComponente{},
+		&models.SaldoAFavor{},
+		&models.MovimientoSaldoAFavor{},
+		&models.ProgramaPuntos{},
+		&models.MovimientoPuntos{},
+		&models.Campana{},
+		&models.GastoCampana{},
+		&models.ObjetivoVenta{},
 		&models.Cliente{},
 		&models.FormaPago{},
 		&models.Venta{},
@@ -47,12 +62,30 @@ func main() {
 		&models.Pedido{},
 		&models.Comision{},
 		&models.Tarea{},
+		&models.Presupuesto{},
+		&models.PresupuestoDetalle{},
+		&models.MovimientoCuentaCorriente{},
+		&models.Caja{},
+		&models.MovimientoCaja{},
+		&models.MovimientoStock{},
+		&models.ConteoInventario{},
+		&models.ConteoInventarioItem{},
+		&models.ConteoInventarioRegistro{},
 	)
 	MigrarGastos()
+	MigrarCajas()
+	MigrarUsuarios()
 
 	SeedTiposProducto()
 	SeedEquipos()
 	SeedFormasPago()
+	SeedTalles()
+	SeedColores()
+	SeedCuentasCorrientes()
+	SeedCostosVentas()
+	SeedVariantes()
+	SeedDescuentosVentas()
+	SeedProgramaPuntos()
 
 	gin.SetMode(gin.DebugMode)
 
@@ -107,6 +140,22 @@ func MigrarGastos() {
 	log.Println(" Tabla 'gastos' migrada exitosamente")
 }
 
+// MigrarCajas crea el índice que impide tener dos cajas abiertas a la vez
+func MigrarCajas() {
+	if err := config.DB.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_cajas_una_abierta ON cajas (estado) WHERE estado = 'abierta'").Error; err != nil {
+		log.Fatal("Error al crear índice de cajas:", err)
+	}
+}
+
+// MigrarUsuarios quita el gasto publicitario fijo por usuario: la comisión descuenta el de las campañas
+func MigrarUsuarios() {
+	if config.DB.Migrator().HasColumn(&models.Usuario{}, "gasto_publicitario") {
+		if err := config.DB.Migrator().DropColumn(&models.Usuario{}, "gasto_publicitario"); err != nil {
+			log.Fatal("Error al quitar gasto_publicitario de usuarios:", err)
+		}
+	}
+}
+
 func SeedTiposProducto() {
 	tiposIniciales := []string{"Camiseta", "Buzo", "Short", "Pantalón", "Remera"}
 
@@ -150,3 +199,126 @@ func SeedFormasPago() {
 	}
 	log.Println("Formas de pago verificadas/creadas")
 }
+
+// SeedTalles carga los talles que antes estaban fijos en el código, respetando su orden
+func SeedTalles() {
+	for i, talle := range models.TallesIniciales {
+		var count int64
+		config.DB.Model(&models.Talle{}).Where("nombre = ?", string(talle)).Count(&count)
+		if count == 0 {
+			config.DB.Create(&models.Talle{Nombre: string(talle), Orden: i + 1, Activo: true})
+		}
+	}
+	log.Println("Talles verificados/creados")
+}
+
+// SeedColores carga los colores que antes estaban fijos en el código
+func SeedColores() {
+	for i, color := range models.ColoresIniciales {
+		var count int64
+		config.DB.Model(&models.Color{}).Where("nombre = ?", string(color)).Count(&count)
+		if count == 0 {
+			config.DB.Create(&models.Color{Nombre: string(color), Orden: i + 1, Activo: true})
+		}
+	}
+	log.Println("Colores verificados/creados")
+}
+
+// SeedCuentasCorrientes asienta en la cuenta corriente las ventas cargadas antes de que existiera,
+// usando el total final como débito y la seña como crédito. Es idempotente.
+func SeedCuentasCorrientes() {
+	senas := config.DB.Exec(`
+		INSERT INTO cuenta_corriente_movimientos (cliente_id, tipo, concepto, monto, venta_id, forma_pago_id, usuario_id, fecha)
+		SELECT v.cliente_id, 'credito', 'sena', v.sena, v.id, v.forma_pago_id, v.usuario_id, v.fecha_venta
+		FROM venta v
+		WHERE v.sena > 0 AND NOT EXISTS (SELECT 1 FROM cuenta_corriente_movimientos m WHERE m.venta_id = v.id)`)
+	if senas.Error != nil {
+		log.Fatal("Error al asentar señas en cuenta corriente:", senas.Error)
+	}
+
+	ventas := config.DB.Exec(`
+		INSERT INTO cuenta_corriente_movimientos (cliente_id, tipo, concepto, monto, venta_id, usuario_id, fecha)
+		SELECT v.cliente_id, 'debito', 'venta', v.total_final, v.id, v.usuario_id, v.fecha_venta
+		FROM venta v
+		WHERE NOT EXISTS (SELECT 1 FROM cuenta_corriente_movimientos m WHERE m.venta_id = v.id AND m.concepto = 'venta')`)
+	if ventas.Error != nil {
+		log.Fatal("Error al asentar ventas en cuenta corriente:", ventas.Error)
+	}
+
+	log.Printf("Cuentas corrientes verificadas (%d ventas asentadas)", ventas.RowsAffected)
+}
+
+// SeedCostosVentas completa el costo de los renglones vendidos antes de que se guardara,
+// usando el costo actual del producto como mejor aproximación. Es idempotente.
+func SeedCostosVentas() {
+	result := config.DB.Exec(`
+		UPDATE venta_detalles vd SET costo_unitario = p.costo_unitario
+		FROM productos p
+		WHERE p.id = vd.producto_id AND vd.costo_unitario IS NULL`)
+	if result.Error != nil {
+		log.Fatal("Error al completar costos de ventas:", result.Error)
+	}
+
+	log.Printf("Costos de ventas verificados (%d renglones completados)", result.RowsAffected)
+}
+
+// SeedVariantes crea la variante (SKU y código de barras) de los registros de stock que no la tienen
+// y la asigna a los renglones de venta anteriores que se pueden identificar sin ambigüedad. Es idempotente.
+func SeedVariantes() {
+	var stocks []models.ProductoStock
+	config.DB.Where("NOT EXISTS (SELECT 1 FROM variantes_producto v WHERE v.stock_id = producto_stocks.id)").Find(&stocks)
+	for _, stock := range stocks {
+		variante := models.NuevaVariante(stock)
+		if err := config.DB.Create(&variante).Error; err != nil {
+			log.Printf("No se pudo crear la variante del stock %d: %v", stock.ID, err)
+		}
+	}
+
+	// Solo se asignan los renglones cuyo producto tiene un único color en ese talle
+	detalles := config.DB.Exec(`
+		UPDATE venta_detalles vd SET variante_id = v.id, color = v.color
+		FROM variantes_producto v
+		WHERE vd.variante_id IS NULL AND v.producto_id = vd.producto_id AND v.talle = vd.talle
+		AND (SELECT COUNT(*) FROM variantes_producto o WHERE o.producto_id = vd.producto_id AND o.talle = vd.talle) = 1`)
+	if detalles.Error != nil {
+		log.Fatal("Error al asignar variantes a ventas:", detalles.Error)
+	}
+
+	log.Printf("Variantes verificadas (%d creadas, %d renglones de venta asignados)", len(stocks), detalles.RowsAffected)
+}
+
+// SeedDescuentosVentas completa el desglose del descuento de las ventas anteriores: el pactado se
+// toma del presupuesto convertido y el resto, si usó transferencia financiera, es el 3%. Es idempotente.
+func SeedDescuentosVentas() {
+	pactados := config.DB.Exec(`
+		UPDATE venta v SET descuento_pactado = p.descuento
+		FROM presupuestos p
+		WHERE p.venta_id = v.id AND p.descuento > 0 AND v.descuento_pactado = 0 AND v.descuento_financiera = 0`)
+	if pactados.Error != nil {
+		log.Fatal("Error al completar descuentos pactados:", pactados.Error)
+	}
+
+	financiera := config.DB.Exec(`
+		UPDATE venta SET descuento_financiera = descuento - descuento_pactado
+		WHERE usa_financiera AND descuento_financiera = 0 AND descuento_promociones = 0 AND descuento > descuento_pactado`)
+	if financiera.Error != nil {
+		log.Fatal("Error al completar descuentos de financiera:", financiera.Error)
+	}
+
+	log.Printf("Descuentos de ventas verificados (%d pactados, %d de financiera)", pactados.RowsAffected, financiera.RowsAffected)
+}
+
+// SeedProgramaPuntos crea la configuración del programa de puntos, inactiva hasta que el dueño la habilite
+func SeedProgramaPuntos() {
+	var count int64
+	config.DB.Model(&models.ProgramaPuntos{}).Count(&count)
+	if count == 0 {
+		config.DB.Create(&models.ProgramaPuntos{
+			Activo:          false,
+			PesosPorPunto:   1000,
+			ValorPunto:      10,
+			DiasVencimiento: 365,
+		})
+	}
+	log.Println("Programa de puntos verificado/creado")
+}
@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// RankingProducto - Unidades e ingresos de un producto, equipo o tipo de producto
+type RankingProducto struct {
+	ID       int     `json:"id"`
+	Nombre   string  `json:"nombre"`
+	Unidades int64   `json:"unidades"`
+	Ingresos float64 `json:"ingresos"` // Suma de subtotales (antes del descuento de la venta)
+}
+
+// SellThroughProducto - Proporción de la mercadería disponible que se vendió en el período
+type SellThroughProducto struct {
+	ProductoID  int     `json:"producto_id"`
+	Nombre      string  `json:"nombre"`
+	Vendidas    int64   `json:"vendidas"`
+	StockActual int64   `json:"stock_actual"`
+	SellThrough float64 `json:"sell_through"` // vendidas / (vendidas + stock actual) * 100
+}
+
+// StockInmovilizado - Producto con stock que no se vende hace tiempo
+type StockInmovilizado struct {
+	ProductoID    int        `json:"producto_id"`
+	Nombre        string     `json:"nombre"`
+	Stock         int64      `json:"stock"`
+	ValorAlCosto  float64    `json:"valor_al_costo"`
+	UltimaVenta   *time.Time `json:"ultima_venta"`    // null si nunca se vendió
+	DiasSinVentas *int       `json:"dias_sin_ventas"` // null si nunca se vendió
+	FechaCreacion time.Time  `json:"fecha_creacion"`
+}
+
+// CurvaTalle - Unidades vendidas de un talle
+type CurvaTalle struct {
+	Talle      string  `json:"talle"`
+	Unidades   int64   `json:"unidades"`
+	Porcentaje float64 `json:"porcentaje"`
+}
+
+// CurvaTallesTipo - Distribución de la demanda por talle de un tipo de producto
+type CurvaTallesTipo struct {
+	TipoProductoID int          `json:"tipo_producto_id"`
+	Nombre         string       `json:"nombre"`
+	Unidades       int64        `json:"unidades"`
+	Talles         []CurvaTalle `json:"talles"`
+}
+
+// CoberturaVariante - Días que alcanza el stock de un producto, talle y color al ritmo de venta actual
+type CoberturaVariante struct {
+	ProductoID    int      `json:"producto_id"`
+	Nombre        string   `json:"nombre"`
+	Talle         string   `json:"talle"`
+	Color         string   `json:"color"`
+	Stock         int64    `json:"stock"`
+	Vendidas      int64    `json:"vendidas"`       // En los últimos N días
+	VentaDiaria   float64  `json:"venta_diaria"`   // Promedio de unidades por día
+	DiasCobertura *float64 `json:"dias_cobertura"` // null si no hubo ventas en el período
+}
@@ -0,0 +1,118 @@
+package models
+
+import "time"
+
+// Estados de una sesión de caja
+const (
+	CajaAbierta = "abierta"
+	CajaCerrada = "cerrada"
+)
+
+// Tipos de movimiento de caja
+const (
+	MovimientoCajaIngreso = "ingreso"
+	MovimientoCajaEgreso  = "egreso"
+)
+
+// Conceptos de los movimientos de caja
+const (
+	ConceptoCajaVenta  = "venta"  // Seña cobrada en efectivo al vender
+	ConceptoCajaPago   = "pago"   // Pago de cuenta corriente en efectivo
+	ConceptoCajaGasto  = "gasto"  // Gasto pagado en efectivo
+	ConceptoCajaManual = "manual" // Retiro o ingreso cargado a mano
+)
+
+// Nombre de la forma de pago que mueve la caja
+const FormaPagoEfectivo = "Efectivo"
+
+// Caja - Sesión de caja: se abre con un fondo inicial y se cierra con el efectivo contado
+type Caja struct {
+	ID                int        `gorm:"primaryKey;autoIncrement" json:"id"`
+	Estado            string     `gorm:"type:varchar(10);not null;default:'abierta';index" json:"estado"` // abierta, cerrada
+	MontoInicial      float64    `gorm:"type:decimal(10,2);not null" json:"monto_inicial"`
+	UsuarioAperturaID int        `gorm:"not null" json:"usuario_apertura_id"`
+	FechaApertura     time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"fecha_apertura"`
+	UsuarioCierreID   *int       `json:"usuario_cierre_id"`
+	FechaCierre       *time.Time `json:"fecha_cierre"`
+	MontoEsperado     *float64   `gorm:"type:decimal(10,2)" json:"monto_esperado"` // Inicial + ingresos - egresos al cerrar
+	MontoContado      *float64   `gorm:"type:decimal(10,2)" json:"monto_contado"`
+	Diferencia        *float64   `gorm:"type:decimal(10,2)" json:"diferencia"` // Contado - esperado (negativo = faltante)
+	Observaciones     *string    `gorm:"type:text" json:"observaciones"`
+
+	// Relaciones
+	UsuarioApertura Usuario          `gorm:"foreignKey:UsuarioAperturaID" json:"usuario_apertura,omitempty"`
+	UsuarioCierre   *Usuario         `gorm:"foreignKey:UsuarioCierreID" json:"usuario_cierre,omitempty"`
+	Movimientos     []MovimientoCaja `gorm:"foreignKey:CajaID" json:"movimientos,omitempty"`
+}
+
+// TableName especifica el nombre de la tabla
+func (Caja) TableName() string {
+	return "cajas"
+}
+
+// MovimientoCaja - Entrada o salida de efectivo durante una sesión de caja
+type MovimientoCaja struct {
+	ID          int       `gorm:"primaryKey;autoIncrement" json:"id"`
+	CajaID      int       `gorm:"not null;index" json:"caja_id"`
+	Tipo        string    `gorm:"type:varchar(10);not null" json:"tipo"`     // ingreso, egreso
+	Concepto    string    `gorm:"type:varchar(10);not null" json:"concepto"` // venta, pago, gasto, manual
+	Monto       float64   `gorm:"type:decimal(10,2);not null" json:"monto"`
+	VentaID     *int      `gorm:"index" json:"venta_id"`
+	ClienteID   *int      `json:"cliente_id"` // Cliente que pagó (conceptos venta y pago)
+	GastoID     *int      `gorm:"index" json:"gasto_id"`
+	Descripcion *string   `gorm:"type:text" json:"descripcion"`
+	UsuarioID   int       `gorm:"not null" json:"usuario_id"`
+	Fecha       time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"fecha"`
+}
+
+// TableName especifica el nombre de la tabla
+func (MovimientoCaja) TableName() string {
+	return "caja_movimientos"
+}
+
+// CajaAbrirRequest - Apertura de caja
+type CajaAbrirRequest struct {
+	MontoInicial  float64 `json:"monto_inicial" binding:"gte=0"`
+	Observaciones string  `json:"observaciones"`
+}
+
+// CajaCerrarRequest - Cierre de caja con el efectivo contado
+type CajaCerrarRequest struct {
+	MontoContado  *float64 `json:"monto_contado" binding:"required,gte=0"`
+	Observaciones string   `json:"observaciones"`
+}
+
+// MovimientoCajaRequest - Retiro o ingreso manual de efectivo
+type MovimientoCajaRequest struct {
+	Tipo        string  `json:"tipo" binding:"required,oneof=ingreso egreso"`
+	Monto       float64 `json:"monto" binding:"required,gt=0"`
+	Descripcion string  `json:"descripcion" binding:"required"`
+}
+
+// CajaResumen - Sesión de caja con sus totales
+type CajaResumen struct {
+	Caja
+	TotalIngresos float64 `json:"total_ingresos"`
+	TotalEgresos  float64 `json:"total_egresos"`
+	SaldoActual   float64 `json:"saldo_actual"` // Inicial + ingresos - egresos
+}
+
+// FlujoFormaPago - Dinero facturado y cobrado con una forma de pago
+type FlujoFormaPago struct {
+	FormaPagoID int     `json:"forma_pago_id"`
+	Nombre      string  `json:"nombre"`
+	Facturado   float64 `json:"facturado"` // Total final de las ventas hechas con esta forma de pago
+	Senas       float64 `json:"senas"`     // Señas cobradas al vender
+	Pagos       float64 `json:"pagos"`     // Pagos posteriores de cuenta corriente
+	Cobrado     float64 `json:"cobrado"`   // Señas + pagos
+}
+
+// FlujoCajaResponse - Flujo de fondos de un período
+type FlujoCajaResponse struct {
+	FechaDesde     string           `json:"fecha_desde"`
+	FechaHasta     string           `json:"fecha_hasta"`
+	PorFormaPago   []FlujoFormaPago `json:"por_forma_pago"`
+	TotalFacturado float64          `json:"total_facturado"`
+	TotalCobrado   float64          `json:"total_cobrado"`
+	GastosEfectivo float64          `json:"gastos_efectivo"` // Gastos pagados en efectivo en el período
+}
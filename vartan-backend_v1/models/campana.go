@@ -0,0 +1,123 @@
+package models
+
+import "time"
+
+// Canales de venta
+const (
+	CanalInstagram    = "instagram"
+	CanalWhatsApp     = "whatsapp"
+	CanalLocal        = "local"
+	CanalMercadoLibre = "mercadolibre"
+	CanalReferido     = "referido"
+)
+
+// CanalesVenta - Canales válidos, en el orden en que se muestran
+var CanalesVenta = []string{CanalInstagram, CanalWhatsApp, CanalLocal, CanalMercadoLibre, CanalReferido}
+
+// NombresCanal - Nombre para mostrar de cada canal
+var NombresCanal = map[string]string{
+	CanalInstagram:    "Instagram",
+	CanalWhatsApp:     "WhatsApp",
+	CanalLocal:        "Local",
+	CanalMercadoLibre: "MercadoLibre",
+	CanalReferido:     "Referido",
+}
+
+// EsCanalVenta indica si el canal es uno de los conocidos
+func EsCanalVenta(canal string) bool {
+	for _, c := range CanalesVenta {
+		if c == canal {
+			return true
+		}
+	}
+	return false
+}
+
+// Campana - Campaña publicitaria en un canal. El gasto cargado en la campaña se descuenta de la
+// comisión del vendedor a cargo en el mes en que se hizo.
+type Campana struct {
+	ID            int        `gorm:"primaryKey;autoIncrement" json:"id"`
+	Nombre        string     `gorm:"type:varchar(100);not null" json:"nombre"`
+	Canal         string     `gorm:"type:varchar(20);not null;index" json:"canal"`
+	UsuarioID     *int       `gorm:"index" json:"usuario_id"` // Vendedor a cargo; sin vendedor el gasto es del negocio
+	FechaDesde    *time.Time `gorm:"type:date" json:"fecha_desde"`
+	FechaHasta    *time.Time `gorm:"type:date" json:"fecha_hasta"` // Inclusive
+	Activo        bool       `gorm:"default:true" json:"activo"`
+	FechaCreacion time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"fecha_creacion"`
+
+	// Relaciones
+	Usuario *Usuario       `gorm:"foreignKey:UsuarioID" json:"usuario,omitempty"`
+	Gastos  []GastoCampana `gorm:"foreignKey:CampanaID" json:"gastos,omitempty"`
+}
+
+// TableName especifica el nombre de la tabla
+func (Campana) TableName() string {
+	return "campanas"
+}
+
+// GastoCampana - Inversión publicitaria de una campaña en una fecha
+type GastoCampana struct {
+	ID          int       `gorm:"primaryKey;autoIncrement" json:"id"`
+	CampanaID   int       `gorm:"not null;index" json:"campana_id"`
+	Fecha       time.Time `gorm:"type:date;not null;index" json:"fecha"`
+	Monto       float64   `gorm:"type:decimal(10,2);not null" json:"monto"`
+	Descripcion string    `gorm:"type:text" json:"descripcion"`
+	UsuarioID   int       `gorm:"not null" json:"usuario_id"` // Usuario que lo cargó
+}
+
+// TableName especifica el nombre de la tabla
+func (GastoCampana) TableName() string {
+	return "campana_gastos"
+}
+
+// CampanaResponse - Campaña con lo invertido y lo vendido con ella
+type CampanaResponse struct {
+	Campana
+	TotalGastado float64  `json:"total_gastado"`
+	Ventas       int64    `json:"ventas"`
+	Ingresos     float64  `json:"ingresos"`
+	ROI          *float64 `json:"roi"`
+}
+
+// CampanaCreateRequest - Datos para crear una campaña
+type CampanaCreateRequest struct {
+	Nombre     string     `json:"nombre" binding:"required"`
+	Canal      string     `json:"canal" binding:"required"`
+	UsuarioID  *int       `json:"usuario_id"`
+	FechaDesde *time.Time `json:"fecha_desde"`
+	FechaHasta *time.Time `json:"fecha_hasta"`
+}
+
+// CampanaUpdateRequest - Campos modificables de una campaña (el canal no cambia)
+type CampanaUpdateRequest struct {
+	Nombre     string     `json:"nombre"`
+	UsuarioID  *int       `json:"usuario_id"`
+	FechaDesde *time.Time `json:"fecha_desde"`
+	FechaHasta *time.Time `json:"fecha_hasta"`
+	Activo     *bool      `json:"activo"`
+}
+
+// GastoCampanaRequest - Carga de inversión publicitaria
+type GastoCampanaRequest struct {
+	Fecha       string  `json:"fecha" binding:"required"` // YYYY-MM-DD
+	Monto       float64 `json:"monto" binding:"required,gt=0"`
+	Descripcion string  `json:"descripcion"`
+}
+
+// RendimientoCanal - Ventas, ingresos, inversión y ROI de un canal o de un vendedor
+type RendimientoCanal struct {
+	Clave             string   `json:"clave"` // Canal o ID del vendedor
+	Nombre            string   `json:"nombre"`
+	Ventas            int64    `json:"ventas"`
+	Ingresos          float64  `json:"ingresos"` // Suma de totales finales
+	GastoPublicitario float64  `json:"gasto_publicitario"`
+	ROI               *float64 `json:"roi"` // (ingresos - gasto) / gasto * 100; null sin gasto
+}
+
+// ReporteCanalesResponse - Rendimiento por canal y por vendedor en el período
+type ReporteCanalesResponse struct {
+	FechaDesde string             `json:"fecha_desde"`
+	FechaHasta string             `json:"fecha_hasta"`
+	Canales    []RendimientoCanal `json:"canales"`
+	Vendedores []RendimientoCanal `json:"vendedores"`
+}
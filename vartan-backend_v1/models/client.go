@@ -1,17 +1,26 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Cliente struct {
-	ID            int       `gorm:"primaryKey;autoIncrement" json:"id"`
-	Nombre        string    `gorm:"type:varchar(100);not null" json:"nombre"`
-	Telefono      string    `gorm:"type:varchar(20)" json:"telefono"`
-	Email         string    `gorm:"type:varchar(100)" json:"email"`
-	Direccion     string    `gorm:"type:varchar(255)" json:"direccion"`
-	Ciudad        string    `gorm:"type:varchar(100)" json:"ciudad"`
-	Provincia     string    `gorm:"type:varchar(100)" json:"provincia"`
-	Pais          string    `gorm:"type:varchar(100)" json:"pais"`
-	FechaCreacion time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"fecha_creacion"`
+	ID            int            `gorm:"primaryKey;autoIncrement" json:"id"`
+	Nombre        string         `gorm:"type:varchar(100);not null" json:"nombre"`
+	Telefono      string         `gorm:"type:varchar(20)" json:"telefono"`
+	Email         string         `gorm:"type:varchar(100)" json:"email"`
+	Direccion     string         `gorm:"type:varchar(255)" json:"direccion"`
+	Ciudad        string         `gorm:"type:varchar(100)" json:"ciudad"`
+	Provincia     string         `gorm:"type:varchar(100)" json:"provincia"`
+	Pais          string         `gorm:"type:varchar(100)" json:"pais"`
+	LimiteCredito *float64       `gorm:"type:decimal(10,2)" json:"limite_credito"` // Deuda máxima en cuenta corriente (null = sin límite)
+	FechaCreacion time.Time      `gorm:"default:CURRENT_TIMESTAMP" json:"fecha_creacion"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"deleted_at" swaggertype:"string"` // Fecha de archivo; los archivados no aparecen en los listados
 }
 
 // Para crear un cliente nuevo
@@ -24,3 +33,86 @@ type ClienteCreateRequest struct {
 	Provincia string `json:"provincia"`
 	Pais      string `json:"pais"`
 }
+
+// ClienteFusionRequest - Clientes duplicados que se fusionan en el cliente destino
+type ClienteFusionRequest struct {
+	ClienteIDs []int `json:"cliente_ids" binding:"required,min=1"`
+}
+
+// ClienteCreateResponse - Cliente creado junto con los posibles duplicados detectados
+type ClienteCreateResponse struct {
+	Cliente
+	PosiblesDuplicados []Cliente `json:"posibles_duplicados,omitempty"`
+}
+
+// EquipoFavorito - Equipo más comprado por un cliente
+type EquipoFavorito struct {
+	EquipoID int     `json:"equipo_id"`
+	Nombre   string  `json:"nombre"`
+	Unidades int     `json:"unidades"`
+	Total    float64 `json:"total"`
+}
+
+// ClienteHistorialResponse - Historial de compras de un cliente
+type ClienteHistorialResponse struct {
+	Cliente          Cliente          `json:"cliente"`
+	CantidadCompras  int64            `json:"cantidad_compras"`
+	TotalGastado     float64          `json:"total_gastado"`
+	TicketPromedio   float64          `json:"ticket_promedio"`
+	UltimaCompra     *time.Time       `json:"ultima_compra"`
+	EquiposFavoritos []EquipoFavorito `json:"equipos_favoritos"`
+	Ventas           []Venta          `json:"ventas"`
+}
+
+// reglasTelefono quitan, en orden, el código de país (54, con o sin el 9 de celulares), el 0 de
+// larga distancia y el 15 de celulares detrás de un código de área de 2, 3 o 4 dígitos.
+// Se aplican igual en Go y en SQL para que el teléfono buscado y el guardado se comparen igual.
+var reglasTelefono = []struct {
+	patron    string
+	reemplazo string
+}{
+	{`^(00){0,1}54(9){0,1}(\d{10})$`, "$3"},
+	{`^0(\d{10,12})$`, "$1"},
+	{`^(\d{2})15(\d{8})$`, "$1$2"},
+	{`^(\d{3})15(\d{7})$`, "$1$2"},
+	{`^(\d{4})15(\d{6})$`, "$1$2"},
+}
+
+var expresionesTelefono = func() []*regexp.Regexp {
+	expresiones := make([]*regexp.Regexp, len(reglasTelefono))
+	for i, regla := range reglasTelefono {
+		expresiones[i] = regexp.MustCompile(regla.patron)
+	}
+	return expresiones
+}()
+
+// NormalizarTelefono deja solo los dígitos, quita los prefijos de reglasTelefono y conserva los
+// últimos 10 (código de área + número), así "+54 9 11 5555-1234" y "011 15 5555 1234" se
+// comparan igual que "1155551234"
+func NormalizarTelefono(telefono string) string {
+	var b strings.Builder
+	for _, r := range telefono {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	digitos := b.String()
+	for i, expresion := range expresionesTelefono {
+		digitos = expresion.ReplaceAllString(digitos, reglasTelefono[i].reemplazo)
+	}
+	if len(digitos) > 10 {
+		digitos = digitos[len(digitos)-10:]
+	}
+	return digitos
+}
+
+// TelefonoNormalizadoSQL devuelve la expresión de Postgres equivalente a NormalizarTelefono
+// aplicada a la columna indicada
+func TelefonoNormalizadoSQL(columna string) string {
+	expresion := fmt.Sprintf(`regexp_replace(%s, '\D', '', 'g')`, columna)
+	for _, regla := range reglasTelefono {
+		reemplazo := strings.ReplaceAll(regla.reemplazo, "$", `\`)
+		expresion = fmt.Sprintf("regexp_replace(%s, '%s', '%s')", expresion, regla.patron, reemplazo)
+	}
+	return fmt.Sprintf("RIGHT(%s, 10)", expresion)
+}
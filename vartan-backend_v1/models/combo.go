@@ -0,0 +1,124 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// Combo - Paquete de productos que se vende a un precio conjunto (p. ej. camiseta + short + medias)
+type Combo struct {
+	ID            int       `gorm:"primaryKey;autoIncrement" json:"id"`
+	Nombre        string    `gorm:"type:varchar(100);not null" json:"nombre"`
+	Precio        float64   `gorm:"type:decimal(10,2);not null" json:"precio"`
+	Activo        bool      `gorm:"default:true" json:"activo"`
+	FechaCreacion time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"fecha_creacion"`
+
+	// Relaciones
+	Componentes []ComboComponente `gorm:"foreignKey:ComboID" json:"componentes,omitempty"`
+}
+
+// TableName especifica el nombre de la tabla
+func (Combo) TableName() string {
+	return "combos"
+}
+
+// ComboComponente - Producto incluido en el combo. Con variante el talle y color quedan fijos;
+// sin variante se eligen al vender.
+type ComboComponente struct {
+	ID         int  `gorm:"primaryKey;autoIncrement" json:"id"`
+	ComboID    int  `gorm:"not null;index" json:"combo_id"`
+	ProductoID int  `gorm:"not null;index" json:"producto_id"`
+	VarianteID *int `json:"variante_id"`
+	Cantidad   int  `gorm:"not null;default:1" json:"cantidad"`
+
+	// Relaciones
+	Producto *Producto         `gorm:"foreignKey:ProductoID" json:"producto,omitempty"`
+	Variante *VarianteProducto `gorm:"foreignKey:VarianteID" json:"variante,omitempty"`
+}
+
+// TableName especifica el nombre de la tabla
+func (ComboComponente) TableName() string {
+	return "combo_componentes"
+}
+
+// ComboResponse - Combo con las unidades que se pueden armar con el stock actual
+type ComboResponse struct {
+	Combo
+	Disponibles int `json:"disponibles"`
+}
+
+// ComboComponenteRequest - Componente a incluir en el combo
+type ComboComponenteRequest struct {
+	ProductoID int  `json:"producto_id" binding:"required"`
+	VarianteID *int `json:"variante_id"`
+	Cantidad   int  `json:"cantidad" binding:"required,min=1"`
+}
+
+// ComboCreateRequest - Datos para crear un combo
+type ComboCreateRequest struct {
+	Nombre      string                   `json:"nombre" binding:"required"`
+	Precio      float64                  `json:"precio" binding:"required,gt=0"`
+	Componentes []ComboComponenteRequest `json:"componentes" binding:"required,min=1,dive"`
+}
+
+// ComboUpdateRequest - Campos modificables; si se envían componentes reemplazan a los actuales
+type ComboUpdateRequest struct {
+	Nombre      string                   `json:"nombre"`
+	Precio      *float64                 `json:"precio" binding:"omitempty,gt=0"`
+	Activo      *bool                    `json:"activo"`
+	Componentes []ComboComponenteRequest `json:"componentes" binding:"omitempty,min=1,dive"`
+}
+
+// VentaComboCreateRequest - Combo vendido. Los componentes sin variante fija necesitan la
+// selección de talle (y opcionalmente color) o de variante.
+type VentaComboCreateRequest struct {
+	ComboID     int                     `json:"combo_id" binding:"required"`
+	Cantidad    int                     `json:"cantidad" binding:"required,min=1"`
+	Selecciones []ComboSeleccionRequest `json:"selecciones"`
+}
+
+// ComboSeleccionRequest - Variante elegida para un componente del combo
+type ComboSeleccionRequest struct {
+	ComponenteID int    `json:"componente_id" binding:"required"`
+	VarianteID   *int   `json:"variante_id"`
+	Talle        string `json:"talle"`
+	Color        string `json:"color"`
+}
+
+// RepartirPrecioCombo reparte el precio del combo entre los componentes en proporción a su peso
+// (precio de lista por cantidad) y devuelve el precio unitario de cada uno, de modo que la suma de
+// precio por cantidad dé el precio del combo. Los centavos del redondeo quedan en el primer
+// componente de una sola unidad, o en el primero si todos llevan varias.
+func RepartirPrecioCombo(precio float64, pesos []float64, cantidades []int) []float64 {
+	unitarios := make([]float64, len(pesos))
+	if len(pesos) == 0 {
+		return unitarios
+	}
+
+	total := 0.0
+	for _, p := range pesos {
+		total += p
+	}
+
+	asignado := 0.0
+	for i := range pesos {
+		parte := precio / float64(len(pesos))
+		if total > 0 {
+			parte = precio * pesos[i] / total
+		}
+		unitarios[i] = math.Round(parte/float64(cantidades[i])*100) / 100
+		asignado += unitarios[i] * float64(cantidades[i])
+	}
+
+	ajuste := 0
+	for i, cantidad := range cantidades {
+		if cantidad == 1 {
+			ajuste = i
+			break
+		}
+	}
+	diferencia := precio - asignado
+	unitarios[ajuste] = math.Round((unitarios[ajuste]+diferencia/float64(cantidades[ajuste]))*100) / 100
+
+	return unitarios
+}
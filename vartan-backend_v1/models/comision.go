@@ -10,6 +10,9 @@ type Comision struct {
 	Sueldo        float64 `gorm:"type:decimal(10,2);default:0" json:"sueldo"` // Sueldo mensual del empleado
 	Observaciones string  `gorm:"type:text" json:"observaciones"`
 
+	GastoPublicitario float64 `gorm:"type:decimal(10,2);default:0" json:"gasto_publicitario"` // Invertido en las campañas del vendedor en el mes
+	Bono              float64 `gorm:"type:decimal(10,2);default:0" json:"bono"`               // Por cumplir el objetivo del mes; incluido en TotalComision
+
 	// Relación
 	Usuario Usuario `gorm:"foreignKey:UsuarioID" json:"usuario,omitempty"`
 }
@@ -0,0 +1,119 @@
+package models
+
+import "time"
+
+// Estados de un conteo de inventario
+const (
+	ConteoAbierto    = "abierto"
+	ConteoConfirmado = "confirmado"
+	ConteoCancelado  = "cancelado"
+)
+
+// ConteoInventario - Recuento físico de stock. Al abrirlo se fijan las variantes a contar;
+// al confirmarlo se ajusta el stock con las diferencias.
+type ConteoInventario struct {
+	ID              int        `gorm:"primaryKey;autoIncrement" json:"id"`
+	Estado          string     `gorm:"type:varchar(12);not null;default:'abierto';index" json:"estado"` // abierto, confirmado, cancelado
+	Descripcion     string     `gorm:"type:varchar(255)" json:"descripcion"`
+	UsuarioID       int        `gorm:"not null" json:"usuario_id"`
+	FechaApertura   time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"fecha_apertura"`
+	FechaCierre     *time.Time `json:"fecha_cierre"`
+	UsuarioCierreID *int       `json:"usuario_cierre_id"`
+	Motivo          *string    `gorm:"type:text" json:"motivo"` // Motivo de los ajustes al confirmar
+
+	// Relaciones
+	Items []ConteoInventarioItem `gorm:"foreignKey:ConteoID" json:"items,omitempty"`
+}
+
+// TableName especifica el nombre de la tabla
+func (ConteoInventario) TableName() string {
+	return "conteos_inventario"
+}
+
+// ConteoInventarioItem - Variante incluida en un conteo
+type ConteoInventarioItem struct {
+	ID           int    `gorm:"primaryKey;autoIncrement" json:"id"`
+	ConteoID     int    `gorm:"not null;uniqueIndex:idx_conteo_item_stock" json:"conteo_id"`
+	StockID      int    `gorm:"not null;uniqueIndex:idx_conteo_item_stock" json:"stock_id"`
+	ProductoID   int    `gorm:"not null" json:"producto_id"`
+	Talle        string `gorm:"type:varchar(10);not null" json:"talle"`
+	Color        string `gorm:"type:varchar(20);not null" json:"color"`
+	StockSistema int    `gorm:"not null" json:"stock_sistema,omitempty"` // Stock del sistema al abrir el conteo
+	Contado      *int   `json:"contado"`                                 // Suma de lo contado en todos los dispositivos; null si no se contó
+	Ajuste       *int   `json:"ajuste,omitempty"`                        // Ajuste aplicado al confirmar
+
+	// Relaciones
+	Producto  Producto                   `gorm:"foreignKey:ProductoID" json:"producto,omitempty"`
+	Registros []ConteoInventarioRegistro `gorm:"foreignKey:ItemID" json:"registros,omitempty"`
+}
+
+// TableName especifica el nombre de la tabla
+func (ConteoInventarioItem) TableName() string {
+	return "conteos_inventario_items"
+}
+
+// ConteoInventarioRegistro - Cantidad contada de una variante desde un dispositivo.
+// Volver a enviar desde el mismo dispositivo reemplaza la cantidad anterior.
+type ConteoInventarioRegistro struct {
+	ID          int       `gorm:"primaryKey;autoIncrement" json:"id"`
+	ItemID      int       `gorm:"not null;uniqueIndex:idx_conteo_registro_dispositivo" json:"item_id"`
+	Dispositivo string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_conteo_registro_dispositivo" json:"dispositivo"`
+	Cantidad    int       `gorm:"not null" json:"cantidad"`
+	UsuarioID   int       `gorm:"not null" json:"usuario_id"`
+	Fecha       time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"fecha"`
+}
+
+// TableName especifica el nombre de la tabla
+func (ConteoInventarioRegistro) TableName() string {
+	return "conteos_inventario_registros"
+}
+
+// ConteoCreateRequest - Apertura de un conteo. Sin filtros se cuentan todos los productos activos.
+type ConteoCreateRequest struct {
+	Descripcion    string `json:"descripcion"`
+	ProductoIDs    []int  `json:"producto_ids"`
+	TipoProductoID *int   `json:"tipo_producto_id"`
+	EquipoID       *int   `json:"equipo_id"`
+}
+
+// ConteoRegistroRequest - Cantidades contadas desde un dispositivo
+type ConteoRegistroRequest struct {
+	Dispositivo string                      `json:"dispositivo" binding:"required"`
+	Items       []ConteoRegistroItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+// ConteoRegistroItemRequest - Cantidad contada de una variante
+type ConteoRegistroItemRequest struct {
+	StockID  int `json:"stock_id" binding:"required"`
+	Cantidad int `json:"cantidad" binding:"gte=0"`
+}
+
+// ConteoConfirmarRequest - Confirmación de un conteo
+type ConteoConfirmarRequest struct {
+	Motivo            string `json:"motivo" binding:"required"`
+	AjustarNoContados bool   `json:"ajustar_no_contados"` // Si es true, las variantes sin contar se llevan a cero
+}
+
+// DiferenciaConteo - Comparación entre lo contado y el sistema para una variante
+type DiferenciaConteo struct {
+	ItemID       int    `json:"item_id"`
+	StockID      int    `json:"stock_id"`
+	ProductoID   int    `json:"producto_id"`
+	Nombre       string `json:"nombre"`
+	Talle        string `json:"talle"`
+	Color        string `json:"color"`
+	StockSistema int    `json:"stock_sistema"`
+	Contado      *int   `json:"contado"`
+	Diferencia   *int   `json:"diferencia"` // Contado - stock del sistema al contar
+	StockActual  int    `json:"stock_actual"`
+	Ajuste       *int   `json:"ajuste,omitempty"`
+}
+
+// ConteoRevisionResponse - Conteo con las diferencias por variante
+type ConteoRevisionResponse struct {
+	Conteo         ConteoInventario   `json:"conteo"`
+	Items          []DiferenciaConteo `json:"items"`
+	TotalItems     int                `json:"total_items"`
+	Contados       int                `json:"contados"`
+	ConDiferencias int                `json:"con_diferencias"`
+}
@@ -0,0 +1,93 @@
+package models
+
+import "time"
+
+// Tipos de movimiento de la cuenta corriente
+const (
+	MovimientoDebito  = "debito"
+	MovimientoCredito = "credito"
+)
+
+// Conceptos de los movimientos de la cuenta corriente
+const (
+	ConceptoVenta       = "venta"         // Débito por el total final de la venta
+	ConceptoSena        = "sena"          // Crédito por la seña abonada al vender
+	ConceptoPago        = "pago"          // Crédito por un pago posterior
+	ConceptoNotaCredito = "nota_credito"  // Crédito emitido por el dueño
+	ConceptoSaldoAFavor = "saldo_a_favor" // Crédito por lo pagado con gift card o saldo a favor
+)
+
+// MovimientoCuentaCorriente - Renglón del libro de la cuenta corriente de un cliente.
+// Las ventas suman deuda (débito); señas, pagos y notas de crédito la cancelan (crédito).
+// Los créditos imputados a una venta guardan su VentaID; sin venta quedan como saldo a favor.
+type MovimientoCuentaCorriente struct {
+	ID          int       `gorm:"primaryKey;autoIncrement" json:"id"`
+	ClienteID   int       `gorm:"not null;index" json:"cliente_id"`
+	Tipo        string    `gorm:"type:varchar(10);not null" json:"tipo"`     // debito, credito
+	Concepto    string    `gorm:"type:varchar(20);not null" json:"concepto"` // venta, sena, pago, nota_credito
+	Monto       float64   `gorm:"type:decimal(10,2);not null" json:"monto"`
+	VentaID     *int      `gorm:"index" json:"venta_id"`
+	FormaPagoID *int      `json:"forma_pago_id"`
+	Descripcion *string   `gorm:"type:text" json:"descripcion"`
+	UsuarioID   int       `gorm:"not null" json:"usuario_id"` // Usuario que registró el movimiento
+	Fecha       time.Time `gorm:"default:CURRENT_TIMESTAMP;index" json:"fecha"`
+
+	// Relaciones
+	FormaPago *FormaPago `gorm:"foreignKey:FormaPagoID" json:"forma_pago,omitempty"`
+}
+
+// TableName especifica el nombre de la tabla
+func (MovimientoCuentaCorriente) TableName() string {
+	return "cuenta_corriente_movimientos"
+}
+
+// PagoClienteRequest - Pago recibido de un cliente
+type PagoClienteRequest struct {
+	Monto       float64 `json:"monto" binding:"required,gt=0"`
+	FormaPagoID int     `json:"forma_pago_id" binding:"required"`
+	VentaID     *int    `json:"venta_id"` // Opcional: venta a cancelar primero; el resto se imputa a las más antiguas
+	Descripcion string  `json:"descripcion"`
+}
+
+// NotaCreditoRequest - Nota de crédito emitida a favor de un cliente
+type NotaCreditoRequest struct {
+	Monto       float64 `json:"monto" binding:"required,gt=0"`
+	VentaID     *int    `json:"venta_id"`
+	Descripcion string  `json:"descripcion" binding:"required"`
+}
+
+// LimiteCreditoRequest - Límite de crédito del cliente (null = sin límite)
+type LimiteCreditoRequest struct {
+	LimiteCredito *float64 `json:"limite_credito" binding:"omitempty,gte=0"`
+}
+
+// MovimientoEstadoCuenta - Movimiento con el saldo acumulado hasta ese renglón
+type MovimientoEstadoCuenta struct {
+	MovimientoCuentaCorriente
+	Saldo float64 `json:"saldo"`
+}
+
+// EstadoCuentaResponse - Resumen de cuenta corriente entre dos fechas
+type EstadoCuentaResponse struct {
+	Cliente       Cliente                  `json:"cliente"`
+	FechaDesde    string                   `json:"fecha_desde"`
+	FechaHasta    string                   `json:"fecha_hasta"`
+	SaldoInicial  float64                  `json:"saldo_inicial"`
+	TotalDebitos  float64                  `json:"total_debitos"`
+	TotalCreditos float64                  `json:"total_creditos"`
+	SaldoFinal    float64                  `json:"saldo_final"`
+	Movimientos   []MovimientoEstadoCuenta `json:"movimientos"`
+}
+
+// AntiguedadSaldoCliente - Deuda de un cliente agrupada por antigüedad de las ventas
+type AntiguedadSaldoCliente struct {
+	ClienteID     int      `json:"cliente_id"`
+	Nombre        string   `json:"nombre"`
+	Telefono      string   `json:"telefono"`
+	LimiteCredito *float64 `json:"limite_credito"`
+	Hasta30       float64  `json:"hasta_30"`
+	De31a60       float64  `json:"de_31_a_60"`
+	De61a90       float64  `json:"de_61_a_90"`
+	MasDe90       float64  `json:"mas_de_90"`
+	Total         float64  `json:"total"`
+}
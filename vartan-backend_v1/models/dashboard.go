@@ -0,0 +1,41 @@
+package models
+
+// DashboardPeriodo - Totales de ventas de un período
+type DashboardPeriodo struct {
+	FechaDesde     string  `json:"fecha_desde"`
+	FechaHasta     string  `json:"fecha_hasta"`
+	Ingresos       float64 `json:"ingresos"` // Suma de total_final
+	CantidadVentas int64   `json:"cantidad_ventas"`
+	TicketPromedio float64 `json:"ticket_promedio"`
+	Descuentos     float64 `json:"descuentos"`
+	TotalSenas     float64 `json:"total_senas"`     // Señas cobradas al vender
+	SaldoPendiente float64 `json:"saldo_pendiente"` // Saldo que todavía deben esas ventas
+}
+
+// DashboardGrupo - Ventas agrupadas por forma de pago, vendedor, equipo o tipo de producto
+type DashboardGrupo struct {
+	ID             int     `json:"id"`
+	Nombre         string  `json:"nombre"`
+	CantidadVentas int64   `json:"cantidad_ventas"`
+	Unidades       int64   `json:"unidades,omitempty"` // Solo para equipos y tipos de producto
+	Total          float64 `json:"total"`
+	Porcentaje     float64 `json:"porcentaje"` // Participación sobre el total del agrupamiento
+}
+
+// DashboardVariacion - Variación porcentual contra el período anterior (null si el anterior fue cero)
+type DashboardVariacion struct {
+	Ingresos       *float64 `json:"ingresos"`
+	CantidadVentas *float64 `json:"cantidad_ventas"`
+	TicketPromedio *float64 `json:"ticket_promedio"`
+}
+
+// DashboardResponse - Indicadores de ventas para el dueño
+type DashboardResponse struct {
+	Periodo         DashboardPeriodo   `json:"periodo"`
+	PeriodoAnterior DashboardPeriodo   `json:"periodo_anterior"`
+	Variacion       DashboardVariacion `json:"variacion"`
+	PorFormaPago    []DashboardGrupo   `json:"por_forma_pago"`
+	PorVendedor     []DashboardGrupo   `json:"por_vendedor"`
+	PorEquipo       []DashboardGrupo   `json:"por_equipo"`        // Por subtotal de los renglones (antes del descuento)
+	PorTipoProducto []DashboardGrupo   `json:"por_tipo_producto"` // Por subtotal de los renglones (antes del descuento)
+}
@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// Orígenes de un cambio de precio
+const (
+	CambioPrecioManual      = "manual"      // Edición del producto
+	CambioPrecioMasivo      = "masivo"      // Actualización por porcentaje o monto
+	CambioPrecioImportacion = "importacion" // Importación desde planilla
+)
+
+// HistorialPrecio - Registro de cada cambio de costo o precio de venta de un producto
+type HistorialPrecio struct {
+	ID             int       `gorm:"primaryKey;autoIncrement" json:"id"`
+	ProductoID     int       `gorm:"not null;index" json:"producto_id"`
+	CostoAnterior  float64   `gorm:"type:decimal(10,2);not null" json:"costo_anterior"`
+	CostoNuevo     float64   `gorm:"type:decimal(10,2);not null" json:"costo_nuevo"`
+	PrecioAnterior float64   `gorm:"type:decimal(10,2);not null" json:"precio_anterior"`
+	PrecioNuevo    float64   `gorm:"type:decimal(10,2);not null" json:"precio_nuevo"`
+	Origen         string    `gorm:"type:varchar(20);not null" json:"origen"`
+	Motivo         string    `gorm:"type:text" json:"motivo"`
+	UsuarioID      int       `gorm:"not null" json:"usuario_id"`
+	Fecha          time.Time `gorm:"default:CURRENT_TIMESTAMP;index" json:"fecha"`
+
+	// Relaciones
+	Usuario *Usuario `gorm:"foreignKey:UsuarioID" json:"usuario,omitempty"`
+}
+
+// TableName especifica el nombre de la tabla
+func (HistorialPrecio) TableName() string {
+	return "historial_precios"
+}
+
+// ActualizacionPreciosRequest - Aumento (o baja) masivo de costo y/o precio de venta.
+// Sin filtros se requiere todos=true para evitar cambiar todo el catálogo por error.
+type ActualizacionPreciosRequest struct {
+	AplicarA       string  `json:"aplicar_a" binding:"required,oneof=costo precio ambos"`
+	Tipo           string  `json:"tipo" binding:"required,oneof=porcentaje monto"`
+	Valor          float64 `json:"valor" binding:"required"` // 15 = +15% o +$15; negativo para bajar
+	RedondearA     float64 `json:"redondear_a"`              // Opcional: redondea al múltiplo más cercano (p. ej. 100)
+	TipoProductoID *int    `json:"tipo_producto_id"`
+	EquipoID       *int    `json:"equipo_id"`
+	ProductoIDs    []int   `json:"producto_ids"`
+	Todos          bool    `json:"todos"`
+	Motivo         string  `json:"motivo"`
+}
+
+// CambioPrecio - Precio anterior y nuevo de un producto en la actualización
+type CambioPrecio struct {
+	ProductoID     int     `json:"producto_id"`
+	Nombre         string  `json:"nombre"`
+	CostoAnterior  float64 `json:"costo_anterior"`
+	CostoNuevo     float64 `json:"costo_nuevo"`
+	PrecioAnterior float64 `json:"precio_anterior"`
+	PrecioNuevo    float64 `json:"precio_nuevo"`
+}
+
+// ActualizacionPreciosResponse - Vista previa o resultado de la actualización masiva
+type ActualizacionPreciosResponse struct {
+	Aplicado  bool           `json:"aplicado"`
+	Productos int            `json:"productos"`
+	Cambios   []CambioPrecio `json:"cambios"`
+}
@@ -0,0 +1,21 @@
+package models
+
+// ErrorImportacion - Problema encontrado en una fila del archivo importado
+type ErrorImportacion struct {
+	Fila    int    `json:"fila"` // Número de fila en la planilla (la 1 es el encabezado)
+	Columna string `json:"columna,omitempty"`
+	Mensaje string `json:"mensaje"`
+}
+
+// ImportacionProductosResponse - Resultado de una importación de productos y stock.
+// En modo prueba los totales indican lo que se haría, sin guardar nada.
+type ImportacionProductosResponse struct {
+	Aplicado              bool               `json:"aplicado"`
+	Error                 string             `json:"error,omitempty"`
+	Filas                 int                `json:"filas"`
+	ProductosNuevos       int                `json:"productos_nuevos"`
+	ProductosActualizados int                `json:"productos_actualizados"`
+	VariantesNuevas       int                `json:"variantes_nuevas"`
+	Unidades              int                `json:"unidades"`
+	Errores               []ErrorImportacion `json:"errores"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Tipos de movimiento de stock
+const (
+	MovimientoStockAjusteConteo = "ajuste_conteo" // Diferencia confirmada en un conteo de inventario
+	MovimientoStockAjusteManual = "ajuste_manual" // Cantidad corregida a mano
+	MovimientoStockDevolucion   = "devolucion"    // Unidades devueltas por un cliente
+)
+
+// MovimientoStock - Cambio en la cantidad de una variante que no proviene de una venta.
+// Cantidad es positiva si suma stock y negativa si lo resta.
+type MovimientoStock struct {
+	ID         int       `gorm:"primaryKey;autoIncrement" json:"id"`
+	StockID    int       `gorm:"not null;index" json:"stock_id"`
+	VarianteID *int      `gorm:"index" json:"variante_id"`
+	ProductoID int       `gorm:"not null;index" json:"producto_id"`
+	Talle      string    `gorm:"type:varchar(10);not null" json:"talle"`
+	Color      string    `gorm:"type:varchar(20);not null" json:"color"`
+	Tipo       string    `gorm:"type:varchar(20);not null" json:"tipo"`
+	Cantidad   int       `gorm:"not null" json:"cantidad"`
+	Anterior   int       `gorm:"not null" json:"anterior"` // Stock antes del movimiento
+	Motivo     string    `gorm:"type:text;not null" json:"motivo"`
+	ConteoID   *int      `gorm:"index" json:"conteo_id"`
+	UsuarioID  int       `gorm:"not null" json:"usuario_id"`
+	Fecha      time.Time `gorm:"default:CURRENT_TIMESTAMP;index" json:"fecha"`
+}
+
+// TableName especifica el nombre de la tabla
+func (MovimientoStock) TableName() string {
+	return "stock_movimientos"
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// ObjetivoVenta - Meta mensual de un vendedor en pesos, en unidades o en ambas. Si la cumple,
+// el bono se suma a su comisión del mes.
+type ObjetivoVenta struct {
+	ID               int       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UsuarioID        int       `gorm:"not null;uniqueIndex:idx_objetivo_usuario_mes" json:"usuario_id"`
+	Mes              int       `gorm:"not null;uniqueIndex:idx_objetivo_usuario_mes" json:"mes"`
+	Anio             int       `gorm:"not null;uniqueIndex:idx_objetivo_usuario_mes" json:"anio"`
+	MontoObjetivo    *float64  `gorm:"type:decimal(12,2)" json:"monto_objetivo"` // Total final vendido, sin lo devuelto
+	UnidadesObjetivo *int      `json:"unidades_objetivo"`                        // Unidades vendidas, sin las devueltas
+	Bono             float64   `gorm:"type:decimal(10,2);default:0" json:"bono"`
+	FechaCreacion    time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"fecha_creacion"`
+
+	// Relaciones
+	Usuario *Usuario `gorm:"foreignKey:UsuarioID" json:"usuario,omitempty"`
+}
+
+// TableName especifica el nombre de la tabla
+func (ObjetivoVenta) TableName() string {
+	return "objetivos_venta"
+}
+
+// ObjetivoVentaRequest - Alta o reemplazo del objetivo de un vendedor para un mes
+type ObjetivoVentaRequest struct {
+	UsuarioID        int      `json:"usuario_id" binding:"required"`
+	Mes              int      `json:"mes" binding:"required,min=1,max=12"`
+	Anio             int      `json:"anio" binding:"required,min=2000"`
+	MontoObjetivo    *float64 `json:"monto_objetivo" binding:"omitempty,gt=0"`
+	UnidadesObjetivo *int     `json:"unidades_objetivo" binding:"omitempty,gt=0"`
+	Bono             float64  `json:"bono" binding:"gte=0"`
+}
+
+// ProgresoObjetivo - Lo vendido por un vendedor en el mes frente a su objetivo
+type ProgresoObjetivo struct {
+	Posicion           int      `json:"posicion,omitempty"` // Solo en el ranking
+	UsuarioID          int      `json:"usuario_id"`
+	Nombre             string   `json:"nombre"`
+	Mes                int      `json:"mes"`
+	Anio               int      `json:"anio"`
+	ObjetivoID         *int     `json:"objetivo_id"`
+	MontoObjetivo      *float64 `json:"monto_objetivo"`
+	UnidadesObjetivo   *int     `json:"unidades_objetivo"`
+	Bono               float64  `json:"bono"`
+	Ventas             int64    `json:"ventas"`
+	Vendido            float64  `json:"vendido"`
+	Unidades           int64    `json:"unidades"`
+	PorcentajeMonto    *float64 `json:"porcentaje_monto"`    // null sin objetivo en pesos
+	PorcentajeUnidades *float64 `json:"porcentaje_unidades"` // null sin objetivo en unidades
+	Cumplido           bool     `json:"cumplido"`            // Alcanzó todas las metas definidas
+}
+
+// AplicarObjetivo copia las metas del objetivo y calcula el avance y si se cumplió
+func (p *ProgresoObjetivo) AplicarObjetivo(objetivo *ObjetivoVenta) {
+	p.ObjetivoID, p.MontoObjetivo, p.UnidadesObjetivo, p.Bono = nil, nil, nil, 0
+	p.PorcentajeMonto, p.PorcentajeUnidades, p.Cumplido = nil, nil, false
+	if objetivo == nil {
+		return
+	}
+
+	p.ObjetivoID = &objetivo.ID
+	p.MontoObjetivo = objetivo.MontoObjetivo
+	p.UnidadesObjetivo = objetivo.UnidadesObjetivo
+	p.Bono = objetivo.Bono
+
+	cumplido := p.MontoObjetivo != nil || p.UnidadesObjetivo != nil
+	if p.MontoObjetivo != nil && *p.MontoObjetivo > 0 {
+		porcentaje := math.Round(p.Vendido / *p.MontoObjetivo * 10000) / 100
+		p.PorcentajeMonto = &porcentaje
+		cumplido = cumplido && p.Vendido >= *p.MontoObjetivo-0.005
+	}
+	if p.UnidadesObjetivo != nil && *p.UnidadesObjetivo > 0 {
+		porcentaje := math.Round(float64(p.Unidades)/float64(*p.UnidadesObjetivo)*10000) / 100
+		p.PorcentajeUnidades = &porcentaje
+		cumplido = cumplido && p.Unidades >= int64(*p.UnidadesObjetivo)
+	}
+	p.Cumplido = cumplido
+}
+
+// OrdenarRanking ordena a los vendedores por lo vendido (a igual monto, por unidades) y numera
+// las posiciones; los empates comparten posición
+func OrdenarRanking(ranking []ProgresoObjetivo) {
+	sort.SliceStable(ranking, func(i, j int) bool {
+		if ranking[i].Vendido != ranking[j].Vendido {
+			return ranking[i].Vendido > ranking[j].Vendido
+		}
+		if ranking[i].Unidades != ranking[j].Unidades {
+			return ranking[i].Unidades > ranking[j].Unidades
+		}
+		return ranking[i].Nombre < ranking[j].Nombre
+	})
+	for i := range ranking {
+		ranking[i].Posicion = i + 1
+		if i > 0 && ranking[i].Vendido == ranking[i-1].Vendido && ranking[i].Unidades == ranking[i-1].Unidades {
+			ranking[i].Posicion = ranking[i-1].Posicion
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package models
+
+import "time"
+
+// Estados posibles de un presupuesto
+const (
+	PresupuestoPendiente  = "pendiente"
+	PresupuestoAceptado   = "aceptado"
+	PresupuestoRechazado  = "rechazado"
+	PresupuestoConvertido = "convertido"
+)
+
+// Presupuesto - Cotización para clientes mayoristas (clubes, colegios). No mueve stock.
+type Presupuesto struct {
+	ID            int       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UsuarioID     int       `gorm:"not null;index" json:"usuario_id"`
+	ClienteID     int       `gorm:"not null;index" json:"cliente_id"`
+	Estado        string    `gorm:"type:varchar(20);not null;default:'pendiente'" json:"estado"` // pendiente, aceptado, rechazado, convertido
+	FechaValidez  time.Time `gorm:"not null" json:"fecha_validez"`                               // Último día en que se respeta el presupuesto
+	Total         float64   `gorm:"type:decimal(10,2);not null" json:"total"`                    // Suma de los subtotales
+	Descuento     float64   `gorm:"type:decimal(10,2);default:0" json:"descuento"`               // Descuento pactado con el cliente
+	TotalFinal    float64   `gorm:"type:decimal(10,2);not null" json:"total_final"`              // Total - Descuento
+	Observaciones *string   `gorm:"type:text" json:"observaciones"`
+	VentaID       *int      `gorm:"index" json:"venta_id"` // Venta generada al convertir
+	FechaCreacion time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"fecha_creacion"`
+
+	// Relaciones
+	Usuario  Usuario              `gorm:"foreignKey:UsuarioID" json:"usuario,omitempty"`
+	Cliente  Cliente              `gorm:"foreignKey:ClienteID" json:"cliente,omitempty"`
+	Detalles []PresupuestoDetalle `gorm:"foreignKey:PresupuestoID" json:"detalles,omitempty"`
+}
+
+// TableName especifica el nombre de la tabla
+func (Presupuesto) TableName() string {
+	return "presupuestos"
+}
+
+// Vencido indica si el presupuesto ya no puede aceptarse a la fecha dada
+func (p Presupuesto) Vencido(ahora time.Time) bool {
+	finDelDia := time.Date(p.FechaValidez.Year(), p.FechaValidez.Month(), p.FechaValidez.Day(), 23, 59, 59, 0, ahora.Location())
+	return ahora.After(finDelDia)
+}
+
+// PresupuestoDetalle - Renglones del presupuesto (misma estructura que VentaDetalle)
+type PresupuestoDetalle struct {
+	ID             int     `gorm:"primaryKey;autoIncrement" json:"id"`
+	PresupuestoID  int     `gorm:"not null;index" json:"presupuesto_id"`
+	ProductoID     int     `gorm:"not null" json:"producto_id"`
+	Talle          string  `gorm:"type:varchar(10);not null" json:"talle"`
+	Cantidad       int     `gorm:"not null" json:"cantidad"`
+	PrecioUnitario float64 `gorm:"type:decimal(10,2);not null" json:"precio_unitario"`
+	Subtotal       float64 `gorm:"type:decimal(10,2);not null" json:"subtotal"`
+
+	Color      string `gorm:"type:varchar(20)" json:"color"` // Vacío: se vende el color con más stock
+	VarianteID *int   `json:"variante_id"`
+
+	// Relaciones
+	Producto Producto `gorm:"foreignKey:ProductoID" json:"producto,omitempty"`
+}
+
+// TableName especifica el nombre de la tabla
+func (PresupuestoDetalle) TableName() string {
+	return "presupuesto_detalles"
+}
+
+// PresupuestoCreateRequest - Request para crear un presupuesto
+type PresupuestoCreateRequest struct {
+	ClienteID     int                         `json:"cliente_id" binding:"required"`
+	FechaValidez  string                      `json:"fecha_validez" binding:"required"` // "2025-02-02" formato YYYY-MM-DD
+	Descuento     float64                     `json:"descuento" binding:"gte=0"`        // Monto de descuento pactado
+	Observaciones string                      `json:"observaciones"`
+	Detalles      []VentaDetalleCreateRequest `json:"detalles" binding:"required,min=1,dive"`
+}
+
+// PresupuestoEstadoRequest - Request para aceptar o rechazar un presupuesto
+type PresupuestoEstadoRequest struct {
+	Estado string `json:"estado" binding:"required,oneof=pendiente aceptado rechazado"`
+}
+
+// PresupuestoConvertirRequest - Datos de pago necesarios para convertir el presupuesto en venta
+type PresupuestoConvertirRequest struct {
+	UsuarioID     *int    `json:"usuario_id"`                       // Opcional: vendedor de la venta (por defecto el autenticado)
+	FormaPagoID   int     `json:"forma_pago_id" binding:"required"` // Se aplica el descuento de financiera si corresponde
+	Sena          float64 `json:"sena" binding:"gte=0"`             // Seña abonada al confirmar
+	Observaciones string  `json:"observaciones"`                    // Si está vacío se copian las del presupuesto
+}
@@ -6,9 +6,10 @@ import (
 	"time"
 )
 
-// TalleEnum - Enum de talles disponibles
+// TalleEnum - Nombre de un talle. Los talles vigentes se administran en la tabla talles.
 type TalleEnum string
 
+// Talles con los que se inicializa la tabla talles
 const (
 	TalleS   TalleEnum = "S"
 	TalleM   TalleEnum = "M"
@@ -17,18 +18,10 @@ const (
 	TalleXXL TalleEnum = "XXL"
 )
 
-// Talles válidos para validación
-var TallesValidos = map[TalleEnum]bool{
-	TalleS:   true,
-	TalleM:   true,
-	TalleL:   true,
-	TalleXL:  true,
-	TalleXXL: true,
-}
-
-// ColorEnum - Enum de colores disponibles
+// ColorEnum - Nombre de un color. Los colores vigentes se administran en la tabla colores.
 type ColorEnum string
 
+// Colores con los que se inicializa la tabla colores
 const (
 	ColorBlanco   ColorEnum = "Blanco"
 	ColorNegro    ColorEnum = "Negro"
@@ -42,18 +35,13 @@ const (
 	ColorNaranja  ColorEnum = "Naranja"
 )
 
-// Colores válidos para validación
-var ColoresValidos = map[ColorEnum]bool{
-	ColorBlanco:   true,
-	ColorNegro:    true,
-	ColorAzul:     true,
-	ColorRojo:     true,
-	ColorVerde:    true,
-	ColorAmarillo: true,
-	ColorGris:     true,
-	ColorRosa:     true,
-	ColorMorado:   true,
-	ColorNaranja:  true,
+// TallesIniciales - Talles cargados la primera vez, en orden
+var TallesIniciales = []TalleEnum{TalleS, TalleM, TalleL, TalleXL, TalleXXL}
+
+// ColoresIniciales - Colores cargados la primera vez, en orden
+var ColoresIniciales = []ColorEnum{
+	ColorBlanco, ColorNegro, ColorAzul, ColorRojo, ColorVerde,
+	ColorAmarillo, ColorGris, ColorRosa, ColorMorado, ColorNaranja,
 }
 
 // TalleArray - Tipo para array de talles (para GORM)
@@ -98,6 +86,7 @@ type Producto struct {
 	ID                 int           `gorm:"primaryKey;autoIncrement" json:"id"`
 	Nombre             string        `gorm:"type:varchar(100);not null" json:"nombre"`
 	CostoUnitario      float64       `gorm:"type:decimal(10,2);not null" json:"costo_unitario"`
+	PrecioVenta        float64       `gorm:"type:decimal(10,2);not null;default:0" json:"precio_venta"` // Precio de lista sugerido al vender
 	Activo             bool          `gorm:"default:true" json:"activo"`
 	FechaCreacion      time.Time     `gorm:"default:CURRENT_TIMESTAMP" json:"fecha_creacion"`
 	TallesDisponibles  TalleArray    `gorm:"type:json" json:"talles_disponibles"`
@@ -106,22 +95,26 @@ type Producto struct {
 	TipoProducto       *TipoProducto `gorm:"foreignKey:TipoProductoID" json:"tipo_producto,omitempty"`
 	EquipoID           *int          `gorm:"index" json:"equipo_id"`
 	Equipo             *Equipo       `gorm:"foreignKey:EquipoID" json:"equipo,omitempty"`
+
+	Imagenes []ProductoImagen `gorm:"foreignKey:ProductoID" json:"imagenes,omitempty"`
 }
 
 // ProductoResponse - Response con stock total calculado
 type ProductoResponse struct {
-	ID                 int           `json:"id"`
-	Nombre             string        `json:"nombre"`
-	CostoUnitario      float64       `json:"costo_unitario"`
-	Activo             bool          `json:"activo"`
-	FechaCreacion      time.Time     `json:"fecha_creacion"`
-	TallesDisponibles  TalleArray    `json:"talles_disponibles"`
-	ColoresDisponibles ColorArray    `json:"colores_disponibles"`
-	StockTotal         int           `json:"stock_total"`
-	TipoProductoID     *int          `json:"tipo_producto_id"`
-	TipoProducto       *TipoProducto `json:"tipo_producto,omitempty"`
-	EquipoID           *int          `json:"equipo_id"`
-	Equipo             *Equipo       `json:"equipo,omitempty"`
+	ID                 int              `json:"id"`
+	Nombre             string           `json:"nombre"`
+	CostoUnitario      float64          `json:"costo_unitario"`
+	PrecioVenta        float64          `json:"precio_venta"`
+	Activo             bool             `json:"activo"`
+	FechaCreacion      time.Time        `json:"fecha_creacion"`
+	TallesDisponibles  TalleArray       `json:"talles_disponibles"`
+	ColoresDisponibles ColorArray       `json:"colores_disponibles"`
+	StockTotal         int              `json:"stock_total"`
+	TipoProductoID     *int             `json:"tipo_producto_id"`
+	TipoProducto       *TipoProducto    `json:"tipo_producto,omitempty"`
+	EquipoID           *int             `json:"equipo_id"`
+	Equipo             *Equipo          `json:"equipo,omitempty"`
+	Imagenes           []ProductoImagen `json:"imagenes,omitempty"` // La principal primero
 }
 
 // tabla productos_stock (stock por talle y color)
@@ -132,12 +125,19 @@ type ProductoStock struct {
 	Talle      TalleEnum `gorm:"type:varchar(10);not null" json:"talle"`
 	Color      ColorEnum `gorm:"type:varchar(20);not null" json:"color"`
 	Cantidad   int       `gorm:"default:0" json:"cantidad"`
+
+	// Reposición: si son null se usan los del tipo de producto
+	StockMinimo        *int `json:"stock_minimo"`
+	CantidadReposicion *int `json:"cantidad_reposicion"`
+
+	Variante *VarianteProducto `gorm:"foreignKey:StockID" json:"variante,omitempty"`
 }
 
 // creo producto nuevo
 type ProductoCreateRequest struct {
 	Nombre         string      `json:"nombre" binding:"required"`
 	CostoUnitario  float64     `json:"costo_unitario" binding:"required"`
+	PrecioVenta    float64     `json:"precio_venta"`
 	Talles         []TalleEnum `json:"talles"`
 	Colores        []ColorEnum `json:"colores"`
 	TipoProductoID *int        `json:"tipo_producto_id"`
@@ -148,6 +148,7 @@ type ProductoCreateRequest struct {
 type ProductoUpdateRequest struct {
 	Nombre         string      `json:"nombre"`
 	CostoUnitario  float64     `json:"costo_unitario"`
+	PrecioVenta    float64     `json:"precio_venta"`
 	Talles         []TalleEnum `json:"talles"`
 	Colores        []ColorEnum `json:"colores"`
 	Activo         *bool       `json:"activo"`
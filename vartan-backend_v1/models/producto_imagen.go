@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// ProductoImagen - Foto de un producto, opcionalmente de un color en particular.
+// Las URLs son públicas para poder usarlas en <img> y compartirlas con clientes.
+type ProductoImagen struct {
+	ID            int       `gorm:"primaryKey;autoIncrement" json:"id"`
+	ProductoID    int       `gorm:"not null;index" json:"producto_id"`
+	Color         *string   `gorm:"type:varchar(20)" json:"color"` // Null = vale para todos los colores
+	URL           string    `gorm:"type:varchar(255);not null" json:"url"`
+	MiniaturaURL  string    `gorm:"type:varchar(255);not null" json:"miniatura_url"`
+	Ancho         int       `json:"ancho"`
+	Alto          int       `json:"alto"`
+	Orden         int       `gorm:"not null;default:0" json:"orden"`
+	Principal     bool      `gorm:"default:false" json:"principal"`
+	FechaCreacion time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"fecha_creacion"`
+}
+
+// TableName especifica el nombre de la tabla
+func (ProductoImagen) TableName() string {
+	return "producto_imagenes"
+}
+
+// ProductoImagenesOrdenRequest - Nuevo orden de las imágenes de un producto (todas sus imágenes)
+type ProductoImagenesOrdenRequest struct {
+	ImagenIDs []int `json:"imagen_ids" binding:"required"`
+}
@@ -0,0 +1,314 @@
+package models
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Tipos de promoción
+const (
+	PromocionPorcentaje = "porcentaje"  // Valor % de descuento en cada unidad
+	PromocionMonto      = "monto"       // Valor $ de descuento en cada unidad
+	PromocionLlevaPaga  = "lleva_paga"  // Lleva Cantidad y paga CantidadPaga (2x1, 3x2)
+	PromocionPrecioFijo = "precio_fijo" // Cantidad unidades por Valor (3 camisetas por $X)
+)
+
+// Promocion - Descuento automático o cupón. Sin producto, equipo ni tipo alcanza a todo el catálogo;
+// con código solo se aplica cuando el vendedor ingresa el cupón
+type Promocion struct {
+	ID             int        `gorm:"primaryKey;autoIncrement" json:"id"`
+	Nombre         string     `gorm:"type:varchar(100);not null" json:"nombre"`
+	Tipo           string     `gorm:"type:varchar(20);not null" json:"tipo"`
+	Valor          float64    `gorm:"type:decimal(10,2);default:0" json:"valor"`
+	Cantidad       int        `gorm:"default:0" json:"cantidad"`      // Unidades del grupo (lleva_paga y precio_fijo)
+	CantidadPaga   int        `gorm:"default:0" json:"cantidad_paga"` // Unidades que se pagan (lleva_paga)
+	ProductoID     *int       `gorm:"index" json:"producto_id"`
+	EquipoID       *int       `gorm:"index" json:"equipo_id"`
+	TipoProductoID *int       `gorm:"index" json:"tipo_producto_id"`
+	Codigo         *string    `gorm:"type:varchar(30);uniqueIndex" json:"codigo"` // Código del cupón, en mayúsculas
+	FechaDesde     *time.Time `gorm:"type:date" json:"fecha_desde"`
+	FechaHasta     *time.Time `gorm:"type:date" json:"fecha_hasta"` // Inclusive
+	UsosMaximos    *int       `json:"usos_maximos"`                 // Ventas en las que se puede aplicar
+	Usos           int        `gorm:"default:0" json:"usos"`
+	Activo         bool       `gorm:"default:true" json:"activo"`
+	FechaCreacion  time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"fecha_creacion"`
+}
+
+// TableName especifica el nombre de la tabla
+func (Promocion) TableName() string {
+	return "promociones"
+}
+
+// Vigente indica si la promoción se puede aplicar en la fecha dada
+func (p Promocion) Vigente(fecha time.Time) bool {
+	if !p.Activo {
+		return false
+	}
+	if p.UsosMaximos != nil && p.Usos >= *p.UsosMaximos {
+		return false
+	}
+	dia := time.Date(fecha.Year(), fecha.Month(), fecha.Day(), 0, 0, 0, 0, time.UTC)
+	if p.FechaDesde != nil && dia.Before(p.FechaDesde.UTC().Truncate(24*time.Hour)) {
+		return false
+	}
+	if p.FechaHasta != nil && dia.After(p.FechaHasta.UTC().Truncate(24*time.Hour)) {
+		return false
+	}
+	return true
+}
+
+// Alcanza indica si la línea está dentro del alcance de la promoción
+func (p Promocion) Alcanza(l LineaPromocion) bool {
+	if p.ProductoID != nil && *p.ProductoID != l.ProductoID {
+		return false
+	}
+	if p.EquipoID != nil && (l.EquipoID == nil || *p.EquipoID != *l.EquipoID) {
+		return false
+	}
+	if p.TipoProductoID != nil && (l.TipoProductoID == nil || *p.TipoProductoID != *l.TipoProductoID) {
+		return false
+	}
+	return true
+}
+
+// LineaPromocion - Renglón de venta a evaluar
+type LineaPromocion struct {
+	ProductoID     int
+	EquipoID       *int
+	TipoProductoID *int
+	Cantidad       int
+	PrecioUnitario float64
+}
+
+// PromocionLinea - Promoción asignada a un renglón y el descuento que le corresponde
+type PromocionLinea struct {
+	PromocionID int
+	Descuento   float64
+}
+
+// AsignarPromociones elige la promoción de cada renglón. Las promociones no se acumulan: en cada
+// paso se aplica la preferible sobre los renglones que quedan libres. Las de grupo (lleva_paga y
+// precio_fijo) juntan unidades de distintos renglones y ocupan todos los renglones que participan,
+// aunque alguno no reciba descuento. El resultado está alineado con lineas y es nil en los
+// renglones sin promoción.
+func AsignarPromociones(lineas []LineaPromocion, promociones []Promocion) []*PromocionLinea {
+	asignadas := make([]*PromocionLinea, len(lineas))
+	libres := make([]int, 0, len(lineas))
+	for i := range lineas {
+		libres = append(libres, i)
+	}
+	pendientes := append([]Promocion(nil), promociones...)
+
+	for len(libres) > 0 && len(pendientes) > 0 {
+		mejor, mejorTotal := -1, 0.0
+		var mejorDescuentos map[int]float64
+		var mejorOcupadas []int
+		for i, p := range pendientes {
+			descuentos, ocupadas := p.descuentos(lineas, libres)
+			total := 0.0
+			for _, d := range descuentos {
+				total += d
+			}
+			if total < 0.01 {
+				continue
+			}
+			if mejor < 0 || preferible(p, total, pendientes[mejor], mejorTotal) {
+				mejor, mejorTotal, mejorDescuentos, mejorOcupadas = i, total, descuentos, ocupadas
+			}
+		}
+		if mejor < 0 {
+			break
+		}
+
+		ocupada := map[int]bool{}
+		for _, i := range mejorOcupadas {
+			asignadas[i] = &PromocionLinea{PromocionID: pendientes[mejor].ID, Descuento: mejorDescuentos[i]}
+			ocupada[i] = true
+		}
+		restantes := libres[:0]
+		for _, i := range libres {
+			if !ocupada[i] {
+				restantes = append(restantes, i)
+			}
+		}
+		libres = restantes
+		pendientes = append(pendientes[:mejor], pendientes[mejor+1:]...)
+	}
+
+	return asignadas
+}
+
+// preferible indica si la promoción a se aplica antes que b: primero los cupones, que el cliente
+// pidió explícitamente, después la que más descuenta y ante empate la más antigua
+func preferible(a Promocion, totalA float64, b Promocion, totalB float64) bool {
+	if cuponA, cuponB := a.Codigo != nil, b.Codigo != nil; cuponA != cuponB {
+		return cuponA
+	}
+	if math.Abs(totalA-totalB) >= 0.01 {
+		return totalA > totalB
+	}
+	return a.ID < b.ID
+}
+
+// descuentos calcula el descuento de la promoción en cada renglón libre y los renglones que ocupa
+func (p Promocion) descuentos(lineas []LineaPromocion, libres []int) (map[int]float64, []int) {
+	descuentos := map[int]float64{}
+	var ocupadas []int
+
+	switch p.Tipo {
+	case PromocionPorcentaje, PromocionMonto:
+		for _, i := range libres {
+			l := lineas[i]
+			if !p.Alcanza(l) || l.Cantidad <= 0 {
+				continue
+			}
+			porUnidad := math.Min(p.Valor, l.PrecioUnitario)
+			if p.Tipo == PromocionPorcentaje {
+				porUnidad = l.PrecioUnitario * math.Min(p.Valor, 100) / 100
+			}
+			if d := redondearCentavos(porUnidad * float64(l.Cantidad)); d > 0 {
+				descuentos[i] = d
+				ocupadas = append(ocupadas, i)
+			}
+		}
+
+	case PromocionLlevaPaga, PromocionPrecioFijo:
+		if p.Cantidad < 2 {
+			break
+		}
+		// Unidades alcanzadas agrupadas por renglón, de mayor a menor precio; cada grupo toma las
+		// siguientes Cantidad. Se recorren por cantidades para no armar una entrada por unidad.
+		var tramos []tramoPromocion
+		unidades := 0
+		for _, i := range libres {
+			if l := lineas[i]; p.Alcanza(l) && l.Cantidad > 0 {
+				tramos = append(tramos, tramoPromocion{i, l.PrecioUnitario, l.Cantidad})
+				unidades += l.Cantidad
+			}
+		}
+		sort.SliceStable(tramos, func(a, b int) bool { return tramos[a].precio > tramos[b].precio })
+
+		usadas := map[int]bool{}
+		pendientes := unidades / p.Cantidad * p.Cantidad
+		for t := 0; pendientes > 0; {
+			// Grupos enteros dentro del mismo renglón: se calculan todos juntos
+			if k := min(tramos[t].cantidad, pendientes) / p.Cantidad; k > 0 {
+				p.descontarGrupo([]tramoPromocion{{tramos[t].linea, tramos[t].precio, p.Cantidad}}, k, descuentos, usadas)
+				tramos[t].cantidad -= k * p.Cantidad
+				pendientes -= k * p.Cantidad
+				if tramos[t].cantidad == 0 {
+					t++
+				}
+				continue
+			}
+			// Grupo que combina el final de un renglón con los siguientes
+			var grupo []tramoPromocion
+			for faltan := p.Cantidad; faltan > 0; {
+				n := min(tramos[t].cantidad, faltan)
+				grupo = append(grupo, tramoPromocion{tramos[t].linea, tramos[t].precio, n})
+				tramos[t].cantidad -= n
+				faltan -= n
+				if tramos[t].cantidad == 0 {
+					t++
+				}
+			}
+			p.descontarGrupo(grupo, 1, descuentos, usadas)
+			pendientes -= p.Cantidad
+		}
+		for _, i := range libres {
+			if usadas[i] {
+				descuentos[i] = redondearCentavos(descuentos[i])
+				ocupadas = append(ocupadas, i)
+			}
+		}
+	}
+
+	return descuentos, ocupadas
+}
+
+// tramoPromocion - Unidades de un renglón al mismo precio dentro de una promoción por cantidad
+type tramoPromocion struct {
+	linea    int
+	precio   float64
+	cantidad int
+}
+
+// descontarGrupo suma el descuento de veces grupos iguales, ordenados de mayor a menor precio
+func (p Promocion) descontarGrupo(grupo []tramoPromocion, veces int, descuentos map[int]float64, usadas map[int]bool) {
+	for _, t := range grupo {
+		usadas[t.linea] = true
+	}
+	if p.Tipo == PromocionLlevaPaga {
+		// Las unidades más baratas del grupo son las que no se pagan
+		pagas := p.CantidadPaga
+		for _, t := range grupo {
+			cobradas := min(t.cantidad, pagas)
+			pagas -= cobradas
+			descuentos[t.linea] += t.precio * float64((t.cantidad-cobradas)*veces)
+		}
+		return
+	}
+	suma := 0.0
+	for _, t := range grupo {
+		suma += t.precio * float64(t.cantidad)
+	}
+	if suma <= p.Valor {
+		return
+	}
+	// La diferencia con el precio del grupo se reparte en proporción al precio de cada unidad
+	for _, t := range grupo {
+		descuentos[t.linea] += (suma - p.Valor) * t.precio * float64(t.cantidad*veces) / suma
+	}
+}
+
+func redondearCentavos(valor float64) float64 {
+	return math.Round(valor*100) / 100
+}
+
+// PromocionCreateRequest - Datos para crear una promoción
+type PromocionCreateRequest struct {
+	Nombre         string     `json:"nombre" binding:"required"`
+	Tipo           string     `json:"tipo" binding:"required,oneof=porcentaje monto lleva_paga precio_fijo"`
+	Valor          float64    `json:"valor"`
+	Cantidad       int        `json:"cantidad"`
+	CantidadPaga   int        `json:"cantidad_paga"`
+	ProductoID     *int       `json:"producto_id"`
+	EquipoID       *int       `json:"equipo_id"`
+	TipoProductoID *int       `json:"tipo_producto_id"`
+	Codigo         string     `json:"codigo"`
+	FechaDesde     *time.Time `json:"fecha_desde"`
+	FechaHasta     *time.Time `json:"fecha_hasta"`
+	UsosMaximos    *int       `json:"usos_maximos"`
+}
+
+// PromocionUpdateRequest - Campos modificables de una promoción (el tipo y el alcance no cambian)
+type PromocionUpdateRequest struct {
+	Nombre      string     `json:"nombre"`
+	Valor       *float64   `json:"valor"`
+	FechaDesde  *time.Time `json:"fecha_desde"`
+	FechaHasta  *time.Time `json:"fecha_hasta"`
+	UsosMaximos *int       `json:"usos_maximos"`
+	Activo      *bool      `json:"activo"`
+}
+
+// SimularPromocionesRequest - Renglones y cupón a evaluar antes de confirmar la venta
+type SimularPromocionesRequest struct {
+	Cupon    string                      `json:"cupon"`
+	Detalles []VentaDetalleCreateRequest `json:"detalles" binding:"required"`
+}
+
+// DetalleSimulacion - Descuento que recibiría cada renglón
+type DetalleSimulacion struct {
+	PromocionID *int    `json:"promocion_id"`
+	Promocion   string  `json:"promocion,omitempty"`
+	Subtotal    float64 `json:"subtotal"`
+	Descuento   float64 `json:"descuento"`
+}
+
+// SimulacionPromocionesResponse - Resultado de la simulación (sin el descuento por forma de pago)
+type SimulacionPromocionesResponse struct {
+	Total                float64             `json:"total"`
+	DescuentoPromociones float64             `json:"descuento_promociones"`
+	Detalles             []DetalleSimulacion `json:"detalles"`
+}
@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// Tipos de movimiento de puntos
+const (
+	MovimientoPuntosAcreditacion = "acreditacion" // Ganados por una venta
+	MovimientoPuntosCanje        = "canje"        // Usados como descuento en una venta
+	MovimientoPuntosVencimiento  = "vencimiento"  // Vencidos sin usar
+	MovimientoPuntosAnulacion    = "anulacion"    // Quitados al eliminar la venta que los generó
+	MovimientoPuntosReintegro    = "reintegro"    // Devueltos al eliminar la venta en la que se canjearon
+)
+
+// ProgramaPuntos - Configuración del programa de fidelidad (un único registro)
+type ProgramaPuntos struct {
+	ID              int     `gorm:"primaryKey;autoIncrement" json:"id"`
+	Activo          bool    `gorm:"default:false" json:"activo"`
+	PesosPorPunto   float64 `gorm:"type:decimal(10,2);not null" json:"pesos_por_punto"` // Se gana 1 punto cada tantos pesos del total final
+	ValorPunto      float64 `gorm:"type:decimal(10,2);not null" json:"valor_punto"`     // Pesos de descuento por punto canjeado
+	DiasVencimiento int     `gorm:"default:0" json:"dias_vencimiento"`                  // 0 = los puntos no vencen
+}
+
+// TableName especifica el nombre de la tabla
+func (ProgramaPuntos) TableName() string {
+	return "programa_puntos"
+}
+
+// MovimientoPuntos - Renglón del libro de puntos de un cliente. Puntos es positivo si suma y
+// negativo si resta. En las acreditaciones y reintegros, Restantes son los puntos que todavía no
+// se canjearon ni vencieron: los canjes consumen primero los que vencen antes.
+type MovimientoPuntos struct {
+	ID               int        `gorm:"primaryKey;autoIncrement" json:"id"`
+	ClienteID        int        `gorm:"not null;index" json:"cliente_id"`
+	Tipo             string     `gorm:"type:varchar(20);not null" json:"tipo"`
+	Puntos           int        `gorm:"not null" json:"puntos"`
+	Restantes        int        `gorm:"default:0" json:"restantes"`
+	VentaID          *int       `gorm:"index" json:"venta_id"`
+	FechaVencimiento *time.Time `json:"fecha_vencimiento"`
+	UsuarioID        int        `gorm:"not null" json:"usuario_id"`
+	Fecha            time.Time  `gorm:"default:CURRENT_TIMESTAMP;index" json:"fecha"`
+}
+
+// TableName especifica el nombre de la tabla
+func (MovimientoPuntos) TableName() string {
+	return "puntos_movimientos"
+}
+
+// ProgramaPuntosRequest - Cambios en la configuración del programa
+type ProgramaPuntosRequest struct {
+	Activo          *bool    `json:"activo"`
+	PesosPorPunto   *float64 `json:"pesos_por_punto" binding:"omitempty,gt=0"`
+	ValorPunto      *float64 `json:"valor_punto" binding:"omitempty,gt=0"`
+	DiasVencimiento *int     `json:"dias_vencimiento" binding:"omitempty,gte=0"`
+}
+
+// PuntosClienteResponse - Saldo de puntos del cliente y su libro
+type PuntosClienteResponse struct {
+	ClienteID          int                `json:"cliente_id"`
+	Saldo              int                `json:"saldo"`
+	ValorEnPesos       float64            `json:"valor_en_pesos"`
+	ProximoVencimiento *time.Time         `json:"proximo_vencimiento"`
+	PuntosPorVencer    int                `json:"puntos_por_vencer"` // Los que vencen en la próxima fecha
+	Movimientos        []MovimientoPuntos `json:"movimientos"`
+}
@@ -0,0 +1,45 @@
+package models
+
+// ReposicionRequest - Stock mínimo y cantidad a reponer. Null borra el valor y se hereda el del tipo de producto.
+type ReposicionRequest struct {
+	StockMinimo        *int `json:"stock_minimo" binding:"omitempty,gte=0"`
+	CantidadReposicion *int `json:"cantidad_reposicion" binding:"omitempty,gt=0"`
+}
+
+// AlertaStock - Variante con stock por debajo de su mínimo
+type AlertaStock struct {
+	StockID          int     `json:"stock_id"`
+	ProductoID       int     `json:"producto_id"`
+	Nombre           string  `json:"nombre"`
+	Talle            string  `json:"talle"`
+	Color            string  `json:"color"`
+	Cantidad         int     `json:"cantidad"`
+	StockMinimo      int     `json:"stock_minimo"`
+	Faltante         int     `json:"faltante"`     // Unidades para llegar al mínimo
+	VentaDiaria      float64 `json:"venta_diaria"` // Promedio reciente de unidades por día
+	CantidadSugerida int     `json:"cantidad_sugerida"`
+}
+
+// ItemSugerenciaCompra - Renglón del pedido sugerido al proveedor
+type ItemSugerenciaCompra struct {
+	StockID          int     `json:"stock_id"`
+	ProductoID       int     `json:"producto_id"`
+	Nombre           string  `json:"nombre"`
+	Talle            string  `json:"talle"`
+	Color            string  `json:"color"`
+	Cantidad         int     `json:"cantidad"` // Stock actual
+	VentaDiaria      float64 `json:"venta_diaria"`
+	CantidadSugerida int     `json:"cantidad_sugerida"`
+	CostoUnitario    float64 `json:"costo_unitario"`
+	Subtotal         float64 `json:"subtotal"`
+}
+
+// AlertasStockResponse - Alertas de stock bajo y pedido sugerido
+type AlertasStockResponse struct {
+	Dias             int                    `json:"dias"`      // Días de ventas usados para calcular el ritmo
+	Cobertura        int                    `json:"cobertura"` // Días que debe cubrir la compra
+	Alertas          []AlertaStock          `json:"alertas"`
+	SugerenciaCompra []ItemSugerenciaCompra `json:"sugerencia_compra"`
+	TotalUnidades    int                    `json:"total_unidades"`
+	TotalCosto       float64                `json:"total_costo"`
+}
@@ -0,0 +1,30 @@
+package models
+
+// EstadoResultadosMes - Resultado económico de un mes
+type EstadoResultadosMes struct {
+	Anio                  int            `json:"anio"`
+	Mes                   int            `json:"mes"`
+	VentasBrutas          float64        `json:"ventas_brutas"` // Suma de los precios de lista vendidos
+	Descuentos            float64        `json:"descuentos"`
+	Devoluciones          float64        `json:"devoluciones"`     // Reintegrado en saldo a favor por las unidades devueltas
+	Ingresos              float64        `json:"ingresos"`         // Ventas netas de descuentos y devoluciones
+	CostoMercaderia       float64        `json:"costo_mercaderia"` // Costo de lo vendido sin lo devuelto, al costo del momento de la venta
+	MargenBruto           float64        `json:"margen_bruto"`
+	MargenBrutoPorcentaje float64        `json:"margen_bruto_porcentaje"`
+	GastosPorCategoria    []GastoResumen `json:"gastos_por_categoria"`
+	GastosOperativos      float64        `json:"gastos_operativos"`  // Gastos sin las compras de mercadería
+	ComprasMercaderia     float64        `json:"compras_mercaderia"` // Informativo: ya se refleja en el costo de lo vendido
+	Comisiones            float64        `json:"comisiones"`
+	Sueldos               float64        `json:"sueldos"`
+	ResultadoNeto         float64        `json:"resultado_neto"`
+	VariacionIngresos     *float64       `json:"variacion_ingresos"`  // % contra el mes anterior
+	VariacionResultado    *float64       `json:"variacion_resultado"` // % contra el mes anterior
+}
+
+// EstadoResultadosResponse - Estado de resultados mensual de los últimos 12 meses
+type EstadoResultadosResponse struct {
+	Desde   string                `json:"desde"` // YYYY-MM
+	Hasta   string                `json:"hasta"` // YYYY-MM
+	Meses   []EstadoResultadosMes `json:"meses"`
+	Totales EstadoResultadosMes   `json:"totales"` // Suma de los 12 meses (anio y mes en cero)
+}
@@ -0,0 +1,82 @@
+package models
+
+import "time"
+
+// Orígenes de un saldo a favor
+const (
+	SaldoAFavorGiftCard   = "gift_card"  // Emitido a pedido (regalo)
+	SaldoAFavorDevolucion = "devolucion" // Crédito por mercadería devuelta
+)
+
+// Tipos de movimiento de un saldo a favor
+const (
+	MovimientoSaldoEmision   = "emision"   // Monto inicial
+	MovimientoSaldoConsumo   = "consumo"   // Usado como pago de una venta
+	MovimientoSaldoReintegro = "reintegro" // Devuelto al anular la venta en la que se usó
+	MovimientoSaldoAnulacion = "anulacion" // Saldo de una devolución anulado al eliminar la venta
+)
+
+// SaldoAFavor - Gift card o crédito de tienda identificado por un código. Saldo es lo que queda
+// por usar; los movimientos registran la emisión y cada consumo.
+type SaldoAFavor struct {
+	ID            int       `gorm:"primaryKey;autoIncrement" json:"id"`
+	Codigo        string    `gorm:"type:varchar(20);not null;uniqueIndex" json:"codigo"`
+	Origen        string    `gorm:"type:varchar(20);not null" json:"origen"` // gift_card, devolucion
+	MontoInicial  float64   `gorm:"type:decimal(10,2);not null" json:"monto_inicial"`
+	Saldo         float64   `gorm:"type:decimal(10,2);not null" json:"saldo"`
+	ClienteID     *int      `gorm:"index" json:"cliente_id"`      // Opcional para gift cards; las devoluciones quedan a nombre del cliente
+	VentaID       *int      `gorm:"index" json:"venta_id"`        // Venta de la devolución que lo originó
+	Descripcion   *string   `gorm:"type:text" json:"descripcion"` // Destinatario, motivo, etc.
+	UsuarioID     int       `gorm:"not null" json:"usuario_id"`   // Usuario que lo emitió
+	FechaCreacion time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"fecha_creacion"`
+
+	// Relaciones
+	Cliente     *Cliente                `gorm:"foreignKey:ClienteID" json:"cliente,omitempty"`
+	Movimientos []MovimientoSaldoAFavor `gorm:"foreignKey:SaldoAFavorID" json:"movimientos,omitempty"`
+}
+
+// TableName especifica el nombre de la tabla
+func (SaldoAFavor) TableName() string {
+	return "saldos_a_favor"
+}
+
+// MovimientoSaldoAFavor - Emisión, consumo o reintegro de un saldo a favor. Monto siempre positivo.
+type MovimientoSaldoAFavor struct {
+	ID            int       `gorm:"primaryKey;autoIncrement" json:"id"`
+	SaldoAFavorID int       `gorm:"not null;index" json:"saldo_a_favor_id"`
+	Tipo          string    `gorm:"type:varchar(20);not null" json:"tipo"`
+	Monto         float64   `gorm:"type:decimal(10,2);not null" json:"monto"`
+	VentaID       *int      `gorm:"index" json:"venta_id"`
+	UsuarioID     int       `gorm:"not null" json:"usuario_id"`
+	Fecha         time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"fecha"`
+}
+
+// TableName especifica el nombre de la tabla
+func (MovimientoSaldoAFavor) TableName() string {
+	return "saldo_a_favor_movimientos"
+}
+
+// GiftCardCreateRequest - Emisión de una gift card
+type GiftCardCreateRequest struct {
+	Monto       float64 `json:"monto" binding:"required,gt=0"`
+	ClienteID   *int    `json:"cliente_id"`
+	Descripcion string  `json:"descripcion"`
+}
+
+// DevolucionItemRequest - Unidades devueltas de un renglón de la venta
+type DevolucionItemRequest struct {
+	DetalleID int `json:"detalle_id" binding:"required"`
+	Cantidad  int `json:"cantidad" binding:"required,min=1"`
+}
+
+// DevolucionRequest - Devolución de mercadería a cambio de saldo a favor
+type DevolucionRequest struct {
+	Items  []DevolucionItemRequest `json:"items" binding:"required,min=1,dive"`
+	Motivo string                  `json:"motivo"`
+}
+
+// DevolucionResponse - Saldo a favor emitido por la devolución
+type DevolucionResponse struct {
+	SaldoAFavor SaldoAFavor `json:"saldo_a_favor"`
+	Unidades    int         `json:"unidades"`
+}
@@ -17,13 +17,31 @@ type Venta struct {
 	Total          float64   `gorm:"type:decimal(10,2);not null" json:"total"`       // Total de la venta (precio de productos)
 	Sena           float64   `gorm:"type:decimal(10,2);not null" json:"sena"`        // Seña abonada
 	Saldo          float64   `gorm:"type:decimal(10,2);not null" json:"saldo"`       // Lo que resta pagar
-	Descuento      float64   `gorm:"type:decimal(10,2);default:0" json:"descuento"`  // Descuento aplicado (3% de financiera)
+	Descuento      float64   `gorm:"type:decimal(10,2);default:0" json:"descuento"`  // Descuento total (suma del desglose)
 	TotalFinal     float64   `gorm:"type:decimal(10,2);not null" json:"total_final"` // Total - Descuento
 	UsaFinanciera  bool      `gorm:"default:false" json:"usa_financiera"`            // Si usa transferencia financiera
 	ComprobanteURL *string   `gorm:"type:varchar(500)" json:"comprobante_url"`       // URL del comprobante subido
 	Observaciones  *string   `gorm:"type:text" json:"observaciones"`                 // Observaciones de la venta
 	FechaVenta     time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"fecha_venta"`
 
+	// Desglose del descuento
+	DescuentoPactado     float64 `gorm:"type:decimal(10,2);default:0" json:"descuento_pactado"`     // Acordado en el presupuesto
+	DescuentoPromociones float64 `gorm:"type:decimal(10,2);default:0" json:"descuento_promociones"` // Promociones y cupones de los renglones
+	DescuentoFinanciera  float64 `gorm:"type:decimal(10,2);default:0" json:"descuento_financiera"`  // 3% de transferencia financiera sobre el saldo
+	DescuentoPuntos      float64 `gorm:"type:decimal(10,2);default:0" json:"descuento_puntos"`      // Puntos de fidelidad canjeados
+	Cupon                *string `gorm:"type:varchar(30)" json:"cupon"`                             // Código de cupón ingresado
+
+	// Parte del pago hecha con gift card o saldo a favor (no es descuento: baja el saldo)
+	PagoSaldoAFavor float64 `gorm:"type:decimal(10,2);default:0" json:"pago_saldo_a_favor"`
+
+	// Programa de puntos
+	PuntosCanjeados int `gorm:"default:0" json:"puntos_canjeados"`
+	PuntosGanados   int `gorm:"default:0" json:"puntos_ganados"`
+
+	// Origen de la venta: canal y, si vino de una campaña publicitaria, la campaña
+	CanalVenta *string `gorm:"type:varchar(20);index" json:"canal_venta"`
+	CampanaID  *int    `gorm:"index" json:"campana_id"`
+
 	// Relaciones
 	Usuario   Usuario        `gorm:"foreignKey:UsuarioID" json:"usuario,omitempty"`
 	Cliente   Cliente        `gorm:"foreignKey:ClienteID" json:"cliente,omitempty"`
@@ -33,13 +51,25 @@ type Venta struct {
 
 // Tabla ventas_detalle (productos vendidos)
 type VentaDetalle struct {
-	ID             int     `gorm:"primaryKey;autoIncrement" json:"id"`
-	VentaID        int     `gorm:"not null" json:"venta_id"`
-	ProductoID     int     `gorm:"not null" json:"producto_id"`
-	Talle          string  `gorm:"type:varchar(10);not null" json:"talle"`
-	Cantidad       int     `gorm:"not null" json:"cantidad"`
-	PrecioUnitario float64 `gorm:"type:decimal(10,2);not null" json:"precio_unitario"`
-	Subtotal       float64 `gorm:"type:decimal(10,2);not null" json:"subtotal"`
+	ID             int      `gorm:"primaryKey;autoIncrement" json:"id"`
+	VentaID        int      `gorm:"not null" json:"venta_id"`
+	ProductoID     int      `gorm:"not null" json:"producto_id"`
+	Talle          string   `gorm:"type:varchar(10);not null" json:"talle"`
+	Color          string   `gorm:"type:varchar(20)" json:"color"`
+	VarianteID     *int     `gorm:"index" json:"variante_id"` // Null en ventas anteriores a las variantes
+	Cantidad       int      `gorm:"not null" json:"cantidad"`
+	PrecioUnitario float64  `gorm:"type:decimal(10,2);not null" json:"precio_unitario"`
+	Subtotal       float64  `gorm:"type:decimal(10,2);not null" json:"subtotal"`
+	CostoUnitario  *float64 `gorm:"type:decimal(10,2)" json:"costo_unitario"` // Costo del producto al momento de la venta
+
+	// Promoción aplicada al renglón; el subtotal no incluye su descuento
+	PromocionID *int    `gorm:"index" json:"promocion_id"`
+	Descuento   float64 `gorm:"type:decimal(10,2);default:0" json:"descuento"`
+
+	// Combo del que forma parte el renglón; el precio unitario es la parte del precio del combo
+	ComboID *int `gorm:"index" json:"combo_id"`
+
+	CantidadDevuelta int `gorm:"default:0" json:"cantidad_devuelta"` // Unidades devueltas a cambio de saldo a favor
 
 	// Relaciones
 	Producto Producto `gorm:"foreignKey:ProductoID" json:"producto,omitempty"`
@@ -52,7 +82,21 @@ type VentaCreateRequest struct {
 	FormaPagoID   int                         `json:"forma_pago_id" form:"forma_pago_id" binding:"required"` // 1=Transferencia Financiera, 2=Transf a Cero, 3=Transf Bancaria, 4=Efectivo
 	Sena          float64                     `json:"sena" form:"sena" binding:"required"`
 	Observaciones string                      `json:"observaciones" form:"observaciones"`
-	Detalles      []VentaDetalleCreateRequest `json:"detalles" binding:"required"`
+	Cupon         string                      `json:"cupon" form:"cupon"` // Opcional: código de cupón de descuento
+	Detalles      []VentaDetalleCreateRequest `json:"detalles"`
+	Combos        []VentaComboCreateRequest   `json:"combos" binding:"dive"`
+
+	// Opcional: gift card o saldo a favor con el que se paga parte o todo el saldo.
+	// Sin monto se usa lo que alcance a cubrir.
+	SaldoAFavorCodigo string  `json:"saldo_a_favor_codigo" form:"saldo_a_favor_codigo"`
+	SaldoAFavorMonto  float64 `json:"saldo_a_favor_monto" form:"saldo_a_favor_monto"`
+
+	PuntosCanje int `json:"puntos_canje" form:"puntos_canje" binding:"gte=0"` // Opcional: puntos del cliente a usar como descuento
+
+	// Opcional: canal (instagram, whatsapp, local, mercadolibre, referido) y campaña de origen.
+	// Con campaña y sin canal se toma el canal de la campaña.
+	CanalVenta string `json:"canal_venta" form:"canal_venta"`
+	CampanaID  *int   `json:"campana_id" form:"campana_id"`
 }
 
 type VentaCreateFormRequest struct {
@@ -61,12 +105,24 @@ type VentaCreateFormRequest struct {
 	FormaPagoID   string `form:"forma_pago_id" binding:"required"`
 	Sena          string `form:"sena" binding:"required"`
 	Observaciones string `form:"observaciones"`
-	Detalles      string `form:"detalles" binding:"required"`
+	Cupon         string `form:"cupon"`
+	Detalles      string `form:"detalles"`
+	Combos        string `form:"combos"` // JSON con los combos vendidos
+
+	SaldoAFavorCodigo string `form:"saldo_a_favor_codigo"`
+	SaldoAFavorMonto  string `form:"saldo_a_favor_monto"`
+	PuntosCanje       string `form:"puntos_canje"`
+	CanalVenta        string `form:"canal_venta"`
+	CampanaID         string `form:"campana_id"`
 }
 
+// Se indica la variante (por ejemplo al escanear el código) o el producto y talle; el color es
+// opcional y si falta se toma el color con más stock del talle
 type VentaDetalleCreateRequest struct {
-	ProductoID     int     `json:"producto_id" binding:"required"`
-	Talle          string  `json:"talle" binding:"required"`
+	VarianteID     *int    `json:"variante_id"`
+	ProductoID     int     `json:"producto_id"`
+	Talle          string  `json:"talle"`
+	Color          string  `json:"color"`
 	Cantidad       int     `json:"cantidad" binding:"required"`
 	PrecioUnitario float64 `json:"precio_unitario" binding:"required"`
 }
@@ -77,4 +133,8 @@ type VentaUpdateRequest struct {
 	FormaPagoID   *int     `json:"forma_pago_id"`
 	Sena          *float64 `json:"sena"`
 	Observaciones *string  `json:"observaciones"`
+
+	// Origen de la venta; la campaña en cero la quita
+	CanalVenta *string `json:"canal_venta"`
+	CampanaID  *int    `json:"campana_id"`
 }
@@ -0,0 +1,45 @@
+package models
+
+// Talle - Talle disponible para productos y stock (S, M, 8, 10, 38...)
+type Talle struct {
+	ID     int    `gorm:"primaryKey;autoIncrement" json:"id"`
+	Nombre string `gorm:"type:varchar(10);not null;unique" json:"nombre"`
+	Orden  int    `gorm:"not null;default:0" json:"orden"` // Posición en listados y curvas de talles
+	Activo bool   `gorm:"default:true" json:"activo"`
+}
+
+// TableName especifica el nombre de la tabla
+func (Talle) TableName() string {
+	return "talles"
+}
+
+// Color - Color disponible para productos y stock
+type Color struct {
+	ID     int    `gorm:"primaryKey;autoIncrement" json:"id"`
+	Nombre string `gorm:"type:varchar(20);not null;unique" json:"nombre"`
+	Orden  int    `gorm:"not null;default:0" json:"orden"`
+	Activo bool   `gorm:"default:true" json:"activo"`
+}
+
+// TableName especifica el nombre de la tabla
+func (Color) TableName() string {
+	return "colores"
+}
+
+// TalleColorCreateRequest - Alta de un talle o color
+type TalleColorCreateRequest struct {
+	Nombre string `json:"nombre" binding:"required"`
+	Orden  *int   `json:"orden"` // Si no se indica va al final
+}
+
+// TalleColorUpdateRequest - Modificación de un talle o color. El nombre no se cambia porque
+// el stock y las ventas guardan el nombre; para reemplazarlo se crea otro y se desactiva este.
+type TalleColorUpdateRequest struct {
+	Orden  *int  `json:"orden"`
+	Activo *bool `json:"activo"`
+}
+
+// TipoProductoTallesRequest - Talles permitidos para un tipo de producto (vacío = todos)
+type TipoProductoTallesRequest struct {
+	TalleIDs []int `json:"talle_ids"`
+}
@@ -8,6 +8,13 @@ type TipoProducto struct {
 	Nombre        string    `gorm:"type:varchar(255);not null;unique" json:"nombre"`
 	Activo        bool      `gorm:"default:true" json:"activo"`
 	FechaCreacion time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"fecha_creacion"`
+
+	// Valores por defecto para las variantes que no tienen los suyos
+	StockMinimo        *int `json:"stock_minimo"`
+	CantidadReposicion *int `json:"cantidad_reposicion"`
+
+	// Talles permitidos para los productos de este tipo (vacío = todos los activos)
+	Talles []Talle `gorm:"many2many:tipo_producto_talles" json:"talles,omitempty"`
 }
 
 // TipoProductoCreateRequest - Request para crear tipo de producto
@@ -11,7 +11,6 @@ type Usuario struct {
 	Rol                 string    `gorm:"type:varchar(20);not null" json:"rol"`
 	Activo              bool      `gorm:"default:true" json:"activo"`
 	PorcentajeComision  float64   `gorm:"type:decimal(5,2);default:10" json:"porcentaje_comision"` // % de comisión (ej: 10 = 10%)
-	GastoPublicitario   float64   `gorm:"type:decimal(10,2);default:0" json:"gasto_publicitario"`  // Gasto publicitario mensual
 	Sueldo              float64   `gorm:"type:decimal(10,2);default:0" json:"sueldo"`              // Sueldo mensual del empleado
 	ObservacionesConfig string    `gorm:"type:text" json:"observaciones_config"`                   // Observaciones de configuración
 	FechaCreacion       time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"fecha_creacion"`
@@ -40,7 +39,6 @@ type RegistroRequest struct {
 // # actualizar configuración de comisión de un usuario
 type UsuarioComisionConfigRequest struct {
 	PorcentajeComision float64 `json:"porcentaje_comision" binding:"required"`
-	GastoPublicitario  float64 `json:"gasto_publicitario"`
 	Sueldo             float64 `json:"sueldo"`
 	Observaciones      string  `json:"observaciones"`
 }
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"vartan-backend/utils"
+)
+
+// VarianteProducto - Combinación de producto, talle y color identificada con un SKU y un código
+// de barras escaneable. Cada variante corresponde a un registro de stock.
+type VarianteProducto struct {
+	ID            int       `gorm:"primaryKey;autoIncrement" json:"id"`
+	StockID       int       `gorm:"not null;uniqueIndex" json:"stock_id"`
+	ProductoID    int       `gorm:"not null;index" json:"producto_id"`
+	Talle         string    `gorm:"type:varchar(10);not null" json:"talle"`
+	Color         string    `gorm:"type:varchar(20);not null" json:"color"`
+	SKU           string    `gorm:"column:sku;type:varchar(50);not null;uniqueIndex" json:"sku"`
+	CodigoBarras  string    `gorm:"type:varchar(20);not null;uniqueIndex" json:"codigo_barras"` // EAN-13 interno o el del proveedor
+	FechaCreacion time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"fecha_creacion"`
+
+	// Relaciones
+	Producto *Producto `gorm:"foreignKey:ProductoID" json:"producto,omitempty"`
+}
+
+// TableName especifica el nombre de la tabla
+func (VarianteProducto) TableName() string {
+	return "variantes_producto"
+}
+
+// NuevaVariante arma la variante de un registro de stock con el SKU y el código interno por defecto.
+// El código de barras se deriva del ID del stock, por lo que no se repite.
+func NuevaVariante(stock ProductoStock) VarianteProducto {
+	return VarianteProducto{
+		StockID:      stock.ID,
+		ProductoID:   stock.ProductoID,
+		Talle:        string(stock.Talle),
+		Color:        string(stock.Color),
+		SKU:          fmt.Sprintf("%d-%s-%s", stock.ProductoID, segmentoSKU(string(stock.Talle)), segmentoSKU(string(stock.Color))),
+		CodigoBarras: utils.CodigoEAN13Interno(stock.ID),
+	}
+}
+
+// segmentoSKU deja solo letras y números en mayúscula ("Celeste y blanco" -> "CELESTEYBLANCO")
+func segmentoSKU(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// VarianteResponse - Variante con el stock disponible
+type VarianteResponse struct {
+	VarianteProducto
+	Cantidad int `json:"cantidad"`
+}
+
+// VarianteUpdateRequest - Cambio de SKU o código de barras (por ejemplo para usar el EAN del proveedor)
+type VarianteUpdateRequest struct {
+	SKU          *string `json:"sku"`
+	CodigoBarras *string `json:"codigo_barras"`
+}
+
+// EtiquetaItem - Variante y cantidad de etiquetas a imprimir
+type EtiquetaItem struct {
+	VarianteID int `json:"variante_id" binding:"required"`
+	Copias     int `json:"copias"` // Por defecto 1
+}
+
+// EtiquetasRequest - Pedido de hoja de etiquetas
+type EtiquetasRequest struct {
+	Items []EtiquetaItem `json:"items" binding:"required,min=1"`
+}
@@ -69,7 +69,6 @@ func main() {
 			Rol:                 "empleado",
 			Activo:              true,
 			PorcentajeComision:  10.0,
-			GastoPublicitario:   0.0,
 			Sueldo:              0.0,
 			ObservacionesConfig: "",
 		}
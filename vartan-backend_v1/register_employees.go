@@ -79,7 +79,6 @@ func main() {
 			Rol:                "empleado",
 			Activo:             true,
 			PorcentajeComision: 10.0,
-			GastoPublicitario:  0.0,
 		}
 
 		if err := config.DB.Create(&usuario).Error; err != nil {
@@ -18,6 +18,9 @@ func SetupRoutes(router *gin.Engine) {
 		auth.POST("/register", controllers.Register)
 	}
 
+	// Fotos de productos: públicas para poder mostrarlas en <img> y compartirlas
+	router.GET("/imagenes/productos/:archivo", controllers.GetImagenProductoArchivo)
+
 	api := router.Group("/api")
 	api.Use(middleware.AuthMiddleware())
 	{
@@ -28,6 +31,10 @@ func SetupRoutes(router *gin.Engine) {
 		api.GET("/productos/:id", controllers.GetProducto)
 		api.GET("/stock", controllers.GetStock)
 		api.GET("/stock/producto/:id", controllers.GetStockByProducto)
+		api.GET("/productos/:id/variantes", controllers.GetVariantesProducto)
+		api.GET("/variantes/codigo/:code", controllers.GetVariantePorCodigo)
+		api.GET("/combos", controllers.GetCombos)
+		api.GET("/combos/:id", controllers.GetCombo)
 
 		// Tipos de producto
 		api.GET("/tipos-producto", controllers.GetTiposProducto)
@@ -37,24 +44,61 @@ func SetupRoutes(router *gin.Engine) {
 		api.GET("/equipos", controllers.GetEquipos)
 		api.GET("/equipos/:id", controllers.GetEquipo)
 
+		// Talles y colores
+		api.GET("/talles", controllers.GetTalles)
+		api.GET("/colores", controllers.GetColores)
+
 		api.GET("/clientes", controllers.GetClientes)
+		api.GET("/clientes/duplicados", controllers.GetClientesDuplicados)
 		api.GET("/clientes/:id", controllers.GetCliente)
 		api.POST("/clientes", controllers.CreateCliente)
 		api.PUT("/clientes/:id", controllers.UpdateCliente)
+		api.GET("/clientes/:id/cuenta-corriente", controllers.GetEstadoCuentaCliente)
+		api.POST("/clientes/:id/pagos", controllers.RegistrarPagoCliente)
+		api.GET("/clientes/:id/historial", controllers.GetClienteHistorial)
+		api.GET("/clientes/:id/puntos", controllers.GetPuntosCliente)
 
 		api.GET("/formas-pago", controllers.GetFormasPago)
+		api.GET("/saldos-a-favor/:codigo", controllers.GetSaldoAFavor)
+		api.GET("/programa-puntos", controllers.GetProgramaPuntos)
+		api.GET("/campanas", controllers.GetCampanas)
+		api.GET("/promociones", controllers.GetPromociones)
+		api.POST("/promociones/simular", controllers.SimularPromociones)
 		api.GET("/mis-ventas", controllers.GetMisVentas)
 		api.POST("/ventas", controllers.CreateVenta)
 		api.GET("/ventas/:id", controllers.GetVenta)
 		api.PUT("/ventas/:id", controllers.UpdateVenta)
 		api.DELETE("/ventas/:id", controllers.DeleteVenta)
 		api.GET("/ventas/:id/comprobante", controllers.GetVentaComprobante)
+		api.GET("/ventas/:id/recibo.pdf", controllers.GetReciboVenta)
+		api.POST("/ventas/:id/devoluciones", controllers.RegistrarDevolucion)
 		api.DELETE("/ventas/:id/comprobante", controllers.DeleteVentaComprobante)
 
+		// Presupuestos
+		api.GET("/presupuestos", controllers.GetPresupuestos)
+		api.POST("/presupuestos", controllers.CreatePresupuesto)
+		api.GET("/presupuestos/:id", controllers.GetPresupuesto)
+		api.PUT("/presupuestos/:id/estado", controllers.UpdatePresupuestoEstado)
+		api.GET("/presupuestos/:id/pdf", controllers.GetPresupuestoPDF)
+		api.POST("/presupuestos/:id/convertir", controllers.ConvertirPresupuesto)
+
 		api.GET("/mis-pedidos", controllers.GetMisPedidos)
 		api.PUT("/pedidos/:id", controllers.UpdatePedidoEstado)
+		api.GET("/pedidos/:id/remito.pdf", controllers.GetRemitoPedido)
 
 		api.GET("/mis-comisiones", controllers.GetMisComisiones)
+		api.GET("/mis-objetivos", controllers.GetMisObjetivos)
+		api.GET("/objetivos/ranking", controllers.GetRankingVendedores)
+
+		// Conteos de inventario (registro desde varios dispositivos)
+		api.GET("/conteos/:id/items", controllers.GetConteoItems)
+		api.POST("/conteos/:id/registros", controllers.RegistrarConteo)
+
+		// Caja
+		api.GET("/caja", controllers.GetCajaActual)
+		api.POST("/caja/abrir", controllers.AbrirCaja)
+		api.POST("/caja/movimientos", controllers.RegistrarMovimientoCaja)
+		api.POST("/caja/cerrar", controllers.CerrarCaja)
 
 		// Gastos
 		api.POST("/gastos", controllers.CrearGasto)
@@ -86,14 +130,34 @@ func SetupRoutes(router *gin.Engine) {
 		owner.POST("/productos", controllers.CreateProducto)
 		owner.PUT("/productos/:id", controllers.UpdateProducto)
 		owner.DELETE("/productos/:id", controllers.DeleteProducto)
+		owner.POST("/productos/importar", controllers.ImportarProductos)
+		owner.POST("/precios/actualizar", controllers.ActualizarPrecios)
+		owner.GET("/productos/:id/precios", controllers.GetHistorialPrecios)
+		owner.POST("/productos/:id/imagenes", controllers.UploadImagenesProducto)
+		owner.PUT("/productos/:id/imagenes/orden", controllers.OrdenarImagenesProducto)
+		owner.PUT("/productos/:id/imagenes/:imagenId/principal", controllers.SetImagenPrincipalProducto)
+		owner.DELETE("/productos/:id/imagenes/:imagenId", controllers.DeleteImagenProducto)
 
 		// Stock
 		owner.POST("/stock", controllers.AddStock)
 		owner.PUT("/stock/:id", controllers.UpdateStock)
+		owner.PUT("/stock/:id/reposicion", controllers.UpdateReposicionStock)
+		owner.PUT("/variantes/:id", controllers.UpdateVariante)
+		owner.POST("/variantes/etiquetas", controllers.GenerarEtiquetasVariantes)
+		owner.GET("/stock/alertas", controllers.GetAlertasStock)
+
+		// Conteos de inventario
+		owner.GET("/conteos", controllers.GetConteos)
+		owner.POST("/conteos", controllers.CrearConteo)
+		owner.GET("/conteos/:id", controllers.GetConteoRevision)
+		owner.POST("/conteos/:id/confirmar", controllers.ConfirmarConteo)
+		owner.POST("/conteos/:id/cancelar", controllers.CancelarConteo)
 
 		// Tipos de producto
 		owner.POST("/tipos-producto", controllers.CreateTipoProducto)
 		owner.PUT("/tipos-producto/:id", controllers.UpdateTipoProducto)
+		owner.PUT("/tipos-producto/:id/reposicion", controllers.UpdateReposicionTipoProducto)
+		owner.PUT("/tipos-producto/:id/talles", controllers.UpdateTallesTipoProducto)
 		owner.DELETE("/tipos-producto/:id", controllers.DeleteTipoProducto)
 
 		// Equipos
@@ -101,13 +165,67 @@ func SetupRoutes(router *gin.Engine) {
 		owner.PUT("/equipos/:id", controllers.UpdateEquipo)
 		owner.DELETE("/equipos/:id", controllers.DeleteEquipo)
 
+		// Talles y colores (se desactivan en lugar de borrarse)
+		owner.POST("/talles", controllers.CreateTalle)
+		owner.PUT("/talles/:id", controllers.UpdateTalle)
+		owner.POST("/colores", controllers.CreateColor)
+		owner.PUT("/colores/:id", controllers.UpdateColor)
+
+		// Gift cards (los saldos por devolución se emiten desde la venta)
+		owner.POST("/saldos-a-favor", controllers.CrearGiftCard)
+
+		// Programa de puntos de fidelidad
+		owner.PUT("/programa-puntos", controllers.UpdateProgramaPuntos)
+
+		// Campañas publicitarias y su inversión (se desactivan en lugar de borrarse)
+		owner.GET("/campanas/:id", controllers.GetCampana)
+		owner.POST("/campanas", controllers.CreateCampana)
+		owner.PUT("/campanas/:id", controllers.UpdateCampana)
+		owner.POST("/campanas/:id/gastos", controllers.CrearGastoCampana)
+		owner.DELETE("/campanas/:id/gastos/:gasto_id", controllers.EliminarGastoCampana)
+
+		// Objetivos mensuales de los vendedores
+		owner.GET("/objetivos", controllers.GetObjetivos)
+		owner.PUT("/objetivos", controllers.GuardarObjetivo)
+		owner.DELETE("/objetivos/:id", controllers.DeleteObjetivo)
+
+		// Combos (se desactivan en lugar de borrarse)
+		owner.POST("/combos", controllers.CreateCombo)
+		owner.PUT("/combos/:id", controllers.UpdateCombo)
+
+		// Promociones y cupones (se desactivan en lugar de borrarse)
+		owner.POST("/promociones", controllers.CreatePromocion)
+		owner.PUT("/promociones/:id", controllers.UpdatePromocion)
+
 		// Clientes (dueño puede eliminar)
 		owner.DELETE("/clientes/:id", controllers.DeleteCliente)
+		owner.PUT("/clientes/:id/limite-credito", controllers.UpdateLimiteCredito)
+		owner.POST("/clientes/:id/notas-credito", controllers.CrearNotaCredito)
+		owner.POST("/clientes/:id/fusionar", controllers.FusionarClientes)
+		owner.POST("/clientes/:id/restaurar", controllers.RestaurarCliente)
+		owner.GET("/cuentas-corrientes/antiguedad", controllers.GetAntiguedadSaldos)
 
 		// Ventas (ver todas)
 		owner.GET("/ventas", controllers.GetVentas)
 		owner.GET("/ventas/usuario/:id", controllers.GetVentasByUsuario)
 
+		// Dashboard de ventas
+		owner.GET("/dashboard", controllers.GetDashboard)
+
+		// Reportes
+		owner.GET("/reportes/resultados", controllers.GetEstadoResultados)
+		owner.GET("/reportes/flujo-caja", controllers.GetFlujoCaja)
+		owner.GET("/reportes/canales", controllers.GetReporteCanales)
+		owner.GET("/reportes/productos/mas-vendidos", controllers.GetProductosMasVendidos)
+		owner.GET("/reportes/productos/sell-through", controllers.GetSellThrough)
+		owner.GET("/reportes/productos/stock-inmovilizado", controllers.GetStockInmovilizado)
+		owner.GET("/reportes/productos/curva-talles", controllers.GetCurvaTalles)
+		owner.GET("/reportes/productos/cobertura", controllers.GetCoberturaStock)
+
+		// Historial de cajas
+		owner.GET("/cajas", controllers.GetCajas)
+		owner.GET("/cajas/:id", controllers.GetCaja)
+
 		// Pedidos (ver todos)
 		owner.GET("/pedidos", controllers.GetPedidos)
 		owner.GET("/pedidos/estado/:estado", controllers.GetPedidosByEstado)
@@ -117,5 +235,11 @@ func SetupRoutes(router *gin.Engine) {
 		owner.GET("/comisiones/usuario/:id", controllers.GetComisionesByUsuario)
 		owner.POST("/comisiones/calcular", controllers.CalcularComisionesMesActual)
 		owner.PUT("/comisiones/:id/observaciones", controllers.UpdateObservaciones)
+
+		// Exportaciones para el contador
+		owner.GET("/exportar/ventas", controllers.ExportarVentas)
+		owner.GET("/exportar/stock", controllers.ExportarStock)
+		owner.GET("/exportar/gastos", controllers.ExportarGastos)
+		owner.GET("/exportar/comisiones", controllers.ExportarComisiones)
 	}
 }
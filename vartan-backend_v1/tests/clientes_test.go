@@ -0,0 +1,28 @@
+package tests
+
+import (
+	"testing"
+	"vartan-backend/models"
+)
+
+func TestNormalizarTelefono(t *testing.T) {
+	casos := []struct {
+		telefono string
+		esperado string
+	}{
+		{"+54 9 11 5555-1234", "1155551234"},
+		{"011 15 5555 1234", "1155551234"},
+		{"1155551234", "1155551234"},
+		{"11 15 5555-1234", "1155551234"},
+		{"(0351) 15 555-1234", "3515551234"},
+		{"+54 351 555 1234", "3515551234"},
+		{"0054 9 2944 55 1234", "2944551234"},
+		{"5555-1234", "55551234"},
+	}
+
+	for _, caso := range casos {
+		if got := models.NormalizarTelefono(caso.telefono); got != caso.esperado {
+			t.Errorf("NormalizarTelefono(%q) = %q, se esperaba %q", caso.telefono, got, caso.esperado)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package tests
+
+import (
+	"testing"
+	"vartan-backend/models"
+)
+
+func TestRepartirPrecioCombo(t *testing.T) {
+	// Camiseta $30.000, short $15.000 y dos pares de medias de $2.500 por $40.000
+	unitarios := models.RepartirPrecioCombo(40000, []float64{30000, 15000, 5000}, []int{1, 1, 2})
+
+	suma := unitarios[0] + unitarios[1] + unitarios[2]*2
+	if suma != 40000 {
+		t.Errorf("la suma de los componentes es %v; se esperaba 40000 (%v)", suma, unitarios)
+	}
+	if unitarios[0] != 24000 || unitarios[1] != 12000 || unitarios[2] != 2000 {
+		t.Errorf("reparto = %v", unitarios)
+	}
+
+	// Los centavos del redondeo quedan en el primer componente de una unidad
+	unitarios = models.RepartirPrecioCombo(100, []float64{1, 1, 1}, []int{3, 1, 1})
+	if total := unitarios[0]*3 + unitarios[1] + unitarios[2]; total < 99.995 || total > 100.005 {
+		t.Errorf("reparto en tercios = %v (total %v)", unitarios, total)
+	}
+}
@@ -0,0 +1,207 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"vartan-backend/config"
+	"vartan-backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func seedCliente(t *testing.T, limiteCredito *float64) models.Cliente {
+	t.Helper()
+
+	cliente := models.Cliente{Nombre: fmt.Sprintf("Cliente test %d", time.Now().UnixNano()), LimiteCredito: limiteCredito}
+	if err := config.DB.Create(&cliente).Error; err != nil {
+		t.Fatalf("no se pudo crear cliente seed: %v", err)
+	}
+	return cliente
+}
+
+// seedFormaPago usa una forma sin recargo financiero ni movimiento de caja
+func seedFormaPago(t *testing.T) models.FormaPago {
+	t.Helper()
+
+	formaPago := models.FormaPago{Nombre: "Transferencia Bancaria"}
+	if err := config.DB.Where("nombre = ?", formaPago.Nombre).FirstOrCreate(&formaPago).Error; err != nil {
+		t.Fatalf("no se pudo crear forma de pago seed: %v", err)
+	}
+	return formaPago
+}
+
+// seedStock crea un producto de $1000 con 10 unidades en talle M, color Blanco
+func seedStock(t *testing.T) models.ProductoStock {
+	t.Helper()
+
+	producto := models.Producto{Nombre: fmt.Sprintf("Camiseta test %d", time.Now().UnixNano()), CostoUnitario: 400, PrecioVenta: 1000, Activo: true}
+	if err := config.DB.Create(&producto).Error; err != nil {
+		t.Fatalf("no se pudo crear producto seed: %v", err)
+	}
+	stock := models.ProductoStock{ProductoID: producto.ID, Talle: "M", Color: "Blanco", Cantidad: 10}
+	if err := config.DB.Create(&stock).Error; err != nil {
+		t.Fatalf("no se pudo crear stock seed: %v", err)
+	}
+	return stock
+}
+
+func ventaRequest(cliente models.Cliente, formaPago models.FormaPago, stock models.ProductoStock, cantidad int, sena float64) models.VentaCreateRequest {
+	return models.VentaCreateRequest{
+		ClienteID:   cliente.ID,
+		FormaPagoID: formaPago.ID,
+		Sena:        sena,
+		Detalles: []models.VentaDetalleCreateRequest{
+			{ProductoID: stock.ProductoID, Talle: string(stock.Talle), Color: string(stock.Color), Cantidad: cantidad, PrecioUnitario: 1000},
+		},
+	}
+}
+
+func enviarJSON(router *gin.Engine, metodo, url string, body interface{}) *httptest.ResponseRecorder {
+	datos, _ := json.Marshal(body)
+	req, _ := http.NewRequest(metodo, url, bytes.NewBuffer(datos))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func crearVenta(t *testing.T, router *gin.Engine, venta models.VentaCreateRequest) models.Venta {
+	t.Helper()
+
+	w := enviarJSON(router, "POST", "/api/ventas", venta)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status esperado %d, obtuve %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	var creada models.Venta
+	if err := json.Unmarshal(w.Body.Bytes(), &creada); err != nil {
+		t.Fatalf("respuesta de venta inválida: %v", err)
+	}
+	return creada
+}
+
+func cantidadEnStock(t *testing.T, stockID int) int {
+	t.Helper()
+
+	var stock models.ProductoStock
+	if err := config.DB.First(&stock, stockID).Error; err != nil {
+		t.Fatalf("no se pudo leer el stock: %v", err)
+	}
+	return stock.Cantidad
+}
+
+func mismoImporte(a, b float64) bool {
+	return math.Abs(a-b) < 0.005
+}
+
+func TestPagoImputadoAVenta(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+	router := setupRouter()
+	cliente, formaPago, stock := seedCliente(t, nil), seedFormaPago(t), seedStock(t)
+
+	venta := crearVenta(t, router, ventaRequest(cliente, formaPago, stock, 2, 100))
+	if !mismoImporte(venta.Saldo, 1900) {
+		t.Fatalf("saldo inicial = %v; se esperaba 1900", venta.Saldo)
+	}
+
+	w := enviarJSON(router, "POST", "/api/clientes/"+intToString(cliente.ID)+"/pagos", models.PagoClienteRequest{
+		Monto: 500, FormaPagoID: formaPago.ID, VentaID: &venta.ID,
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status esperado %d, obtuve %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	config.DB.First(&venta, venta.ID)
+	if !mismoImporte(venta.Saldo, 1400) {
+		t.Errorf("saldo después del pago = %v; se esperaba 1400", venta.Saldo)
+	}
+	var imputado float64
+	config.DB.Model(&models.MovimientoCuentaCorriente{}).
+		Select("COALESCE(SUM(monto), 0)").
+		Where("venta_id = ? AND concepto = ?", venta.ID, models.ConceptoPago).
+		Scan(&imputado)
+	if !mismoImporte(imputado, 500) {
+		t.Errorf("pago imputado a la venta = %v; se esperaba 500", imputado)
+	}
+
+	// Cambiar la seña no debe olvidar el pago ya imputado
+	sena := 200.0
+	w = enviarJSON(router, "PUT", "/api/ventas/"+intToString(venta.ID), models.VentaUpdateRequest{Sena: &sena})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status esperado %d, obtuve %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	config.DB.First(&venta, venta.ID)
+	if !mismoImporte(venta.Saldo, 1300) {
+		t.Errorf("saldo después de editar la seña = %v; se esperaba 1300", venta.Saldo)
+	}
+}
+
+func TestLimiteCreditoCliente(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+	router := setupRouter()
+	limite := 500.0
+	cliente, formaPago, stock := seedCliente(t, &limite), seedFormaPago(t), seedStock(t)
+
+	// Quedarían 900 pendientes con un límite de 500
+	w := enviarJSON(router, "POST", "/api/ventas", ventaRequest(cliente, formaPago, stock, 1, 100))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status esperado %d, obtuve %d: %s", http.StatusBadRequest, w.Code, w.Body.String())
+	}
+	if got := cantidadEnStock(t, stock.ID); got != 10 {
+		t.Errorf("stock después de la venta rechazada = %d; se esperaba 10", got)
+	}
+
+	// Con seña suficiente entra en el límite
+	crearVenta(t, router, ventaRequest(cliente, formaPago, stock, 1, 600))
+}
+
+func TestEliminarVentaRevierteCuentaCorriente(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+	router := setupRouter()
+	cliente, formaPago, stock := seedCliente(t, nil), seedFormaPago(t), seedStock(t)
+
+	venta := crearVenta(t, router, ventaRequest(cliente, formaPago, stock, 3, 200))
+	if got := cantidadEnStock(t, stock.ID); got != 7 {
+		t.Fatalf("stock después de vender = %d; se esperaba 7", got)
+	}
+	w := enviarJSON(router, "POST", "/api/clientes/"+intToString(cliente.ID)+"/pagos", models.PagoClienteRequest{
+		Monto: 300, FormaPagoID: formaPago.ID, VentaID: &venta.ID,
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status esperado %d, obtuve %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	w = enviarJSON(router, "DELETE", "/api/ventas/"+intToString(venta.ID), nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status esperado %d, obtuve %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	if got := cantidadEnStock(t, stock.ID); got != 10 {
+		t.Errorf("stock después de eliminar = %d; se esperaba 10", got)
+	}
+	var movimientos, pedidos int64
+	config.DB.Model(&models.MovimientoCuentaCorriente{}).Where("venta_id = ?", venta.ID).Count(&movimientos)
+	config.DB.Model(&models.Pedido{}).Where("venta_id = ?", venta.ID).Count(&pedidos)
+	if movimientos != 0 || pedidos != 0 {
+		t.Errorf("quedaron %d movimientos de cuenta corriente y %d pedidos de la venta eliminada", movimientos, pedidos)
+	}
+
+	// El pago queda en la cuenta del cliente como crédito sin imputar
+	var pagos float64
+	config.DB.Model(&models.MovimientoCuentaCorriente{}).
+		Select("COALESCE(SUM(monto), 0)").
+		Where("cliente_id = ? AND concepto = ? AND venta_id IS NULL", cliente.ID, models.ConceptoPago).
+		Scan(&pagos)
+	if !mismoImporte(pagos, 300) {
+		t.Errorf("pago sin imputar = %v; se esperaba 300", pagos)
+	}
+}
@@ -14,7 +14,7 @@ import (
 	"vartan-backend/models"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/driver/sqlserver"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
@@ -22,15 +22,43 @@ func setupTestDB(t *testing.T) {
 	t.Helper()
 
 	dsn := testDatabaseDSN(t)
-	db, err := gorm.Open(sqlserver.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
 	if err != nil {
 		t.Fatalf("no se pudo conectar a la DB de tests: %v", err)
 	}
 
 	config.DB = db
 
-	if err := config.DB.AutoMigrate(&models.Gasto{}); err != nil {
-		t.Fatalf("no se pudo migrar la tabla gastos: %v", err)
+	if err := config.DB.AutoMigrate(
+		&models.Gasto{},
+		&models.Usuario{},
+		&models.Producto{},
+		&models.ProductoStock{},
+		&models.VarianteProducto{},
+		&models.Promocion{},
+		&models.SaldoAFavor{},
+		&models.MovimientoSaldoAFavor{},
+		&models.ProgramaPuntos{},
+		&models.MovimientoPuntos{},
+		&models.Campana{},
+		&models.Cliente{},
+		&models.FormaPago{},
+		&models.Venta{},
+		&models.VentaDetalle{},
+		&models.Pedido{},
+		&models.Comision{},
+		&models.MovimientoCuentaCorriente{},
+		&models.Caja{},
+		&models.MovimientoCaja{},
+		&models.MovimientoStock{},
+	); err != nil {
+		t.Fatalf("no se pudieron migrar las tablas de tests: %v", err)
+	}
+
+	// Las ventas se registran a nombre del usuario autenticado (id 1 en setupRouter)
+	usuario := models.Usuario{ID: 1, Nombre: "Usuario test", Email: "test@vartan.local", PasswordHash: "-", Rol: "dueño", Activo: true}
+	if err := config.DB.Where("id = ?", 1).FirstOrCreate(&usuario).Error; err != nil {
+		t.Fatalf("no se pudo crear el usuario de tests: %v", err)
 	}
 }
 
@@ -49,6 +77,8 @@ func setupRouter() *gin.Engine {
 	router.Use(func(c *gin.Context) {
 		c.Set("cliente_id", uint(1))
 		c.Set("usuario_id", uint(1))
+		c.Set("user_id", 1)
+		c.Set("rol", "dueño")
 		c.Next()
 	})
 
@@ -61,6 +91,12 @@ func setupRouter() *gin.Engine {
 	router.GET("/api/gastos/por-mes", controllers.ObtenerGastosPorMes)
 	router.GET("/api/gastos/proveedores", controllers.ListarProveedores)
 
+	router.POST("/api/ventas", controllers.CreateVenta)
+	router.PUT("/api/ventas/:id", controllers.UpdateVenta)
+	router.DELETE("/api/ventas/:id", controllers.DeleteVenta)
+	router.POST("/api/clientes/:id/pagos", controllers.RegistrarPagoCliente)
+	router.POST("/api/saldos-a-favor", controllers.CrearGiftCard)
+
 	return router
 }
 
@@ -0,0 +1,54 @@
+package tests
+
+import (
+	"testing"
+	"vartan-backend/models"
+)
+
+func TestAplicarObjetivo(t *testing.T) {
+	monto := 1000000.0
+	unidades := 40
+
+	// Superó el monto pero no las unidades: no cumple
+	progreso := models.ProgresoObjetivo{Vendido: 1200000, Unidades: 30}
+	progreso.AplicarObjetivo(&models.ObjetivoVenta{ID: 1, MontoObjetivo: &monto, UnidadesObjetivo: &unidades, Bono: 50000})
+	if progreso.Cumplido {
+		t.Error("no debería cumplir sin las unidades")
+	}
+	if *progreso.PorcentajeMonto != 120 || *progreso.PorcentajeUnidades != 75 {
+		t.Errorf("porcentajes = %v, %v", *progreso.PorcentajeMonto, *progreso.PorcentajeUnidades)
+	}
+
+	// Solo meta en pesos
+	progreso = models.ProgresoObjetivo{Vendido: 1000000, Unidades: 3}
+	progreso.AplicarObjetivo(&models.ObjetivoVenta{ID: 2, MontoObjetivo: &monto, Bono: 50000})
+	if !progreso.Cumplido || progreso.PorcentajeUnidades != nil || progreso.Bono != 50000 {
+		t.Errorf("progreso = %+v", progreso)
+	}
+
+	// Sin objetivo no hay metas ni cumplimiento
+	progreso.AplicarObjetivo(nil)
+	if progreso.Cumplido || progreso.MontoObjetivo != nil || progreso.PorcentajeMonto != nil {
+		t.Errorf("sin objetivo = %+v", progreso)
+	}
+}
+
+func TestOrdenarRanking(t *testing.T) {
+	ranking := []models.ProgresoObjetivo{
+		{Nombre: "Ana", Vendido: 500, Unidades: 5},
+		{Nombre: "Beto", Vendido: 900, Unidades: 2},
+		{Nombre: "Caro", Vendido: 500, Unidades: 5},
+		{Nombre: "Dani", Vendido: 500, Unidades: 8},
+	}
+	models.OrdenarRanking(ranking)
+
+	esperado := []struct {
+		nombre   string
+		posicion int
+	}{{"Beto", 1}, {"Dani", 2}, {"Ana", 3}, {"Caro", 3}}
+	for i, e := range esperado {
+		if ranking[i].Nombre != e.nombre || ranking[i].Posicion != e.posicion {
+			t.Errorf("puesto %d = %s (%d); se esperaba %s (%d)", i, ranking[i].Nombre, ranking[i].Posicion, e.nombre, e.posicion)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+	"vartan-backend/utils"
+)
+
+func TestParseNumero(t *testing.T) {
+	casos := map[string]float64{
+		"1.234,56":   1234.56,
+		"1234.56":    1234.56,
+		"$ 2.500":    2500,
+		"1.250.000":  1250000,
+		"12.5":       12.5,
+		"$ 2.500,00": 2500,
+		"15":         15,
+	}
+	for texto, esperado := range casos {
+		got, err := utils.ParseNumero(texto)
+		if err != nil || got != esperado {
+			t.Errorf("ParseNumero(%q) = %v, %v; se esperaba %v", texto, got, err, esperado)
+		}
+	}
+	if _, err := utils.ParseNumero("abc"); err == nil {
+		t.Errorf("se esperaba error para un texto no numérico")
+	}
+}
+
+func TestLeerCSVPuntoYComa(t *testing.T) {
+	filas, err := utils.LeerCSV([]byte("\xef\xbb\xbfnombre;talle;cantidad\nCamiseta River;S, M;10\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	esperado := [][]string{{"nombre", "talle", "cantidad"}, {"Camiseta River", "S, M", "10"}}
+	if !reflect.DeepEqual(filas, esperado) {
+		t.Errorf("LeerCSV = %v", filas)
+	}
+}
+
+func TestLeerXLSX(t *testing.T) {
+	archivos := map[string]string{
+		"xl/workbook.xml":            `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Hoja1" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="worksheet" Target="worksheets/hoja.xml"/></Relationships>`,
+		"xl/sharedStrings.xml":       `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><si><t>nombre</t></si><si><r><t>Camiseta </t></r><r><t>Boca</t></r></si></sst>`,
+		"xl/worksheets/hoja.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="inlineStr"><is><t>cantidad</t></is></c></row>` +
+			`<row r="3"><c r="A3" t="s"><v>1</v></c><c r="C3"><v>12.5</v></c></row>` +
+			`</sheetData></worksheet>`,
+	}
+
+	filas, err := utils.LeerXLSX(armarXLSX(archivos))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// La fila 2 vacía se conserva y la celda B3 vacía se completa
+	esperado := [][]string{{"nombre", "cantidad"}, nil, {"Camiseta Boca", "", "12.5"}}
+	if !reflect.DeepEqual(filas, esperado) {
+		t.Errorf("LeerXLSX = %q", filas)
+	}
+
+	// Una celda más allá de la columna XFD no se lee
+	archivos["xl/worksheets/hoja.xml"] = `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+		`<row r="1"><c r="ZZZZZZ1"><v>1</v></c></row></sheetData></worksheet>`
+	if _, err := utils.LeerXLSX(armarXLSX(archivos)); err == nil {
+		t.Error("se esperaba error para una columna fuera de rango")
+	}
+
+	// Ni una fila posterior a la última de Excel
+	archivos["xl/worksheets/hoja.xml"] = `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+		`<row r="2000000000"><c r="A2000000000"><v>1</v></c></row></sheetData></worksheet>`
+	if _, err := utils.LeerXLSX(armarXLSX(archivos)); err == nil {
+		t.Error("se esperaba error para una fila fuera de rango")
+	}
+}
+
+func armarXLSX(archivos map[string]string) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for nombre, contenido := range archivos {
+		w, _ := zw.Create(nombre)
+		w.Write([]byte(contenido))
+	}
+	zw.Close()
+	return buf.Bytes()
+}
+
+func TestPlanillaCSV(t *testing.T) {
+	var buf bytes.Buffer
+	planilla, err := utils.NuevaPlanillaCSV(&buf, []string{"Fecha", "Cliente", "Total"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	planilla.Fila(time.Date(2026, 3, 5, 10, 0, 0, 0, time.UTC), "Pérez; Juan", 1234.5)
+	if err := planilla.Cerrar(); err != nil {
+		t.Fatal(err)
+	}
+
+	esperado := "\xef\xbb\xbfFecha;Cliente;Total\n05/03/2026;\"Pérez; Juan\";1.234,50\n"
+	if buf.String() != esperado {
+		t.Errorf("CSV = %q", buf.String())
+	}
+}
+
+func TestPlanillaXLSXSePuedeLeer(t *testing.T) {
+	var buf bytes.Buffer
+	planilla, err := utils.NuevaPlanillaXLSX(&buf, "ventas", []string{"Cliente", "Cantidad", "Total"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	planilla.Fila("Gómez & Hijos <SA>", 3, 99.9)
+	if err := planilla.Cerrar(); err != nil {
+		t.Fatal(err)
+	}
+
+	filas, err := utils.LeerXLSX(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	esperado := [][]string{{"Cliente", "Cantidad", "Total"}, {"Gómez & Hijos <SA>", "3", "99.9"}}
+	if !reflect.DeepEqual(filas, esperado) {
+		t.Errorf("XLSX releído = %q", filas)
+	}
+}
@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"testing"
+	"time"
+	"vartan-backend/models"
+)
+
+func TestAsignarPromociones2x1YPorcentaje(t *testing.T) {
+	camiseta, buzo := 1, 2
+	lineas := []models.LineaPromocion{
+		{ProductoID: 10, TipoProductoID: &camiseta, Cantidad: 1, PrecioUnitario: 100},
+		{ProductoID: 11, TipoProductoID: &camiseta, Cantidad: 1, PrecioUnitario: 80},
+		{ProductoID: 12, TipoProductoID: &camiseta, Cantidad: 1, PrecioUnitario: 60},
+		{ProductoID: 20, TipoProductoID: &buzo, Cantidad: 2, PrecioUnitario: 50},
+	}
+	promociones := []models.Promocion{
+		{ID: 1, Tipo: models.PromocionLlevaPaga, Cantidad: 2, CantidadPaga: 1, TipoProductoID: &camiseta, Activo: true},
+		{ID: 2, Tipo: models.PromocionPorcentaje, Valor: 10, Activo: true},
+	}
+
+	asignadas := models.AsignarPromociones(lineas, promociones)
+
+	// El 2x1 agrupa las dos camisetas más caras y no cobra la de 80; la tercera queda para el 10%
+	esperado := []models.PromocionLinea{
+		{PromocionID: 1, Descuento: 0},
+		{PromocionID: 1, Descuento: 80},
+		{PromocionID: 2, Descuento: 6},
+		{PromocionID: 2, Descuento: 10},
+	}
+	for i, e := range esperado {
+		if asignadas[i] == nil || *asignadas[i] != e {
+			t.Errorf("renglón %d: %+v; se esperaba %+v", i, asignadas[i], e)
+		}
+	}
+}
+
+func TestAsignarPromocionesPrecioFijoYCupon(t *testing.T) {
+	codigo := "VUELTA10"
+	lineas := []models.LineaPromocion{
+		{ProductoID: 1, Cantidad: 3, PrecioUnitario: 100},
+		{ProductoID: 2, Cantidad: 1, PrecioUnitario: 40},
+	}
+	promociones := []models.Promocion{
+		{ID: 1, Tipo: models.PromocionPrecioFijo, Cantidad: 3, Valor: 240, ProductoID: &lineas[0].ProductoID, Activo: true},
+		{ID: 2, Tipo: models.PromocionMonto, Valor: 5, Codigo: &codigo, Activo: true},
+	}
+
+	asignadas := models.AsignarPromociones(lineas, promociones)
+
+	// El cupón se aplica primero aunque descuente menos que las 3 por $240
+	if asignadas[0] == nil || asignadas[0].PromocionID != 2 || asignadas[0].Descuento != 15 {
+		t.Errorf("renglón 0: %+v", asignadas[0])
+	}
+	if asignadas[1] == nil || asignadas[1].PromocionID != 2 || asignadas[1].Descuento != 5 {
+		t.Errorf("renglón 1: %+v", asignadas[1])
+	}
+
+	asignadas = models.AsignarPromociones(lineas, promociones[:1])
+	if asignadas[0] == nil || asignadas[0].Descuento != 60 || asignadas[1] != nil {
+		t.Errorf("3 por $240: %+v, %+v", asignadas[0], asignadas[1])
+	}
+}
+
+func TestAsignarPromocionesGruposPorCantidad(t *testing.T) {
+	lineas := []models.LineaPromocion{
+		{ProductoID: 1, Cantidad: 4, PrecioUnitario: 100},
+		{ProductoID: 2, Cantidad: 2, PrecioUnitario: 50},
+		{ProductoID: 3, Cantidad: 100000, PrecioUnitario: 10},
+	}
+	promociones := []models.Promocion{
+		{ID: 1, Tipo: models.PromocionLlevaPaga, Cantidad: 3, CantidadPaga: 2, Activo: true},
+	}
+
+	asignadas := models.AsignarPromociones(lineas[:2], promociones)
+
+	// Un 3x2 entero con los de 100 y otro que combina el último de 100 con los dos de 50
+	if asignadas[0] == nil || asignadas[0].Descuento != 100 {
+		t.Errorf("renglón 0: %+v", asignadas[0])
+	}
+	if asignadas[1] == nil || asignadas[1].Descuento != 50 {
+		t.Errorf("renglón 1: %+v", asignadas[1])
+	}
+
+	// Muchas unidades en un renglón se agrupan sin recorrerlas una por una
+	asignadas = models.AsignarPromociones(lineas, promociones)
+	if asignadas[2] == nil || asignadas[2].Descuento != 333330 {
+		t.Errorf("renglón 2: %+v", asignadas[2])
+	}
+}
+
+func TestPromocionVigente(t *testing.T) {
+	desde := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	hasta := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	maximo := 2
+	p := models.Promocion{FechaDesde: &desde, FechaHasta: &hasta, UsosMaximos: &maximo, Usos: 1, Activo: true}
+
+	if !p.Vigente(time.Date(2024, 3, 31, 22, 0, 0, 0, time.Local)) {
+		t.Errorf("la fecha hasta debe ser inclusive")
+	}
+	if p.Vigente(time.Date(2024, 2, 29, 12, 0, 0, 0, time.Local)) {
+		t.Errorf("no debe estar vigente antes de la fecha desde")
+	}
+	p.Usos = 2
+	if p.Vigente(time.Date(2024, 3, 10, 12, 0, 0, 0, time.Local)) {
+		t.Errorf("no debe estar vigente con los usos agotados")
+	}
+}
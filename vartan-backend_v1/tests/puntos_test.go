@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"vartan-backend/config"
+	"vartan-backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// activarProgramaPuntos deja 1 punto cada $100 y $10 por punto, y restaura el programa al terminar
+func activarProgramaPuntos(t *testing.T) {
+	t.Helper()
+
+	var programa models.ProgramaPuntos
+	config.DB.Order("id").Limit(1).Find(&programa)
+	anterior := programa
+	t.Cleanup(func() {
+		if anterior.ID == 0 {
+			config.DB.Delete(&programa)
+		} else {
+			config.DB.Save(&anterior)
+		}
+	})
+
+	programa.Activo, programa.PesosPorPunto, programa.ValorPunto, programa.DiasVencimiento = true, 100, 10, 0
+	if err := config.DB.Save(&programa).Error; err != nil {
+		t.Fatalf("no se pudo activar el programa de puntos: %v", err)
+	}
+}
+
+func saldoPuntos(clienteID int) int {
+	var puntos int
+	config.DB.Model(&models.MovimientoPuntos{}).Select("COALESCE(SUM(puntos), 0)").Where("cliente_id = ?", clienteID).Scan(&puntos)
+	return puntos
+}
+
+func TestPuntosGanadosYCanjeados(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+	router := setupRouter()
+	activarProgramaPuntos(t)
+	cliente, formaPago, stock := seedCliente(t, nil), seedFormaPago(t), seedStock(t)
+
+	// $2000 a 1 punto cada $100
+	primera := crearVenta(t, router, ventaRequest(cliente, formaPago, stock, 2, 100))
+	if primera.PuntosGanados != 20 {
+		t.Fatalf("puntos ganados = %d; se esperaba 20", primera.PuntosGanados)
+	}
+
+	// Canje de 10 puntos = $100 de descuento; sobre los $900 restantes se ganan 9
+	req := ventaRequest(cliente, formaPago, stock, 1, 100)
+	req.PuntosCanje = 10
+	segunda := crearVenta(t, router, req)
+	if !mismoImporte(segunda.DescuentoPuntos, 100) || !mismoImporte(segunda.TotalFinal, 900) || segunda.PuntosGanados != 9 {
+		t.Errorf("venta con canje: descuento %v, total final %v, puntos ganados %d", segunda.DescuentoPuntos, segunda.TotalFinal, segunda.PuntosGanados)
+	}
+	if got := saldoPuntos(cliente.ID); got != 19 {
+		t.Errorf("saldo de puntos = %d; se esperaba 19", got)
+	}
+
+	// No se pueden canjear más puntos de los que tiene
+	req.PuntosCanje = 50
+	w := enviarJSON(router, "POST", "/api/ventas", req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status esperado %d al canjear de más, obtuve %d", http.StatusBadRequest, w.Code)
+	}
+
+	// Eliminar la venta con canje devuelve los puntos usados y quita los ganados
+	w = enviarJSON(router, "DELETE", "/api/ventas/"+intToString(segunda.ID), nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status esperado %d, obtuve %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if got := saldoPuntos(cliente.ID); got != 20 {
+		t.Errorf("saldo de puntos después de eliminar = %d; se esperaba 20", got)
+	}
+}
+
+func TestCambioDeClienteMuevePuntos(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+	router := setupRouter()
+	activarProgramaPuntos(t)
+	cliente, otro, formaPago, stock := seedCliente(t, nil), seedCliente(t, nil), seedFormaPago(t), seedStock(t)
+
+	primera := crearVenta(t, router, ventaRequest(cliente, formaPago, stock, 2, 100))
+	req := ventaRequest(cliente, formaPago, stock, 1, 100)
+	req.PuntosCanje = 10
+	segunda := crearVenta(t, router, req)
+
+	// El otro cliente no tiene los puntos que se canjearon en la venta
+	w := enviarJSON(router, "PUT", "/api/ventas/"+intToString(segunda.ID), models.VentaUpdateRequest{ClienteID: &otro.ID})
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status esperado %d, obtuve %d: %s", http.StatusBadRequest, w.Code, w.Body.String())
+	}
+
+	// Los 20 puntos de la primera venta pasan al otro cliente; el original solo pierde los 10 que no usó
+	w = enviarJSON(router, "PUT", "/api/ventas/"+intToString(primera.ID), models.VentaUpdateRequest{ClienteID: &otro.ID})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status esperado %d, obtuve %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if got := saldoPuntos(cliente.ID); got != 9 {
+		t.Errorf("saldo de puntos del cliente original = %d; se esperaba 9", got)
+	}
+	if got := saldoPuntos(otro.ID); got != 20 {
+		t.Errorf("saldo de puntos del nuevo cliente = %d; se esperaba 20", got)
+	}
+}
@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"vartan-backend/config"
+	"vartan-backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func crearGiftCard(t *testing.T, router *gin.Engine, monto float64) models.SaldoAFavor {
+	t.Helper()
+
+	w := enviarJSON(router, "POST", "/api/saldos-a-favor", models.GiftCardCreateRequest{Monto: monto})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status esperado %d, obtuve %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	var gift models.SaldoAFavor
+	if err := json.Unmarshal(w.Body.Bytes(), &gift); err != nil {
+		t.Fatalf("respuesta de gift card inválida: %v", err)
+	}
+	return gift
+}
+
+func TestVentaPagadaConGiftCard(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+	router := setupRouter()
+	cliente, formaPago, stock := seedCliente(t, nil), seedFormaPago(t), seedStock(t)
+	gift := crearGiftCard(t, router, 600)
+
+	req := ventaRequest(cliente, formaPago, stock, 1, 100)
+	req.SaldoAFavorCodigo = gift.Codigo
+	venta := crearVenta(t, router, req)
+	if !mismoImporte(venta.PagoSaldoAFavor, 600) || !mismoImporte(venta.Saldo, 300) {
+		t.Errorf("pago con gift card = %v, saldo = %v; se esperaba 600 y 300", venta.PagoSaldoAFavor, venta.Saldo)
+	}
+	config.DB.First(&gift, gift.ID)
+	if !mismoImporte(gift.Saldo, 0) {
+		t.Errorf("saldo de la gift card = %v; se esperaba 0", gift.Saldo)
+	}
+
+	// Ya consumida no puede volver a usarse
+	w := enviarJSON(router, "POST", "/api/ventas", req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status esperado %d con la gift card agotada, obtuve %d", http.StatusBadRequest, w.Code)
+	}
+
+	// El consumo queda en el cliente original: no se puede pasar la venta a otro
+	otro := seedCliente(t, nil)
+	w = enviarJSON(router, "PUT", "/api/ventas/"+intToString(venta.ID), models.VentaUpdateRequest{ClienteID: &otro.ID})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status esperado %d al cambiar el cliente, obtuve %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestEliminarVentaReintegraGiftCard(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+	router := setupRouter()
+	cliente, formaPago, stock := seedCliente(t, nil), seedFormaPago(t), seedStock(t)
+	gift := crearGiftCard(t, router, 300)
+
+	req := ventaRequest(cliente, formaPago, stock, 3, 200)
+	req.SaldoAFavorCodigo = gift.Codigo
+	venta := crearVenta(t, router, req)
+
+	w := enviarJSON(router, "DELETE", "/api/ventas/"+intToString(venta.ID), nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status esperado %d, obtuve %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	config.DB.First(&gift, gift.ID)
+	if !mismoImporte(gift.Saldo, 300) {
+		t.Errorf("saldo de la gift card = %v; se esperaba 300 reintegrados", gift.Saldo)
+	}
+	var movimientos int64
+	config.DB.Model(&models.MovimientoCuentaCorriente{}).
+		Where("venta_id = ? AND concepto = ?", venta.ID, models.ConceptoSaldoAFavor).
+		Count(&movimientos)
+	if movimientos != 0 {
+		t.Errorf("quedaron %d créditos por saldo a favor de la venta eliminada", movimientos)
+	}
+}
@@ -0,0 +1,145 @@
+package tests
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"testing"
+	"time"
+	"vartan-backend/utils"
+)
+
+func TestFormatoNumero(t *testing.T) {
+	casos := []struct {
+		valor     float64
+		decimales int
+		esperado  string
+	}{
+		{0, 2, "0,00"},
+		{999.5, 2, "999,50"},
+		{1234.56, 2, "1.234,56"},
+		{1234567.891, 2, "1.234.567,89"},
+		{-1500, 0, "-1.500"},
+		{-0.001, 2, "0,00"},
+	}
+
+	for _, caso := range casos {
+		if got := utils.FormatoNumero(caso.valor, caso.decimales); got != caso.esperado {
+			t.Errorf("FormatoNumero(%v, %d) = %q, se esperaba %q", caso.valor, caso.decimales, got, caso.esperado)
+		}
+	}
+
+	if got := utils.FormatoMoneda(2500); got != "$ 2.500,00" {
+		t.Errorf("FormatoMoneda(2500) = %q", got)
+	}
+
+	if got := utils.FormatoFecha(time.Date(2026, 2, 1, 15, 0, 0, 0, time.UTC)); got != "01/02/2026" {
+		t.Errorf("FormatoFecha = %q", got)
+	}
+}
+
+func TestPDFGenerado(t *testing.T) {
+	pdf := utils.NewPDF()
+	pdf.AddPage()
+	pdf.SetFont(true, 12)
+	pdf.Text(40, 60, "Presupuesto (Camiseta) Ñandú")
+	pdf.AddPage()
+	pdf.Line(40, 100, 200, 100, 1)
+
+	data := pdf.Bytes()
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4")) {
+		t.Fatalf("el documento no empieza con la cabecera PDF")
+	}
+	if !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Fatalf("el documento no termina con %%%%EOF")
+	}
+	if !bytes.Contains(data, []byte("/Count 2")) {
+		t.Errorf("se esperaban 2 páginas en el documento")
+	}
+	// Los paréntesis se escapan y los caracteres Latin-1 se codifican en octal
+	if !bytes.Contains(data, []byte(`\(Camiseta\) \321and\372`)) {
+		t.Errorf("el texto no fue codificado correctamente")
+	}
+}
+
+func TestPDFImagenJPEG(t *testing.T) {
+	var jpg bytes.Buffer
+	if err := jpeg.Encode(&jpg, image.NewRGBA(image.Rect(0, 0, 12, 8)), nil); err != nil {
+		t.Fatal(err)
+	}
+	logo := jpg.Bytes()
+
+	pdf := utils.NewPDF()
+	pdf.AddPage()
+	if err := pdf.Image(logo, 40, 30, 60, 40); err != nil {
+		t.Fatalf("Image: %v", err)
+	}
+	pdf.AddPage()
+	if err := pdf.Image(logo, 40, 30, 60, 40); err != nil {
+		t.Fatalf("Image: %v", err)
+	}
+	if err := pdf.Image([]byte("no es un jpeg"), 0, 0, 10, 10); err == nil {
+		t.Error("se esperaba un error con datos que no son JPEG")
+	}
+
+	data := pdf.Bytes()
+	// La imagen se incrusta una sola vez aunque se dibuje en las dos páginas
+	if n := bytes.Count(data, []byte("/Filter /DCTDecode")); n != 1 {
+		t.Errorf("imágenes incrustadas = %d, se esperaba 1", n)
+	}
+	if !bytes.Contains(data, []byte("/Width 12 /Height 8 /ColorSpace /DeviceRGB")) {
+		t.Errorf("dimensiones de la imagen incorrectas")
+	}
+	if n := bytes.Count(data, []byte("/Im1 Do")); n != 2 {
+		t.Errorf("dibujos de la imagen = %d, se esperaban 2", n)
+	}
+}
+
+func TestPDFTextWidth(t *testing.T) {
+	pdf := utils.NewPDF()
+	pdf.SetFont(false, 10)
+	if got := pdf.TextWidth("MM"); got != 16.66 {
+		t.Errorf("TextWidth(MM) = %v, se esperaba 16.66", got)
+	}
+
+	recortado := pdf.TruncateText("Camiseta titular temporada 2026", 60)
+	if pdf.TextWidth(recortado) > 60 {
+		t.Errorf("TruncateText devolvió un texto más ancho que el límite: %q", recortado)
+	}
+}
+
+func TestEAN13(t *testing.T) {
+	if got := utils.CodigoEAN13Interno(1); got != "2000000000015" {
+		t.Errorf("CodigoEAN13Interno(1) = %q", got)
+	}
+	if !utils.EsEAN13("4006381333931") {
+		t.Errorf("4006381333931 debería ser un EAN-13 válido")
+	}
+	if utils.EsEAN13("4006381333932") || utils.EsEAN13("400638133393") {
+		t.Errorf("se aceptó un EAN-13 inválido")
+	}
+
+	barras, err := utils.BarrasEAN13("4006381333931")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(barras) != 95 || barras[:3] != "101" || barras[45:50] != "01010" || barras[92:] != "101" {
+		t.Errorf("estructura de barras incorrecta: %s", barras)
+	}
+	// El 4 inicial usa la paridad LGLLGG: el primer dígito (0) va con el juego L
+	if barras[3:10] != "0001101" {
+		t.Errorf("codificación del primer dígito incorrecta: %s", barras[3:10])
+	}
+}
+
+func TestMiniatura(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 1000, 500))
+	if b := utils.Miniatura(img, 400).Bounds(); b.Dx() != 400 || b.Dy() != 200 {
+		t.Errorf("miniatura de %dx%d, se esperaba 400x200", b.Dx(), b.Dy())
+	}
+
+	chica := image.NewRGBA(image.Rect(0, 0, 100, 300))
+	if utils.Miniatura(chica, 400) != image.Image(chica) {
+		t.Errorf("una imagen más chica que el límite no debe redimensionarse")
+	}
+}
@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PrefijoEAN13Interno - Los prefijos 20-29 están reservados para uso interno del comercio,
+// así que los códigos generados no chocan con los de ningún fabricante
+const PrefijoEAN13Interno = "20"
+
+// CodigoEAN13Interno genera un EAN-13 interno a partir de un número (por ejemplo el ID del stock)
+func CodigoEAN13Interno(numero int) string {
+	base := fmt.Sprintf("%s%010d", PrefijoEAN13Interno, numero)
+	return base + fmt.Sprint(DigitoControlEAN13(base))
+}
+
+// DigitoControlEAN13 calcula el dígito verificador de los primeros 12 dígitos
+func DigitoControlEAN13(doce string) int {
+	suma := 0
+	for i, r := range doce[:12] {
+		d := int(r - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		suma += d
+	}
+	return (10 - suma%10) % 10
+}
+
+// EsEAN13 indica si el código tiene 13 dígitos y el verificador es correcto
+func EsEAN13(codigo string) bool {
+	if len(codigo) != 13 {
+		return false
+	}
+	for _, r := range codigo {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return DigitoControlEAN13(codigo) == int(codigo[12]-'0')
+}
+
+// Codificación de cada dígito (juegos L y G; el R es el complemento de L)
+var (
+	ean13L = [10]string{"0001101", "0011001", "0010011", "0111101", "0100011", "0110001", "0101111", "0111011", "0110111", "0001011"}
+	ean13G = [10]string{"0100111", "0110011", "0011011", "0100001", "0011101", "0111001", "0000101", "0010001", "0001001", "0010111"}
+	// El primer dígito no se dibuja: define qué juego usa cada dígito de la mitad izquierda
+	ean13Paridad = [10]string{"LLLLLL", "LLGLGG", "LLGGLG", "LLGGGL", "LGLLGG", "LGGLLG", "LGGGLL", "LGLGLG", "LGLGGL", "LGGLGL"}
+)
+
+// BarrasEAN13 devuelve los 95 módulos del código ("1" barra, "0" espacio)
+func BarrasEAN13(codigo string) (string, error) {
+	if !EsEAN13(codigo) {
+		return "", fmt.Errorf("código EAN-13 inválido: %s", codigo)
+	}
+
+	var b strings.Builder
+	b.WriteString("101")
+	paridad := ean13Paridad[codigo[0]-'0']
+	for i := 1; i <= 6; i++ {
+		d := codigo[i] - '0'
+		if paridad[i-1] == 'L' {
+			b.WriteString(ean13L[d])
+		} else {
+			b.WriteString(ean13G[d])
+		}
+	}
+	b.WriteString("01010")
+	for i := 7; i <= 12; i++ {
+		for _, r := range ean13L[codigo[i]-'0'] {
+			if r == '0' {
+				b.WriteByte('1')
+			} else {
+				b.WriteByte('0')
+			}
+		}
+	}
+	b.WriteString("101")
+	return b.String(), nil
+}
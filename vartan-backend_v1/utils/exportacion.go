@@ -0,0 +1,206 @@
+package utils
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Planilla - Escritor de filas para exportar a CSV o XLSX a medida que se leen de la base,
+// sin armar el archivo completo en memoria.
+// Los valores pueden ser string, int, int64, float64 (importe), bool, time.Time, *time.Time o nil.
+type Planilla interface {
+	Fila(valores ...interface{}) error
+	Cerrar() error
+}
+
+// filasPorFlush - Cada cuántas filas se envía lo acumulado al cliente
+const filasPorFlush = 500
+
+// NuevaPlanillaCSV escribe un CSV con punto y coma, BOM y formato argentino para que Excel
+// en español lo abra directamente
+func NuevaPlanillaCSV(w io.Writer, encabezados []string) (Planilla, error) {
+	if _, err := io.WriteString(w, "\xef\xbb\xbf"); err != nil {
+		return nil, err
+	}
+	escritor := csv.NewWriter(w)
+	escritor.Comma = ';'
+	if err := escritor.Write(encabezados); err != nil {
+		return nil, err
+	}
+	return &planillaCSV{escritor: escritor, celdas: make([]string, len(encabezados))}, nil
+}
+
+type planillaCSV struct {
+	escritor *csv.Writer
+	celdas   []string
+	filas    int
+}
+
+func (p *planillaCSV) Fila(valores ...interface{}) error {
+	p.celdas = p.celdas[:0]
+	for _, v := range valores {
+		p.celdas = append(p.celdas, textoCelda(v))
+	}
+	if err := p.escritor.Write(p.celdas); err != nil {
+		return err
+	}
+	p.filas++
+	if p.filas%filasPorFlush == 0 {
+		p.escritor.Flush()
+	}
+	return p.escritor.Error()
+}
+
+func (p *planillaCSV) Cerrar() error {
+	p.escritor.Flush()
+	return p.escritor.Error()
+}
+
+// textoCelda convierte un valor al texto que se escribe en el CSV
+func textoCelda(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case int:
+		return strconv.Itoa(x)
+	case int64:
+		return strconv.FormatInt(x, 10)
+	case float64:
+		return FormatoNumero(x, 2)
+	case bool:
+		if x {
+			return "Sí"
+		}
+		return "No"
+	case time.Time:
+		return FormatoFecha(x)
+	case *time.Time:
+		if x == nil {
+			return ""
+		}
+		return FormatoFecha(*x)
+	default:
+		return fmt.Sprint(x)
+	}
+}
+
+// Estilos de celda definidos en styles.xml
+const (
+	estiloXLSXEncabezado = 1
+	estiloXLSXImporte    = 2
+	estiloXLSXFecha      = 3
+)
+
+// NuevaPlanillaXLSX escribe un XLSX de una hoja. Los importes y fechas se guardan como números
+// con formato, así Excel los muestra según la configuración regional (1.234,56 y dd/mm/aaaa)
+// y se pueden sumar y filtrar.
+func NuevaPlanillaXLSX(w io.Writer, hoja string, encabezados []string) (Planilla, error) {
+	zw := zip.NewWriter(w)
+	fijos := []struct{ nombre, contenido string }{
+		{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/><Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/></Types>`},
+		{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+		{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="` + escaparXML(hoja) + `" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/></Relationships>`},
+		{"xl/styles.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><numFmts count="1"><numFmt numFmtId="164" formatCode="dd/mm/yyyy"/></numFmts><fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts><fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills><borders count="1"><border/></borders><cellStyleXfs count="1"><xf/></cellStyleXfs><cellXfs count="4"><xf/><xf fontId="1" applyFont="1"/><xf numFmtId="4" applyNumberFormat="1"/><xf numFmtId="164" applyNumberFormat="1"/></cellXfs></styleSheet>`},
+	}
+	for _, f := range fijos {
+		archivo, err := zw.Create(f.nombre)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(archivo, f.contenido); err != nil {
+			return nil, err
+		}
+	}
+
+	archivo, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	p := &planillaXLSX{zip: zw, hoja: bufio.NewWriter(archivo)}
+	p.hoja.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	p.hoja.WriteString("<row>")
+	for _, e := range encabezados {
+		fmt.Fprintf(p.hoja, `<c t="inlineStr" s="%d"><is><t>%s</t></is></c>`, estiloXLSXEncabezado, escaparXML(e))
+	}
+	p.hoja.WriteString("</row>")
+	return p, nil
+}
+
+type planillaXLSX struct {
+	zip  *zip.Writer
+	hoja *bufio.Writer
+}
+
+func (p *planillaXLSX) Fila(valores ...interface{}) error {
+	p.hoja.WriteString("<row>")
+	for _, v := range valores {
+		switch x := v.(type) {
+		case nil:
+			p.hoja.WriteString("<c/>")
+		case int:
+			fmt.Fprintf(p.hoja, "<c><v>%d</v></c>", x)
+		case int64:
+			fmt.Fprintf(p.hoja, "<c><v>%d</v></c>", x)
+		case float64:
+			fmt.Fprintf(p.hoja, `<c s="%d"><v>%s</v></c>`, estiloXLSXImporte, strconv.FormatFloat(x, 'f', -1, 64))
+		case time.Time:
+			fmt.Fprintf(p.hoja, `<c s="%d"><v>%s</v></c>`, estiloXLSXFecha, strconv.FormatFloat(serialExcel(x), 'f', -1, 64))
+		case *time.Time:
+			if x == nil {
+				p.hoja.WriteString("<c/>")
+			} else {
+				fmt.Fprintf(p.hoja, `<c s="%d"><v>%s</v></c>`, estiloXLSXFecha, strconv.FormatFloat(serialExcel(*x), 'f', -1, 64))
+			}
+		default:
+			fmt.Fprintf(p.hoja, `<c t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, escaparXML(textoCelda(x)))
+		}
+	}
+	_, err := p.hoja.WriteString("</row>")
+	return err
+}
+
+func (p *planillaXLSX) Cerrar() error {
+	p.hoja.WriteString("</sheetData></worksheet>")
+	if err := p.hoja.Flush(); err != nil {
+		return err
+	}
+	return p.zip.Close()
+}
+
+// serialExcel convierte la fecha al número de días desde el 30/12/1899 que usa Excel,
+// conservando la hora como fracción del día
+func serialExcel(t time.Time) float64 {
+	base := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	local := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+	return local.Sub(base).Hours() / 24
+}
+
+// escaparXML escapa el texto y descarta los caracteres de control que el XML no admite
+func escaparXML(s string) string {
+	limpio := make([]rune, 0, len(s))
+	for _, r := range s {
+		if r == '\t' || r == '\n' || r == '\r' || r >= 0x20 {
+			limpio = append(limpio, r)
+		}
+	}
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(string(limpio)))
+	return b.String()
+}
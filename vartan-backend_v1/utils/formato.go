@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FormatoNumero formatea un número al estilo argentino: 1.234,56
+func FormatoNumero(valor float64, decimales int) string {
+	negativo := valor < 0
+	valor = math.Abs(valor)
+
+	texto := strconv.FormatFloat(valor, 'f', decimales, 64)
+	entero, fraccion, _ := strings.Cut(texto, ".")
+
+	// Separador de miles
+	var b strings.Builder
+	for i, d := range entero {
+		if i > 0 && (len(entero)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(d)
+	}
+	if fraccion != "" {
+		b.WriteByte(',')
+		b.WriteString(fraccion)
+	}
+
+	if negativo && strings.Trim(b.String(), "0.,") != "" {
+		return "-" + b.String()
+	}
+	return b.String()
+}
+
+// FormatoMoneda formatea un importe en pesos: $ 1.234,56
+func FormatoMoneda(valor float64) string {
+	return "$ " + FormatoNumero(valor, 2)
+}
+
+// FormatoFecha formatea una fecha como dd/mm/aaaa
+func FormatoFecha(t time.Time) string {
+	return t.Format("02/01/2006")
+}
+
+// ParseNumero interpreta un número escrito al estilo argentino (1.234,56 o 2.500) o con punto decimal (1234.56)
+func ParseNumero(texto string) (float64, error) {
+	texto = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(texto), "$"))
+	if strings.Contains(texto, ",") || milesConPunto.MatchString(texto) {
+		texto = strings.ReplaceAll(texto, ".", "")
+		texto = strings.ReplaceAll(texto, ",", ".")
+	}
+	return strconv.ParseFloat(texto, 64)
+}
+
+// milesConPunto reconoce enteros con separador de miles sin decimales (2.500, 1.250.000)
+var milesConPunto = regexp.MustCompile(`^-?\d{1,3}(\.\d{3})+$`)
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"image"
+	"image/color"
+)
+
+// Miniatura reduce la imagen para que su lado mayor no supere maxLado, promediando los píxeles
+// de cada celda para que no aparezcan serruchos. Si ya es más chica la devuelve sin cambios.
+func Miniatura(img image.Image, maxLado int) image.Image {
+	b := img.Bounds()
+	ancho, alto := b.Dx(), b.Dy()
+	if ancho <= maxLado && alto <= maxLado {
+		return img
+	}
+
+	nuevoAncho, nuevoAlto := maxLado, alto*maxLado/ancho
+	if alto > ancho {
+		nuevoAncho, nuevoAlto = ancho*maxLado/alto, maxLado
+	}
+	nuevoAncho, nuevoAlto = max(nuevoAncho, 1), max(nuevoAlto, 1)
+
+	dst := image.NewRGBA(image.Rect(0, 0, nuevoAncho, nuevoAlto))
+	for y := 0; y < nuevoAlto; y++ {
+		y0 := b.Min.Y + y*alto/nuevoAlto
+		y1 := max(b.Min.Y+(y+1)*alto/nuevoAlto, y0+1)
+		for x := 0; x < nuevoAncho; x++ {
+			x0 := b.Min.X + x*ancho/nuevoAncho
+			x1 := max(b.Min.X+(x+1)*ancho/nuevoAncho, x0+1)
+
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r, g, bl, a = r+uint64(cr), g+uint64(cg), bl+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{uint16(r / n), uint16(g / n), uint16(bl / n), uint16(a / n)})
+		}
+	}
+	return dst
+}
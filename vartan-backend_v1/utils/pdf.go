@@ -0,0 +1,351 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Medidas de una hoja A4 en puntos (1/72 de pulgada)
+const (
+	A4Ancho = 595.28
+	A4Alto  = 841.89
+)
+
+// PDF - Generador mínimo de documentos PDF con las fuentes estándar Helvetica.
+// Alcanza para comprobantes, presupuestos y etiquetas sin depender de servicios externos.
+// Las coordenadas se expresan en puntos con origen en la esquina superior izquierda.
+type PDF struct {
+	paginas []*bytes.Buffer
+	actual  *bytes.Buffer
+	negrita bool
+	tamanio float64
+	Ancho   float64
+	Alto    float64
+
+	imagenes []imagenJPEG
+}
+
+// imagenJPEG - Imagen JPEG que se incrusta tal cual con el filtro DCTDecode
+type imagenJPEG struct {
+	datos       []byte
+	ancho, alto int
+	componentes int
+}
+
+// NewPDF crea un documento A4 vertical vacío
+func NewPDF() *PDF {
+	return &PDF{Ancho: A4Ancho, Alto: A4Alto, tamanio: 10}
+}
+
+// AddPage agrega una página nueva y la deja como página actual
+func (p *PDF) AddPage() {
+	p.actual = &bytes.Buffer{}
+	p.paginas = append(p.paginas, p.actual)
+}
+
+// SetFont cambia la fuente actual (Helvetica normal o negrita) y su tamaño
+func (p *PDF) SetFont(negrita bool, tamanio float64) {
+	p.negrita = negrita
+	p.tamanio = tamanio
+}
+
+// Text escribe un texto con su línea base en (x, y)
+func (p *PDF) Text(x, y float64, s string) {
+	fuente := "F1"
+	if p.negrita {
+		fuente = "F2"
+	}
+	fmt.Fprintf(p.actual, "BT /%s %.2f Tf %.2f %.2f Td (%s) Tj ET\n", fuente, p.tamanio, x, p.Alto-y, escaparTextoPDF(s))
+}
+
+// TextRight escribe un texto alineado a la derecha terminando en x
+func (p *PDF) TextRight(x, y float64, s string) {
+	p.Text(x-p.TextWidth(s), y, s)
+}
+
+// TextCenter escribe un texto centrado en x
+func (p *PDF) TextCenter(x, y float64, s string) {
+	p.Text(x-p.TextWidth(s)/2, y, s)
+}
+
+// TextWidth devuelve el ancho en puntos del texto con la fuente actual
+func (p *PDF) TextWidth(s string) float64 {
+	tabla := anchosHelvetica
+	if p.negrita {
+		tabla = anchosHelveticaNegrita
+	}
+	var total int
+	for _, r := range s {
+		total += anchoCaracter(tabla, r)
+	}
+	return float64(total) * p.tamanio / 1000
+}
+
+// TruncateText recorta el texto con "..." para que no supere el ancho indicado
+func (p *PDF) TruncateText(s string, ancho float64) string {
+	if p.TextWidth(s) <= ancho {
+		return s
+	}
+	runas := []rune(s)
+	for len(runas) > 0 && p.TextWidth(string(runas)+"...") > ancho {
+		runas = runas[:len(runas)-1]
+	}
+	return string(runas) + "..."
+}
+
+// Line dibuja una línea recta de (x1, y1) a (x2, y2)
+func (p *PDF) Line(x1, y1, x2, y2, grosor float64) {
+	fmt.Fprintf(p.actual, "%.2f w %.2f %.2f m %.2f %.2f l S\n", grosor, x1, p.Alto-y1, x2, p.Alto-y2)
+}
+
+// Rect dibuja un rectángulo con su esquina superior izquierda en (x, y).
+// gris va de 0 (negro) a 1 (blanco); si relleno es false solo se dibuja el borde.
+func (p *PDF) Rect(x, y, ancho, alto float64, relleno bool, gris float64) {
+	op := "S"
+	color := fmt.Sprintf("%.3f G", gris)
+	if relleno {
+		op = "f"
+		color = fmt.Sprintf("%.3f g", gris)
+	}
+	fmt.Fprintf(p.actual, "q %s 0.5 w %.2f %.2f %.2f %.2f re %s Q\n", color, x, p.Alto-y-alto, ancho, alto, op)
+}
+
+// SetTextGray cambia el color del texto (0 negro, 1 blanco)
+func (p *PDF) SetTextGray(gris float64) {
+	fmt.Fprintf(p.actual, "%.3f g\n", gris)
+}
+
+// Image dibuja una imagen JPEG con su esquina superior izquierda en (x, y), escalada al ancho
+// y alto indicados. Si la misma imagen se usa varias veces se incrusta una sola vez.
+func (p *PDF) Image(jpeg []byte, x, y, ancho, alto float64) error {
+	indice := -1
+	for i, img := range p.imagenes {
+		if len(img.datos) == len(jpeg) && len(jpeg) > 0 && &img.datos[0] == &jpeg[0] {
+			indice = i
+			break
+		}
+	}
+	if indice < 0 {
+		img, err := leerJPEG(jpeg)
+		if err != nil {
+			return err
+		}
+		p.imagenes = append(p.imagenes, img)
+		indice = len(p.imagenes) - 1
+	}
+
+	fmt.Fprintf(p.actual, "q %.2f 0 0 %.2f %.2f %.2f cm /Im%d Do Q\n", ancho, alto, x, p.Alto-y-alto, indice+1)
+	return nil
+}
+
+// Bytes arma el archivo PDF completo
+func (p *PDF) Bytes() []byte {
+	if len(p.paginas) == 0 {
+		p.AddPage()
+	}
+
+	var out bytes.Buffer
+	var offsets []int
+	nuevoObjeto := func() int {
+		offsets = append(offsets, out.Len())
+		n := len(offsets)
+		fmt.Fprintf(&out, "%d 0 obj\n", n)
+		return n
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 1: catálogo, 2: árbol de páginas, 3 y 4: fuentes
+	nuevoObjeto()
+	out.WriteString("<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+
+	primeraPagina := 5
+	nuevoObjeto()
+	var kids strings.Builder
+	for i := range p.paginas {
+		fmt.Fprintf(&kids, "%d 0 R ", primeraPagina+i*2)
+	}
+	fmt.Fprintf(&out, "<< /Type /Pages /Kids [%s] /Count %d >>\nendobj\n", kids.String(), len(p.paginas))
+
+	nuevoObjeto()
+	out.WriteString("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>\nendobj\n")
+	nuevoObjeto()
+	out.WriteString("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>\nendobj\n")
+
+	// Las imágenes van después de las páginas
+	primeraImagen := primeraPagina + len(p.paginas)*2
+	recursos := "/Font << /F1 3 0 R /F2 4 0 R >>"
+	if len(p.imagenes) > 0 {
+		var xobjetos strings.Builder
+		for i := range p.imagenes {
+			fmt.Fprintf(&xobjetos, "/Im%d %d 0 R ", i+1, primeraImagen+i)
+		}
+		recursos += " /XObject << " + xobjetos.String() + ">>"
+	}
+
+	for i, pagina := range p.paginas {
+		nuevoObjeto()
+		fmt.Fprintf(&out, "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << %s >> /Contents %d 0 R >>\nendobj\n",
+			p.Ancho, p.Alto, recursos, primeraPagina+i*2+1)
+		nuevoObjeto()
+		fmt.Fprintf(&out, "<< /Length %d >>\nstream\n", pagina.Len())
+		out.Write(pagina.Bytes())
+		out.WriteString("endstream\nendobj\n")
+	}
+
+	for _, img := range p.imagenes {
+		espacio := "/DeviceRGB"
+		switch img.componentes {
+		case 1:
+			espacio = "/DeviceGray"
+		case 4:
+			espacio = "/DeviceCMYK"
+		}
+		nuevoObjeto()
+		fmt.Fprintf(&out, "<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace %s /BitsPerComponent 8 /Filter /DCTDecode /Length %d >>\nstream\n",
+			img.ancho, img.alto, espacio, len(img.datos))
+		out.Write(img.datos)
+		out.WriteString("\nendstream\nendobj\n")
+	}
+
+	inicioXref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, inicioXref)
+
+	return out.Bytes()
+}
+
+// leerJPEG obtiene las dimensiones y la cantidad de componentes de color del encabezado SOF del JPEG
+func leerJPEG(datos []byte) (imagenJPEG, error) {
+	if len(datos) < 4 || datos[0] != 0xFF || datos[1] != 0xD8 {
+		return imagenJPEG{}, errors.New("la imagen no es un JPEG")
+	}
+	for i := 2; i+4 <= len(datos); {
+		if datos[i] != 0xFF {
+			return imagenJPEG{}, errors.New("JPEG dañado")
+		}
+		marcador := datos[i+1]
+		if marcador == 0xFF {
+			// Relleno entre marcadores
+			i++
+			continue
+		}
+		largo := int(datos[i+2])<<8 | int(datos[i+3])
+		// SOF0 a SOF15, salvo DHT (C4), JPG (C8) y DAC (CC)
+		if marcador >= 0xC0 && marcador <= 0xCF && marcador != 0xC4 && marcador != 0xC8 && marcador != 0xCC {
+			if i+10 > len(datos) {
+				break
+			}
+			img := imagenJPEG{
+				datos:       datos,
+				alto:        int(datos[i+5])<<8 | int(datos[i+6]),
+				ancho:       int(datos[i+7])<<8 | int(datos[i+8]),
+				componentes: int(datos[i+9]),
+			}
+			if datos[i+4] != 8 || img.ancho == 0 || img.alto == 0 {
+				return imagenJPEG{}, errors.New("JPEG no soportado")
+			}
+			return img, nil
+		}
+		if marcador == 0xDA {
+			break
+		}
+		i += 2 + largo
+	}
+	return imagenJPEG{}, errors.New("JPEG sin encabezado de imagen")
+}
+
+// escaparTextoPDF convierte el texto a WinAnsi (Latin-1) y escapa los caracteres especiales
+func escaparTextoPDF(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n' || r == '\r' || r == '\t':
+			b.WriteByte(' ')
+		case r < 128:
+			b.WriteRune(r)
+		case r < 256:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+func anchoCaracter(tabla [95]int, r rune) int {
+	if r >= 32 && r <= 126 {
+		return tabla[r-32]
+	}
+	// Letras acentuadas de Latin-1: mismo ancho que la letra base
+	if base, ok := letrasBaseLatin1[r]; ok {
+		return tabla[base-32]
+	}
+	return 556
+}
+
+var letrasBaseLatin1 = map[rune]rune{
+	'á': 'a', 'é': 'e', 'í': 'i', 'ó': 'o', 'ú': 'u', 'ü': 'u', 'ñ': 'n',
+	'Á': 'A', 'É': 'E', 'Í': 'I', 'Ó': 'O', 'Ú': 'U', 'Ü': 'U', 'Ñ': 'N',
+	'¿': '?', '¡': '!', 'º': 'o', 'ª': 'a', '°': 'o',
+}
+
+// Anchos de los caracteres 32-126 (unidades de 1/1000 del tamaño de fuente)
+var anchosHelvetica = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var anchosHelveticaNegrita = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
+
+// EAN13 dibuja un código de barras EAN-13 con su esquina superior izquierda en (x, y)
+// y los dígitos debajo. El ancho incluye los márgenes en blanco que exige el lector.
+func (p *PDF) EAN13(x, y, ancho, alto float64, codigo string) error {
+	barras, err := BarrasEAN13(codigo)
+	if err != nil {
+		return err
+	}
+
+	// 95 módulos de barras más 11 de margen izquierdo y 7 de derecho
+	modulo := ancho / 113
+	inicio := x + 11*modulo
+	for i := 0; i < len(barras); {
+		if barras[i] == '0' {
+			i++
+			continue
+		}
+		j := i
+		for j < len(barras) && barras[j] == '1' {
+			j++
+		}
+		fmt.Fprintf(p.actual, "0 g %.3f %.3f %.3f %.3f re f\n", inicio+float64(i)*modulo, p.Alto-y-alto, float64(j-i)*modulo, alto)
+		i = j
+	}
+
+	tamanio := p.tamanio
+	p.SetFont(p.negrita, modulo*9)
+	p.Text(x, y+alto+modulo*9, codigo[:1])
+	p.TextCenter(inicio+24*modulo, y+alto+modulo*9, codigo[1:7])
+	p.TextCenter(inicio+71*modulo, y+alto+modulo*9, codigo[7:])
+	p.SetFont(p.negrita, tamanio)
+	return nil
+}
@@ -0,0 +1,195 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// LeerCSV lee un CSV separado por coma o punto y coma (el que usa Excel en español)
+// y devuelve las filas como texto
+func LeerCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // BOM que agrega Excel
+
+	primeraLinea, _, _ := bytes.Cut(data, []byte("\n"))
+	lector := csv.NewReader(bytes.NewReader(data))
+	if bytes.Count(primeraLinea, []byte(";")) > bytes.Count(primeraLinea, []byte(",")) {
+		lector.Comma = ';'
+	}
+	lector.FieldsPerRecord = -1
+	lector.TrimLeadingSpace = true
+	return lector.ReadAll()
+}
+
+// Límites de un XLSX: los de Excel para filas y columnas (XFD) y un tope para cada XML
+// descomprimido, para no agotar la memoria con un archivo armado a propósito
+const (
+	maxFilasXLSX    = 1048576
+	maxColumnasXLSX = 16384
+	maxXMLXLSX      = 64 << 20
+)
+
+// Estructuras mínimas de SpreadsheetML para leer la primera hoja de un XLSX
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelaciones struct {
+	Relaciones []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxTextos struct {
+	Items []xlsxTextoRico `xml:"si"`
+}
+
+// xlsxTextoRico - Texto simple (<t>) o con formato (varios <r><t>)
+type xlsxTextoRico struct {
+	T  string `xml:"t"`
+	Rs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxTextoRico) texto() string {
+	if len(t.Rs) == 0 {
+		return t.T
+	}
+	var b strings.Builder
+	for _, r := range t.Rs {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+type xlsxHoja struct {
+	Filas []struct {
+		Numero int `xml:"r,attr"`
+		Celdas []struct {
+			Ref    string        `xml:"r,attr"`
+			Tipo   string        `xml:"t,attr"`
+			Valor  string        `xml:"v"`
+			Inline xlsxTextoRico `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// LeerXLSX lee la primera hoja de un archivo XLSX y devuelve las filas como texto.
+// Las celdas vacías intermedias se completan para que cada valor quede en su columna.
+func LeerXLSX(data []byte) ([][]string, error) {
+	archivo, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("el archivo no es un XLSX válido")
+	}
+
+	var libro xlsxWorkbook
+	if err := leerXMLDeZip(archivo, "xl/workbook.xml", &libro); err != nil || len(libro.Sheets) == 0 {
+		return nil, fmt.Errorf("el archivo no es un XLSX válido")
+	}
+
+	// Ubicar el archivo de la primera hoja a través de las relaciones del libro
+	rutaHoja := "xl/worksheets/sheet1.xml"
+	var rels xlsxRelaciones
+	if leerXMLDeZip(archivo, "xl/_rels/workbook.xml.rels", &rels) == nil {
+		for _, rel := range rels.Relaciones {
+			if rel.ID == libro.Sheets[0].RID {
+				if strings.HasPrefix(rel.Target, "/") {
+					rutaHoja = strings.TrimPrefix(rel.Target, "/")
+				} else {
+					rutaHoja = path.Join("xl", rel.Target)
+				}
+			}
+		}
+	}
+
+	// Los textos suelen estar en una tabla compartida (no existe si la hoja no tiene textos)
+	var textos xlsxTextos
+	leerXMLDeZip(archivo, "xl/sharedStrings.xml", &textos)
+
+	var hoja xlsxHoja
+	if err := leerXMLDeZip(archivo, rutaHoja, &hoja); err != nil {
+		return nil, fmt.Errorf("no se pudo leer la hoja del XLSX")
+	}
+
+	filas := make([][]string, 0, len(hoja.Filas))
+	for _, f := range hoja.Filas {
+		if f.Numero > maxFilasXLSX || len(filas) >= maxFilasXLSX {
+			return nil, fmt.Errorf("la hoja supera las %d filas", maxFilasXLSX)
+		}
+		// Las filas vacías no figuran en el XML; se agregan para conservar la numeración
+		for f.Numero > 0 && len(filas) < f.Numero-1 {
+			filas = append(filas, nil)
+		}
+
+		var fila []string
+		for i, celda := range f.Celdas {
+			columna := i
+			if celda.Ref != "" {
+				columna = columnaXLSX(celda.Ref)
+			}
+			if columna >= maxColumnasXLSX {
+				return nil, fmt.Errorf("la celda %s está fuera de las columnas de Excel", celda.Ref)
+			}
+			for len(fila) < columna {
+				fila = append(fila, "")
+			}
+
+			valor := celda.Valor
+			switch celda.Tipo {
+			case "s":
+				if idx, err := strconv.Atoi(celda.Valor); err == nil && idx < len(textos.Items) {
+					valor = textos.Items[idx].texto()
+				}
+			case "inlineStr":
+				valor = celda.Inline.texto()
+			}
+			fila = append(fila, valor)
+		}
+		filas = append(filas, fila)
+	}
+	return filas, nil
+}
+
+// columnaXLSX convierte la referencia de una celda ("C12") en el índice de columna desde 0.
+// Las columnas posteriores a XFD devuelven maxColumnasXLSX.
+func columnaXLSX(ref string) int {
+	n := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		n = n*26 + int(r-'A'+1)
+		if n > maxColumnasXLSX {
+			return maxColumnasXLSX
+		}
+	}
+	return n - 1
+}
+
+// leerXMLDeZip decodifica un XML del archivo, rechazando los que superan maxXMLXLSX descomprimidos
+func leerXMLDeZip(archivo *zip.Reader, nombre string, destino interface{}) error {
+	f, err := archivo.Open(nombre)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	contenido, err := io.ReadAll(io.LimitReader(f, maxXMLXLSX+1))
+	if err != nil {
+		return err
+	}
+	if len(contenido) > maxXMLXLSX {
+		return fmt.Errorf("%s es demasiado grande", nombre)
+	}
+	return xml.Unmarshal(contenido, destino)
+}
@@ -46,6 +46,7 @@ export default function ComisionesPage() {
     const [configurarModalOpen, setConfigurarModalOpen] = useState(false);
     const [vendedorSeleccionado, setVendedorSeleccionado] = useState<IUser | null>(null);
     const [miConfiguracion, setMiConfiguracion] = useState<IUser | null>(null);
+    const [miGastoPublicitario, setMiGastoPublicitario] = useState(0);
     const [loading, setLoading] = useState(true);
     const [error, setError] = useState<string | null>(null);
 
@@ -72,7 +73,7 @@ export default function ComisionesPage() {
                 const comAnterior = comisionesData.find(c => c.usuario_id === v.id && c.mes === mesAnterior && c.anio === anioAnterior);
 
                 const ventas = comActual?.total_ventas || 0;
-                const gastoPublicitario = v.gasto_publicitario || 0;
+                const gastoPublicitario = comActual?.gasto_publicitario || 0;
                 const base = ventas - gastoPublicitario;
                 const porcentaje = v.porcentaje_comision || 0;
                 const comisionEst = base > 0 ? (base * porcentaje) / 100 : 0;
@@ -121,8 +122,16 @@ export default function ComisionesPage() {
         setError(null);
 
         try {
-            const userData = await usuarioService.getMe();
+            const [userData, misComisiones] = await Promise.all([
+                usuarioService.getMe(),
+                comisionService.getMisComisiones(),
+            ]);
             setMiConfiguracion(userData);
+
+            // Lo invertido en las campañas a cargo del vendedor en el mes actual
+            const hoy = new Date();
+            const comActual = misComisiones.find(c => c.mes === hoy.getMonth() + 1 && c.anio === hoy.getFullYear());
+            setMiGastoPublicitario(comActual?.gasto_publicitario || 0);
         } catch (err) {
             console.error('Error:', err);
             setError('Error al cargar datos');
@@ -149,7 +158,6 @@ export default function ComisionesPage() {
             rol: 'vendedor',
             activo: true,
             porcentaje_comision: v.porcentaje_comision,
-            gasto_publicitario: v.gasto_publicitario,
             sueldo: v.sueldo,
             observaciones_config: v.observaciones_config,
             fecha_creacion: new Date().toISOString(),
@@ -224,7 +232,7 @@ export default function ComisionesPage() {
                             <Grid size={{ xs: 12, md: 4 }}>
                                 <Box sx={{ bgcolor: 'rgba(239, 68, 68, 0.05)', border: '1px solid rgba(239, 68, 68, 0.2)', borderRadius: '8px', p: 3, textAlign: 'center' }}>
                                     <Typography sx={{ fontSize: '12px', color: '#6B7280', mb: 1, textTransform: 'uppercase', fontWeight: 600 }}>Gasto Pub.</Typography>
-                                    <Typography sx={{ fontSize: '32px', fontWeight: 700, color: '#DC2626' }}>{formatCurrency(miConfiguracion.gasto_publicitario)}</Typography>
+                                    <Typography sx={{ fontSize: '32px', fontWeight: 700, color: '#DC2626' }}>{formatCurrency(miGastoPublicitario)}</Typography>
                                 </Box>
                             </Grid>
                             <Grid size={{ xs: 12, md: 4 }}>
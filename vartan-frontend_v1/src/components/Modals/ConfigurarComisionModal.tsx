@@ -20,7 +20,6 @@ export default function ConfigurarComisionModal({
   vendedor,
 }: ConfigurarComisionModalProps) {
   const [porcentajeComision, setPorcentajeComision] = useState('');
-  const [gastoPublicitario, setGastoPublicitario] = useState('');
   const [sueldo, setSueldo] = useState('');
   const [observaciones, setObservaciones] = useState('');
   const [loading, setLoading] = useState(false);
@@ -29,7 +28,6 @@ export default function ConfigurarComisionModal({
   useEffect(() => {
     if (vendedor) {
       setPorcentajeComision(vendedor.porcentaje_comision?.toString() || '0');
-      setGastoPublicitario(vendedor.gasto_publicitario?.toString() || '0');
       setSueldo(vendedor.sueldo?.toString() || '0');
       setObservaciones(vendedor.observaciones_config || '');
     }
@@ -44,11 +42,6 @@ export default function ConfigurarComisionModal({
       return;
     }
 
-    if (!gastoPublicitario || parseFloat(gastoPublicitario) < 0) {
-      setError('El gasto publicitario debe ser mayor o igual a 0');
-      return;
-    }
-
     if (!sueldo || parseFloat(sueldo) < 0) {
       setError('El sueldo debe ser mayor o igual a 0');
       return;
@@ -60,7 +53,6 @@ export default function ConfigurarComisionModal({
     try {
       await usuarioService.updateComisionConfig(vendedor.id, {
         porcentaje_comision: parseFloat(porcentajeComision),
-        gasto_publicitario: parseFloat(gastoPublicitario),
         sueldo: parseFloat(sueldo),
         observaciones: observaciones.trim() || undefined,
       });
@@ -77,7 +69,6 @@ export default function ConfigurarComisionModal({
 
   const handleClose = () => {
     setPorcentajeComision('');
-    setGastoPublicitario('');
     setSueldo('');
     setObservaciones('');
     setError(null);
@@ -95,7 +86,7 @@ export default function ConfigurarComisionModal({
       submitText="Guardar"
     >
       <Grid container spacing={2}>
-        <Grid size={{ xs: 12, md: 4 }}>
+        <Grid size={{ xs: 12, md: 6 }}>
           <Box>
             <Typography sx={{ fontSize: '13px', fontWeight: 500, color: '#6B7280', mb: 0.75 }}>
               Porcentaje de comisión (%) *
@@ -123,34 +114,7 @@ export default function ConfigurarComisionModal({
           </Box>
         </Grid>
 
-        <Grid size={{ xs: 12, md: 4 }}>
-          <Box>
-            <Typography sx={{ fontSize: '13px', fontWeight: 500, color: '#6B7280', mb: 0.75 }}>
-              Gasto publicitario ($) *
-            </Typography>
-            <input
-              type="number"
-              value={gastoPublicitario}
-              onChange={(e) => setGastoPublicitario(e.target.value)}
-              placeholder="Ej: 5000"
-              min="0"
-              step="0.01"
-              style={{
-                width: '100%',
-                padding: '10px 12px',
-                fontSize: '14px',
-                border: '1px solid #E5E7EB',
-                borderRadius: '6px',
-                fontFamily: 'inherit',
-                outline: 'none',
-              }}
-              onFocus={(e) => e.target.style.borderColor = '#3B82F6'}
-              onBlur={(e) => e.target.style.borderColor = '#E5E7EB'}
-            />
-          </Box>
-        </Grid>
-
-        <Grid size={{ xs: 12, md: 4 }}>
+        <Grid size={{ xs: 12, md: 6 }}>
           <Box>
             <Typography sx={{ fontSize: '13px', fontWeight: 500, color: '#6B7280', mb: 0.75 }}>
               Sueldo base ($) *
@@ -188,7 +152,7 @@ export default function ConfigurarComisionModal({
               Cálculo de sueldo total
             </Typography>
             <Typography sx={{ fontSize: '12px', color: '#075985', lineHeight: 1.5 }}>
-              Sueldo Total = Sueldo base + ((Ventas - Gasto publicitario) × {porcentajeComision || '0'}%)
+              Sueldo Total = Sueldo base + ((Ventas - Gasto de sus campañas) × {porcentajeComision || '0'}%) + Bono por objetivo
             </Typography>
           </Box>
         </Grid>
@@ -8,4 +8,5 @@ export interface ICliente {
     provincia?: string;
     pais?: string;
     fecha_creacion: string;
+    deleted_at?: string | null;
 }
\ No newline at end of file
@@ -10,6 +10,7 @@ export interface IComision {
     base_comision: number;          // Total ventas - gasto publicitario
     porcentaje_comision: number;    // Porcentaje aplicado
     total_comision: number;         // Monto final de la comisión
+    bono: number;                   // Por cumplir el objetivo del mes (incluido en el total)
     sueldo: number;                 // Sueldo base registrado
     observaciones?: string;
     usuario?: IUser;
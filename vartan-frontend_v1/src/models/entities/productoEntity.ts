@@ -6,6 +6,7 @@ export interface IProducto {
     id: number;
     nombre: string;
     costo_unitario: number;
+    precio_venta?: number;
     activo: boolean;
     fecha_creacion: string;
     talles_disponibles?: TalleEnum[];
@@ -15,6 +16,19 @@ export interface IProducto {
     equipo_id?: number;
     tipo_producto?: ITipoProducto;
     equipo?: IEquipo;
+    imagenes?: IProductoImagen[];
+}
+
+export interface IProductoImagen {
+    id: number;
+    producto_id: number;
+    color?: string | null;
+    url: string;
+    miniatura_url: string;
+    ancho: number;
+    alto: number;
+    orden: number;
+    principal: boolean;
 }
 
 export interface IProductoStock {
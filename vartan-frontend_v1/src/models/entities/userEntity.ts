@@ -5,7 +5,6 @@ export interface IUser {
     rol: 'dueño' | 'vendedor';
     activo: boolean;
     porcentaje_comision: number;     // Porcentaje de comisión (ej: 10 = 10%)
-    gasto_publicitario: number;       // Monto fijo de gasto publicitario
     observaciones_config?: string;    // Observaciones del dueño para el vendedor (coincide con backend)
     sueldo: number;                   // Sueldo base del empleado
     fecha_creacion: string;
@@ -16,6 +16,16 @@ export interface IVenta {
     sena: number;
     saldo: number;
     descuento: number;
+    descuento_pactado: number;
+    descuento_promociones: number;
+    descuento_financiera: number;
+    descuento_puntos: number;
+    cupon?: string | null;
+    pago_saldo_a_favor: number;
+    puntos_canjeados: number;
+    puntos_ganados: number;
+    canal_venta?: string | null;
+    campana_id?: number | null;
     total_final: number;
     usa_financiera: boolean;
     comprobante_url?: string | null;
@@ -35,5 +45,9 @@ export interface IVentaDetalle {
     cantidad: number;
     precio_unitario: number;
     subtotal: number;
+    promocion_id?: number | null;
+    descuento: number;
+    combo_id?: number | null;
+    cantidad_devuelta: number;
     producto?: IProducto;
 }
\ No newline at end of file
@@ -4,7 +4,6 @@ export interface IComisionObservacionesRequest {
 
 export interface IUsuarioComisionConfigRequest {
     porcentaje_comision: number;
-    gasto_publicitario: number;
     sueldo: number;
     observaciones?: string;
 }
@@ -4,6 +4,7 @@ import { ColorEnum } from '@models/enums/ColorEnum';
 export interface IProductoCreateRequest {
     nombre: string;
     costo_unitario: number;
+    precio_venta?: number;
     talles?: TalleEnum[];
     colores?: ColorEnum[];
     tipo_producto_id?: number;
@@ -13,6 +14,7 @@ export interface IProductoCreateRequest {
 export interface IProductoUpdateRequest {
     nombre: string;
     costo_unitario: number;
+    precio_venta?: number;
     talles?: TalleEnum[];
     colores?: ColorEnum[];
     activo?: boolean;
@@ -7,12 +7,31 @@ export interface IVentaCreateRequest {
     usa_financiera?: boolean; // Opcional, el backend lo calcula automáticamente
     comprobante?: File | null;
     observaciones?: string;
+    cupon?: string;
     detalles: IVentaDetalleCreateRequest[];
+    combos?: IVentaComboCreateRequest[];
+    saldo_a_favor_codigo?: string; // Gift card o saldo a favor
+    saldo_a_favor_monto?: number; // Sin monto se usa lo que alcance a cubrir
+    puntos_canje?: number; // Puntos del cliente a usar como descuento
+    canal_venta?: string; // instagram, whatsapp, local, mercadolibre, referido
+    campana_id?: number; // Campaña publicitaria de origen
 }
 
 export interface IVentaDetalleCreateRequest {
     producto_id: number;
     talle: string;
+    color?: string;
+    variante_id?: number;
     cantidad: number;
     precio_unitario: number;
-}
\ No newline at end of file
+}
+export interface IVentaComboCreateRequest {
+    combo_id: number;
+    cantidad: number;
+    selecciones?: {
+        componente_id: number;
+        variante_id?: number;
+        talle?: string;
+        color?: string;
+    }[];
+}
@@ -6,4 +6,10 @@ export interface IClientesResponse {
 
 export interface IClienteResponse {
     data: ICliente;
-}
\ No newline at end of file
+}
+export interface IListarClientesResponse {
+    clientes: ICliente[];
+    total: number;
+    page: number;
+    limit: number;
+}
@@ -10,4 +10,11 @@ export interface IProductoResponse {
 
 export interface IStockResponse {
     data: IProductoStock[];
-}
\ No newline at end of file
+}
+
+export interface IListarProductosResponse {
+    productos: IProducto[];
+    total: number;
+    page: number;
+    limit: number;
+}
@@ -10,4 +10,28 @@ export interface IVentaResponse {
 
 export interface IFormasPagoResponse {
     data: IFormaPago[];
-}
\ No newline at end of file
+}
+
+export interface IListarVentasResponse {
+    ventas: IVenta[];
+    total: number;
+    page: number;
+    limit: number;
+}
+
+export interface IVentasFilters {
+    fecha_desde?: string;
+    fecha_hasta?: string;
+    cliente_id?: number;
+    usuario_id?: number;
+    forma_pago_id?: number;
+    con_saldo?: boolean;
+    producto_id?: number;
+    equipo_id?: number;
+    total_min?: number;
+    total_max?: number;
+    ordenar_por?: 'fecha_venta' | 'total_final' | 'saldo' | 'id';
+    orden?: 'asc' | 'desc';
+    page?: number;
+    limit?: number;
+}
@@ -1,11 +1,12 @@
 import { api } from '@libraries/api';
 import { ICliente } from '@models/entities/clienteEntity';
 import { IClienteCreateRequest, IClienteUpdateRequest } from '@models/request/IClienteRequest';
+import { IListarClientesResponse } from '@models/response/IClienteResponse';
 
 export const clienteService = {
     getAll: async (): Promise<ICliente[]> => {
-        const response = await api.get<ICliente[]>('/api/clientes');
-        return response.data;
+        const response = await api.get<IListarClientesResponse>('/api/clientes', { params: { limit: 500 } });
+        return response.data.clientes;
     },
 
     getById: async (id: number): Promise<ICliente> => {
@@ -26,4 +27,9 @@ export const clienteService = {
     delete: async (id: number): Promise<void> => {
         await api.delete(`/api/owner/clientes/${id}`);
     },
+
+    restore: async (id: number): Promise<ICliente> => {
+        const response = await api.post<ICliente>(`/api/owner/clientes/${id}/restaurar`);
+        return response.data;
+    },
 };
@@ -6,11 +6,12 @@ import {
     IStockCreateRequest,
     IStockUpdateRequest
 } from '@models/request/IProductoRequest';
+import { IListarProductosResponse } from '@models/response/IProductoResponse';
 
 export const productoService = {
     getAll: async (): Promise<IProducto[]> => {
-        const response = await api.get<IProducto[]>('/api/productos');
-        return response.data;
+        const response = await api.get<IListarProductosResponse>('/api/productos', { params: { limit: 500 } });
+        return response.data.productos;
     },
 
     getById: async (id: number): Promise<IProducto> => {
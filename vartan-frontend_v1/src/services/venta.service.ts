@@ -1,6 +1,7 @@
 import { api } from '@libraries/api';
 import { IVenta } from '@models/entities/ventaEntity';
 import { IVentaCreateRequest } from '@models/request/IVentaRequest';
+import { IListarVentasResponse, IVentasFilters } from '@models/response/IVentaResponse';
 
 interface IVentaCreateResponse {
     message: string;
@@ -8,9 +9,11 @@ interface IVentaCreateResponse {
 }
 
 export const ventaService = {
-    getMisVentas: async (): Promise<IVenta[]> => {
-        const response = await api.get<IVenta[]>('/api/mis-ventas');
-        return response.data;
+    getMisVentas: async (filters?: IVentasFilters): Promise<IVenta[]> => {
+        const response = await api.get<IListarVentasResponse>('/api/mis-ventas', {
+            params: { limit: 500, ...filters },
+        });
+        return response.data.ventas;
     },
 
     create: async (data: IVentaCreateRequest): Promise<IVentaCreateResponse> => {
@@ -69,16 +72,26 @@ export const ventaService = {
     },
 
     // Solo dueño: obtener todas las ventas
-    getAll: async (): Promise<IVenta[]> => {
-        const response = await api.get<IVenta[]>('/api/owner/ventas');
+    getAll: async (filters?: IVentasFilters): Promise<IVenta[]> => {
+        const response = await api.get<IListarVentasResponse>('/api/owner/ventas', {
+            params: { limit: 500, ...filters },
+        });
+        return response.data.ventas;
+    },
+
+    // Solo dueño: listado paginado con total para tablas con paginación del servidor
+    listar: async (filters?: IVentasFilters): Promise<IListarVentasResponse> => {
+        const response = await api.get<IListarVentasResponse>('/api/owner/ventas', { params: filters });
         return response.data;
     },
 
 
     // Solo dueño: obtener ventas por usuario/vendedor
-    getByUsuario: async (usuarioId: number): Promise<IVenta[]> => {
-        const response = await api.get<IVenta[]>(`/api/owner/ventas/usuario/${usuarioId}`);
-        return response.data;
+    getByUsuario: async (usuarioId: number, filters?: IVentasFilters): Promise<IVenta[]> => {
+        const response = await api.get<IListarVentasResponse>(`/api/owner/ventas/usuario/${usuarioId}`, {
+            params: { limit: 500, ...filters },
+        });
+        return response.data.ventas;
     },
 
     // Descargar comprobante